@@ -0,0 +1,183 @@
+package api
+
+import (
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+//getTopProducts provides the best selling products by units (default) or by revenue in a currency
+func (server *Server) getTopProducts(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getTopProducts")
+	filter, err := salesFilter(context)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	by := data.SalesRanking(context.DefaultQuery(rankBy, string(data.RankByUnits)))
+	if !by.IsValid() {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("by %q is not valid, the products are ranked by %s or %s", by, data.RankByUnits, data.RankByRevenue),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	// revenues in different currencies cannot be ranked together
+	if by == data.RankByRevenue && !validCurrency(filter.Currency) {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "a currency code like EUR is required to rank by revenue",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	limit, err := strconv.Atoi(context.DefaultQuery(limitParam, strconv.Itoa(defaultTopLimit)))
+	if err != nil || limit <= 0 || limit > maxTopLimit {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("limit must be a number between 1 and %d", maxTopLimit),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, products := server.Inventory.GetTopProducts(context.Request.Context(), filter, by, limit)
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if len(products) == 0 {
+		context.JSON(http.StatusOK, ResponseProduct{
+			Message: "No sale is found",
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		TopProducts: products,
+	})
+	return
+}
+
+//getSalesSeries provides the sales per product over time by day, week or month
+func (server *Server) getSalesSeries(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getSalesSeries")
+	filter, err := salesFilter(context)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	interval := data.SeriesInterval(context.DefaultQuery(intervalParam, string(data.IntervalDay)))
+	if !interval.IsValid() {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("interval %q is not valid, the intervals are %s, %s and %s", interval, data.IntervalDay, data.IntervalWeek, data.IntervalMonth),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, series := server.Inventory.GetSalesSeries(context.Request.Context(), filter, interval)
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if len(series) == 0 {
+		context.JSON(http.StatusOK, ResponseProduct{
+			Message: "No sale is found",
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Series: series,
+	})
+	return
+}
+
+//getSellThrough provides the units sold in the period against the units still available per product
+func (server *Server) getSellThrough(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getSellThrough")
+	filter, err := salesFilter(context)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, revenue := server.Inventory.GetSalesRevenue(context.Request.Context(), filter)
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err, stocks := server.Inventory.GetProductStock(context.Request.Context())
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	context.JSON(http.StatusOK, ResponseProduct{
+		SellThrough: sellThrough(filter.ProductName, revenue.Products, stocks),
+	})
+	return
+}
+
+//sellThrough calculates the sell-through rate of every product sold or available, of only one product when the
+//product name is given
+func sellThrough(productName string, sold []data.ProductRevenue, stocks data.ProductStocks) []data.SellThrough {
+	rates := make(map[string]*data.SellThrough)
+	var productNames []string
+	product := func(name string) *data.SellThrough {
+		rate, ok := rates[name]
+		if !ok {
+			rate = &data.SellThrough{ProductName: name}
+			rates[name] = rate
+			productNames = append(productNames, name)
+		}
+		return rate
+	}
+	for _, revenue := range sold {
+		product(revenue.ProductName).Sold += revenue.Quantity
+	}
+	for _, stock := range stocks {
+		if productName != "" && stock.Name != productName {
+			continue
+		}
+		available, _ := strconv.Atoi(stock.AvailableProductNo)
+		product(stock.Name).Available = available
+	}
+
+	sort.Strings(productNames)
+	result := make([]data.SellThrough, 0, len(productNames))
+	for _, name := range productNames {
+		rate := rates[name]
+		if rate.Sold+rate.Available > 0 {
+			rate.Rate = math.Round(float64(rate.Sold)/float64(rate.Sold+rate.Available)*10000) / 10000
+		}
+		result = append(result, *rate)
+	}
+	return result
+}
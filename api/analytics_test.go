@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestServer_getTopProducts(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+	products := []data.TopProduct{
+		{Rank: 1, ProductName: "Dining Chair", Quantity: 3, Revenue: map[string]float64{"EUR": 149.7}},
+		{Rank: 2, ProductName: "Dining Table", Quantity: 1},
+	}
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		query      url.Values
+		callDB     bool
+		filter     data.SalesFilter
+		by         data.SalesRanking
+		limit      int
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "by_units",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			query:      url.Values{},
+			callDB:     true,
+			by:         data.RankByUnits,
+			limit:      defaultTopLimit,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "by_revenue",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			query:      url.Values{rankBy: {"revenue"}, currencyParam: {"eur"}, limitParam: {"2"}},
+			callDB:     true,
+			filter:     data.SalesFilter{Currency: "EUR"},
+			by:         data.RankByRevenue,
+			limit:      2,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "by_revenue_without_currency",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			query:      url.Values{rankBy: {"revenue"}},
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "a currency code like EUR is required to rank by revenue",
+		},
+		{
+			name:       "limit_too_high",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			query:      url.Values{limitParam: {"1000"}},
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "limit must be a number between 1 and 100",
+		},
+		{
+			name:       "unknown_ranking",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			query:      url.Values{rankBy: {"profit"}},
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    `by "profit" is not valid, the products are ranked by units or revenue`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+			context.Request = &http.Request{URL: &url.URL{RawQuery: tt.query.Encode()}}
+
+			if tt.callDB {
+				inventory.EXPECT().GetTopProducts(gomock.Any(), tt.filter, tt.by, tt.limit).Return(nil, products)
+			}
+
+			server.getTopProducts(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+			if tt.callDB {
+				assert.Equal(t, response.TopProducts, products)
+			}
+		})
+	}
+}
+
+func TestServer_getSalesSeries(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := &Server{
+		Inventory: inventory,
+		router:    gin.New(),
+		Config:    Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"},
+		Logger:    logrus.NewEntry(logrus.New()),
+	}
+
+	t.Run("weekly", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context, _ := gin.CreateTestContext(recorder)
+		context.Request = &http.Request{URL: &url.URL{RawQuery: intervalParam + "=week&" + productName + "=Dining+Chair"}}
+		monday := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+		series := []data.ProductSeries{{ProductName: "Dining Chair", Points: []data.SeriesPoint{
+			{Period: monday, Quantity: 2, Revenue: map[string]float64{"EUR": 99.8}},
+			{Period: monday.AddDate(0, 0, 7)},
+		}}}
+		inventory.EXPECT().GetSalesSeries(gomock.Any(), data.SalesFilter{ProductName: "Dining Chair"}, data.IntervalWeek).Return(nil, series)
+
+		server.getSalesSeries(context)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response ResponseProduct
+		byteArr, _ := ioutil.ReadAll(recorder.Body)
+		_ = json.Unmarshal(byteArr, &response)
+		assert.Equal(t, response.Series, series)
+	})
+
+	t.Run("unknown_interval", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context, _ := gin.CreateTestContext(recorder)
+		context.Request = &http.Request{URL: &url.URL{RawQuery: intervalParam + "=year"}}
+
+		server.getSalesSeries(context)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		var response ResponseProduct
+		byteArr, _ := ioutil.ReadAll(recorder.Body)
+		_ = json.Unmarshal(byteArr, &response)
+		assert.Equal(t, response.Message, `interval "year" is not valid, the intervals are day, week and month`)
+	})
+}
+
+func TestServer_getSellThrough(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+	server := &Server{
+		Inventory: inventory,
+		router:    gin.New(),
+		Config:    Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"},
+		Logger:    logrus.NewEntry(logrus.New()),
+	}
+	context.Request = &http.Request{URL: &url.URL{}}
+	inventory.EXPECT().GetSalesRevenue(gomock.Any(), data.SalesFilter{}).Return(nil, data.SalesRevenue{Products: []data.ProductRevenue{
+		{ProductName: "Dining Chair", Currency: "EUR", Quantity: 2, Revenue: 99.8},
+		{ProductName: "Dining Chair", Quantity: 1},
+		{ProductName: "Dining Table", Quantity: 1},
+	}})
+	inventory.EXPECT().GetProductStock(gomock.Any()).Return(nil, data.ProductStocks{
+		{Name: "Dining Chair", AvailableProductNo: "1"},
+		{Name: "Bookshelf", AvailableProductNo: "5"},
+	})
+
+	server.getSellThrough(context)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response ResponseProduct
+	byteArr, _ := ioutil.ReadAll(recorder.Body)
+	_ = json.Unmarshal(byteArr, &response)
+	assert.Equal(t, response.SellThrough, []data.SellThrough{
+		{ProductName: "Bookshelf", Sold: 0, Available: 5, Rate: 0},
+		{ProductName: "Dining Chair", Sold: 3, Available: 1, Rate: 0.75},
+		{ProductName: "Dining Table", Sold: 1, Available: 0, Rate: 1},
+	})
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/auknl/warehouse/auth"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+//createAPIKey creates an API key with the requested scopes, the key is in the response only this time
+func (server *Server) createAPIKey(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("createAPIKey")
+	var requested data.APIKey
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err == nil {
+		err = json.Unmarshal(jsonData, &requested)
+	}
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	apiKey, err := auth.NewAPIKey(requested.Name, requested.Scopes)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, created := server.Inventory.CreateAPIKey(context.Request.Context(), apiKey)
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	created.Key = apiKey.Key
+	log.WithField("key_id", created.KeyId).Info("createAPIKey, API key is created")
+	message := fmt.Sprintf("API key %d is created, keep the key, it is not shown again", created.KeyId)
+	context.JSON(http.StatusOK, ResponseProduct{
+		APIKey:  &created,
+		Message: message,
+	})
+	return
+}
+
+//getAPIKeys lists the API keys without the keys themselves
+func (server *Server) getAPIKeys(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getAPIKeys")
+	err, keys := server.Inventory.GetAPIKeys(context.Request.Context())
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		APIKeys: keys,
+	})
+	return
+}
+
+//revokeAPIKey revokes the API key, the requests with it are not authorized any more
+func (server *Server) revokeAPIKey(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("revokeAPIKey")
+	keyId, err := strconv.Atoi(context.Param(keyID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "API key id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err = server.Inventory.RevokeAPIKey(context.Request.Context(), keyId)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	log.WithField("key_id", keyId).Info("revokeAPIKey, API key is revoked")
+	message := fmt.Sprintf("API key %d is revoked", keyId)
+	context.JSON(http.StatusOK, ResponseProduct{
+		Message: message,
+	})
+	return
+}
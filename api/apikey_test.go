@@ -0,0 +1,191 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/auth"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer_authorize(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := NewServer(inventory, nil, nil, Configuration{BackendTimeout: "25s"}, logrus.NewEntry(logrus.New()))
+
+	tests := []struct {
+		name       string
+		key        string
+		callDB     bool
+		dbErr      error
+		apiKey     data.APIKey
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "missing_key",
+			statusCode: http.StatusUnauthorized,
+			message:    "an API key is required in the X-API-Key header",
+		},
+		{
+			name:       "unknown_key",
+			key:        "wh_unknown",
+			callDB:     true,
+			dbErr:      db.ErrAPIKeyNotFound,
+			statusCode: http.StatusUnauthorized,
+			message:    "API key is not valid",
+		},
+		{
+			name:       "lookup_failed",
+			key:        "wh_reader",
+			callDB:     true,
+			dbErr:      errors.New("connection refused"),
+			statusCode: http.StatusInternalServerError,
+			message:    "connection refused",
+		},
+		{
+			name:       "missing_scope",
+			key:        "wh_seller",
+			callDB:     true,
+			apiKey:     data.APIKey{KeyId: 2, Name: "till", Scopes: []data.Scope{data.ScopeProductSell}},
+			statusCode: http.StatusForbidden,
+			message:    "API key has no inventory:read scope",
+		},
+		{
+			name:       "authorized",
+			key:        "wh_reader",
+			callDB:     true,
+			apiKey:     data.APIKey{KeyId: 1, Name: "dashboard", Scopes: []data.Scope{data.ScopeInventoryRead}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "admin_authorized",
+			key:        "wh_admin",
+			callDB:     true,
+			apiKey:     data.APIKey{KeyId: 3, Name: "ops", Scopes: []data.Scope{data.ScopeAdmin}},
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.callDB {
+				inventory.EXPECT().GetActiveAPIKey(gomock.Any(), auth.HashAPIKey(tt.key)).Return(tt.dbErr, tt.apiKey)
+			}
+			if tt.statusCode == http.StatusOK {
+				inventory.EXPECT().GetInventory(gomock.Any()).Return(nil, []data.Stock{})
+			}
+
+			recorder := httptest.NewRecorder()
+			httpRequest, _ := http.NewRequest(http.MethodGet, "/warehouse/v1/inventory", nil)
+			if tt.key != "" {
+				httpRequest.Header.Set(apiKeyHeader, tt.key)
+			}
+			server.router.ServeHTTP(recorder, httpRequest)
+
+			assert.Equal(t, recorder.Code, tt.statusCode)
+			if tt.statusCode != http.StatusOK {
+				var response ResponseError
+				_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+				assert.Equal(t, response.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestServer_createAPIKey(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	type args struct {
+		context *gin.Context
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		body       string
+		callDB     bool
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "api_key_created",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			body:       `{"name":"till","scopes":["product:sell","inventory:read"]}`,
+			callDB:     true,
+			statusCode: http.StatusOK,
+			message:    "API key 1 is created, keep the key, it is not shown again",
+		},
+		{
+			name:       "missing_name",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			body:       `{"scopes":["product:sell"]}`,
+			statusCode: http.StatusBadRequest,
+			message:    "API key name is required",
+		},
+		{
+			name:       "invalid_scope",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			body:       `{"name":"till","scopes":["product:steal"]}`,
+			statusCode: http.StatusBadRequest,
+			message:    `scope "product:steal" is not valid`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Body.Reset()
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBufferString(tt.body))}
+
+			var stored data.APIKey
+			if tt.callDB {
+				inventory.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, apiKey data.APIKey) (error, data.APIKey) {
+					stored = apiKey
+					apiKey.KeyId = 1
+					apiKey.Key = ""
+					return nil, apiKey
+				})
+			}
+
+			server.createAPIKey(tt.args.context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+			if tt.callDB {
+				assert.Equal(t, strings.HasPrefix(response.APIKey.Key, response.APIKey.Prefix), true)
+				assert.Equal(t, stored.KeyHash, auth.HashAPIKey(response.APIKey.Key))
+				assert.Equal(t, strings.Contains(string(byteArr), stored.KeyHash), false)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestServer_getInventoryMasterData(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := &Server{
+		Inventory: inventory,
+		Config:    Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"},
+		Logger:    logrus.NewEntry(logrus.New()),
+	}
+
+	tests := []struct {
+		name     string
+		stock    data.Stock
+		response string
+	}{
+		{
+			name:     "v1_article",
+			stock:    data.Stock{ArtId: "1", Name: "leg", Stock: "12"},
+			response: `{"inventory":[{"art_id":"1","name":"leg","stock":"12"}]}`,
+		},
+		{
+			name: "article_with_master_data",
+			stock: data.Stock{ArtId: "1", Name: "leg", Stock: "12", Unit: "pcs", WeightKg: 0.4,
+				Dimensions: &data.Dimensions{LengthCm: 70, WidthCm: 4, HeightCm: 4}, Barcodes: []string{"4006381333931"},
+				Category: "legs", Attributes: map[string]string{"color": "white"}},
+			response: `{"inventory":[{"art_id":"1","name":"leg","stock":"12","unit":"pcs","weight_kg":0.4,` +
+				`"dimensions":{"length_cm":70,"width_cm":4,"height_cm":4},"barcodes":["4006381333931"],` +
+				`"category":"legs","attributes":{"color":"white"}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			context.Request = &http.Request{URL: &url.URL{}}
+			inventory.EXPECT().GetInventory(gomock.Any()).Return(nil, []data.Stock{tt.stock})
+
+			server.getInventory(context)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tt.response, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"fmt"
+	"github.com/auknl/warehouse/auth"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+)
+
+//client is the caller of a request, authenticated by an API key or a bearer token
+type client struct {
+	//id identifies the client in its rate limit buckets
+	id string
+	//kind names the credential in the error messages
+	kind   string
+	scopes []data.Scope
+	//tenant is the tenant the credential belongs to
+	tenant string
+	//fields identify the client in the log lines of the request
+	fields logrus.Fields
+}
+
+//authorize lets the request through when its API key or bearer token is valid and has the scope. The key or the
+//subject of the token identifies the client in the log lines of the request
+func (server *Server) authorize(scope data.Scope) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if server.Config.AuthDisabled {
+			return
+		}
+		var caller client
+		var ok bool
+		if token := bearerToken(context); token != "" {
+			caller, ok = server.authenticateToken(context, token)
+		} else {
+			caller, ok = server.authenticateAPIKey(context)
+		}
+		if !ok {
+			context.Abort()
+			return
+		}
+
+		context.Set(clientKey, caller.fields)
+		context.Set(clientIDKey, caller.id)
+		context.Set(clientTenantKey, caller.tenant)
+		if !data.HasScope(caller.scopes, scope) {
+			server.requestLog(context).WithField("scope", scope).Info("authorize, client has not the scope")
+			context.Abort()
+			respondError(context, http.StatusForbidden, ResponseError{
+				Message: fmt.Sprintf("%s has no %s scope", caller.kind, scope),
+				RID:     request.GetRID(context.Request.Context()),
+			})
+			return
+		}
+	}
+}
+
+//guard applies the per IP limit of the auth group, authorizes the request for the scope and resolves its tenant,
+//then applies the rate limit of the route group to its client
+func (server *Server) guard(scope data.Scope, group string) gin.HandlerFunc {
+	authLimit := server.rateLimitIP(rateLimitGroupAuth)
+	authorize := server.authorize(scope)
+	tenant := server.tenant()
+	rateLimit := server.rateLimit(group)
+	return func(context *gin.Context) {
+		authLimit(context)
+		if context.IsAborted() {
+			return
+		}
+		authorize(context)
+		if context.IsAborted() {
+			return
+		}
+		tenant(context)
+		if context.IsAborted() {
+			return
+		}
+		rateLimit(context)
+	}
+}
+
+//authenticateAPIKey finds the active API key of the X-API-Key header, it responds itself when there is none
+func (server *Server) authenticateAPIKey(context *gin.Context) (client, bool) {
+	key := context.GetHeader(apiKeyHeader)
+	if key == "" {
+		message := "an API key is required in the " + apiKeyHeader + " header"
+		if server.Tokens != nil {
+			message = "a bearer token or an API key in the " + apiKeyHeader + " header is required"
+			context.Header(authenticateHeader, bearerScheme)
+		}
+		respondError(context, http.StatusUnauthorized, ResponseError{
+			Message: message,
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return client{}, false
+	}
+
+	err, apiKey := server.Inventory.GetActiveAPIKey(context.Request.Context(), auth.HashAPIKey(key))
+	if err == db.ErrAPIKeyNotFound {
+		respondError(context, http.StatusUnauthorized, ResponseError{
+			Message: "API key is not valid",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return client{}, false
+	}
+	if err != nil {
+		server.requestLog(context).WithField("err", err).Error("authorize, API key lookup failed")
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return client{}, false
+	}
+	return client{
+		id:     fmt.Sprintf("api_key:%d", apiKey.KeyId),
+		kind:   "API key",
+		scopes: apiKey.Scopes,
+		tenant: apiKey.TenantId,
+		fields: logrus.Fields{"api_key_id": apiKey.KeyId, "api_key_name": apiKey.Name},
+	}, true
+}
+
+//authenticateToken verifies the bearer token against the key set, it responds itself when the token is not valid
+func (server *Server) authenticateToken(context *gin.Context, token string) (client, bool) {
+	if server.Tokens == nil {
+		respondError(context, http.StatusUnauthorized, ResponseError{
+			Message: "bearer tokens are not accepted, use an API key in the " + apiKeyHeader + " header",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return client{}, false
+	}
+	principal, err := server.Tokens.Verify(token)
+	if err != nil {
+		server.requestLog(context).WithField("err", err).Info("authorize, bearer token is not valid")
+		context.Header(authenticateHeader, bearerScheme+` error="invalid_token"`)
+		respondError(context, http.StatusUnauthorized, ResponseError{
+			Message: "bearer token is not valid",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return client{}, false
+	}
+	return client{
+		id:     "subject:" + principal.Subject,
+		kind:   "token",
+		scopes: principal.Scopes,
+		tenant: principal.Tenant,
+		fields: logrus.Fields{"subject": principal.Subject, "roles": principal.Roles},
+	}, true
+}
+
+//bearerToken gets the token of the Authorization header, empty if the request has no bearer token
+func bearerToken(context *gin.Context) string {
+	header := context.GetHeader(authorizationHeader)
+	if len(header) <= len(bearerScheme)+1 || !strings.EqualFold(header[:len(bearerScheme)+1], bearerScheme+" ") {
+		return ""
+	}
+	return strings.TrimSpace(header[len(bearerScheme)+1:])
+}
+
+//requestLog gives the logger of the request with its rid, its tenant and the client it is authorized for
+func (server *Server) requestLog(context *gin.Context) *logrus.Entry {
+	log := server.Logger.WithField("rid", request.GetRID(context.Request.Context()))
+	if tenant := context.GetString(tenantKey); tenant != "" {
+		log = log.WithField("tenant", tenant)
+	}
+	if client, ok := context.Get(clientKey); ok {
+		log = log.WithFields(client.(logrus.Fields))
+	}
+	return log
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/auth"
+	"github.com/auknl/warehouse/data"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//newTestVerifier creates a verifier of the tokens signed by the key
+func newTestVerifier(t *testing.T, key *rsa.PrivateKey) *auth.Verifier {
+	jwks := fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"test-1","use":"sig","n":"%s","e":"%s"}]}`,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()))
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	err := ioutil.WriteFile(file, []byte(jwks), 0600)
+	assert.Equal(t, err, nil)
+	keys, err := auth.LoadKeySet(file, logrus.NewEntry(logrus.New()))
+	assert.Equal(t, err, nil)
+	return auth.NewVerifier(keys, auth.TokenConfig{Audience: "warehouse"})
+}
+
+func TestServer_authorizeToken(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := NewServer(inventory, nil, newTestVerifier(t, key), Configuration{BackendTimeout: "25s"}, logrus.NewEntry(logrus.New()))
+
+	sign := func(roles []string, expiresIn time.Duration) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"sub":   "alice",
+			"aud":   "warehouse",
+			"exp":   time.Now().Add(expiresIn).Unix(),
+			"roles": roles,
+		})
+		token.Header["kid"] = "test-1"
+		signed, _ := token.SignedString(key)
+		return signed
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		statusCode    int
+		message       string
+	}{
+		{
+			name:       "missing_credentials",
+			method:     http.MethodGet,
+			path:       "/warehouse/v1/inventory",
+			statusCode: http.StatusUnauthorized,
+			message:    "a bearer token or an API key in the X-API-Key header is required",
+		},
+		{
+			name:          "expired_token",
+			method:        http.MethodGet,
+			path:          "/warehouse/v1/inventory",
+			authorization: "Bearer " + sign([]string{"viewer"}, -time.Minute),
+			statusCode:    http.StatusUnauthorized,
+			message:       "bearer token is not valid",
+		},
+		{
+			name:          "viewer_reads",
+			method:        http.MethodGet,
+			path:          "/warehouse/v1/inventory",
+			authorization: "Bearer " + sign([]string{"viewer"}, time.Hour),
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "viewer_cannot_sell",
+			method:        http.MethodPost,
+			path:          "/warehouse/v1/product/Dining%20Chair",
+			authorization: "Bearer " + sign([]string{"viewer"}, time.Hour),
+			statusCode:    http.StatusForbidden,
+			message:       "token has no product:sell scope",
+		},
+		{
+			name:          "clerk_sells",
+			method:        http.MethodPost,
+			path:          "/warehouse/v1/product/Dining%20Chair",
+			authorization: "bearer " + sign([]string{"clerk"}, time.Hour),
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "clerk_cannot_set_price",
+			method:        http.MethodPut,
+			path:          "/warehouse/v1/product/Dining%20Chair/price",
+			authorization: "Bearer " + sign([]string{"clerk"}, time.Hour),
+			statusCode:    http.StatusForbidden,
+			message:       "token has no price:write scope",
+		},
+		{
+			name:          "unknown_role",
+			method:        http.MethodGet,
+			path:          "/warehouse/v1/inventory",
+			authorization: "Bearer " + sign([]string{"auditor"}, time.Hour),
+			statusCode:    http.StatusForbidden,
+			message:       "token has no inventory:read scope",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.statusCode == http.StatusOK && tt.method == http.MethodGet {
+				inventory.EXPECT().GetInventory(gomock.Any()).Return(nil, []data.Stock{})
+			}
+			if tt.statusCode == http.StatusOK && tt.method == http.MethodPost {
+				inventory.EXPECT().SellProduct(gomock.Any(), "Dining Chair").Return(nil)
+			}
+
+			recorder := httptest.NewRecorder()
+			httpRequest, _ := http.NewRequest(tt.method, tt.path, nil)
+			if tt.authorization != "" {
+				httpRequest.Header.Set(authorizationHeader, tt.authorization)
+			}
+			server.router.ServeHTTP(recorder, httpRequest)
+
+			assert.Equal(t, recorder.Code, tt.statusCode)
+			if tt.statusCode != http.StatusOK {
+				var response ResponseError
+				_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+				assert.Equal(t, response.Message, tt.message)
+				assert.Equal(t, response.RID, recorder.Header().Get(requestIDHeader))
+			}
+			if tt.statusCode == http.StatusUnauthorized {
+				assert.NotEqual(t, recorder.Header().Get(authenticateHeader), "")
+			}
+		})
+	}
+}
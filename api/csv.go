@@ -0,0 +1,328 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// csv columns of the inventory, the first ones are required
+var (
+	inventoryColumns         = []string{"art_id", "name", "stock", "unit", "weight_kg", "length_cm", "width_cm", "height_cm", "category", "barcodes", "attributes", "unit_cost"}
+	inventoryRequiredColumns = 3
+)
+
+// csv columns of the products, one row per product article, the first ones are required
+var (
+	productColumns         = []string{"name", "art_id", "amount_of", "barcodes"}
+	productRequiredColumns = 3
+)
+
+// csv columns of the product stock export
+var productStockColumns = []string{"product_name", "stock_of_product"}
+
+// separators of the list values inside a csv cell
+const (
+	csvListSeparator  = "|"
+	csvValueSeparator = "="
+)
+
+//csvError collects the invalid rows of an uploaded csv
+type csvError struct {
+	rows []string
+}
+
+func (e *csvError) add(row int, format string, args ...interface{}) {
+	e.rows = append(e.rows, fmt.Sprintf("row %d: ", row)+fmt.Sprintf(format, args...))
+}
+
+func (e *csvError) Error() string {
+	return "invalid csv, " + strings.Join(e.rows, "; ")
+}
+
+//isCSV checks if the request body is csv
+func isCSV(context *gin.Context) bool {
+	return context.ContentType() == mimeCSV
+}
+
+//acceptsCSV checks if the client asks for a csv response
+func acceptsCSV(context *gin.Context) bool {
+	return context.NegotiateFormat(gin.MIMEJSON, mimeCSV) == mimeCSV
+}
+
+//writeCSV responds with the records, the first record is the header
+func writeCSV(context *gin.Context, records [][]string) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	err := writer.WriteAll(records)
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.Data(http.StatusOK, mimeCSV+"; charset=utf-8", buffer.Bytes())
+}
+
+//readCSV reads all records and maps the header to the column index.
+//Unknown, duplicated and missing required columns are rejected
+func readCSV(body io.Reader, columns []string, required int) (map[string]int, [][]string, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid csv, %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("invalid csv, header row is missing")
+	}
+
+	known := make(map[string]bool)
+	for _, column := range columns {
+		known[column] = true
+	}
+	header := make(map[string]int)
+	for i, column := range records[0] {
+		// spreadsheet programs may start the file with a byte order mark
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !known[column] {
+			return nil, nil, fmt.Errorf("invalid csv header, unknown column %q, columns are %s", column, strings.Join(columns, ","))
+		}
+		if _, ok := header[column]; ok {
+			return nil, nil, fmt.Errorf("invalid csv header, column %q is duplicated", column)
+		}
+		header[column] = i
+	}
+	for _, column := range columns[:required] {
+		if _, ok := header[column]; !ok {
+			return nil, nil, fmt.Errorf("invalid csv header, required column %q is missing", column)
+		}
+	}
+	return header, records[1:], nil
+}
+
+//csvCell gets the trimmed value of the column in the record, empty if the column is not in the header
+func csvCell(header map[string]int, record []string, column string) string {
+	i, ok := header[column]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+//csvList splits a list cell
+func csvList(cell string) []string {
+	if cell == "" {
+		return nil
+	}
+	var values []string
+	for _, value := range strings.Split(cell, csvListSeparator) {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+//parseInventoryCSV converts the csv rows to articles, one row per article
+func parseInventoryCSV(body io.Reader) (data.Inventory, error) {
+	var inventory data.Inventory
+	header, records, err := readCSV(body, inventoryColumns, inventoryRequiredColumns)
+	if err != nil {
+		return inventory, err
+	}
+
+	rowErr := &csvError{}
+	for i, record := range records {
+		// the header is the first row
+		row := i + 2
+		if len(record) != len(header) {
+			rowErr.add(row, "expected %d fields, got %d", len(header), len(record))
+			continue
+		}
+		article := data.Stock{
+			ArtId:    csvCell(header, record, "art_id"),
+			Name:     csvCell(header, record, "name"),
+			Stock:    csvCell(header, record, "stock"),
+			Unit:     csvCell(header, record, "unit"),
+			Category: csvCell(header, record, "category"),
+			Barcodes: csvList(csvCell(header, record, "barcodes")),
+		}
+		if article.ArtId == "" {
+			rowErr.add(row, "art_id is required")
+		}
+		if stock, err := strconv.Atoi(article.Stock); err != nil || stock < 0 {
+			rowErr.add(row, "stock %q is not a non negative number", article.Stock)
+		}
+
+		var numbers [5]float64
+		for j, column := range []string{"weight_kg", "length_cm", "width_cm", "height_cm", "unit_cost"} {
+			cell := csvCell(header, record, column)
+			if cell == "" {
+				continue
+			}
+			numbers[j], err = strconv.ParseFloat(cell, 64)
+			if err != nil || numbers[j] < 0 {
+				rowErr.add(row, "%s %q is not a non negative number", column, cell)
+			}
+		}
+		article.WeightKg = numbers[0]
+		article.UnitCost = numbers[4]
+		if numbers[1] != 0 || numbers[2] != 0 || numbers[3] != 0 {
+			article.Dimensions = &data.Dimensions{LengthCm: numbers[1], WidthCm: numbers[2], HeightCm: numbers[3]}
+		}
+
+		for _, barcode := range article.Barcodes {
+			if !data.ValidGTIN(barcode) {
+				rowErr.add(row, "barcode %s is not a valid GTIN", barcode)
+			}
+		}
+		for _, attribute := range csvList(csvCell(header, record, "attributes")) {
+			pair := strings.SplitN(attribute, csvValueSeparator, 2)
+			if len(pair) != 2 || strings.TrimSpace(pair[0]) == "" {
+				rowErr.add(row, "attribute %q is not in key%svalue form", attribute, csvValueSeparator)
+				continue
+			}
+			if article.Attributes == nil {
+				article.Attributes = make(map[string]string)
+			}
+			article.Attributes[strings.TrimSpace(pair[0])] = strings.TrimSpace(pair[1])
+		}
+		inventory.Inventory = append(inventory.Inventory, article)
+	}
+	if len(rowErr.rows) > 0 {
+		return inventory, rowErr
+	}
+	return inventory, nil
+}
+
+//parseProductsCSV converts the csv rows to products, one row per product article.
+//The rows of a product are merged in the order the product first appears
+func parseProductsCSV(body io.Reader) (data.Products, error) {
+	var products data.Products
+	header, records, err := readCSV(body, productColumns, productRequiredColumns)
+	if err != nil {
+		return products, err
+	}
+
+	rowErr := &csvError{}
+	index := make(map[string]int)
+	for i, record := range records {
+		// the header is the first row
+		row := i + 2
+		if len(record) != len(header) {
+			rowErr.add(row, "expected %d fields, got %d", len(header), len(record))
+			continue
+		}
+		name := csvCell(header, record, "name")
+		contain := data.ArticleContain{
+			ArtId:    csvCell(header, record, "art_id"),
+			AmountOf: csvCell(header, record, "amount_of"),
+		}
+		if name == "" {
+			rowErr.add(row, "name is required")
+		}
+		if contain.ArtId == "" {
+			rowErr.add(row, "art_id is required")
+		}
+		if amount, err := strconv.Atoi(contain.AmountOf); err != nil || amount <= 0 {
+			rowErr.add(row, "amount_of %q is not a positive number", contain.AmountOf)
+		}
+		barcodes := csvList(csvCell(header, record, "barcodes"))
+		for _, barcode := range barcodes {
+			if !data.ValidGTIN(barcode) {
+				rowErr.add(row, "barcode %s is not a valid GTIN", barcode)
+			}
+		}
+
+		j, ok := index[name]
+		if !ok {
+			j = len(products.Products)
+			index[name] = j
+			products.Products = append(products.Products, data.Product{Name: name})
+		}
+		product := &products.Products[j]
+		product.ContainArticles = append(product.ContainArticles, contain)
+		for _, barcode := range barcodes {
+			if !containsString(product.Barcodes, barcode) {
+				product.Barcodes = append(product.Barcodes, barcode)
+			}
+		}
+	}
+	if len(rowErr.rows) > 0 {
+		return products, rowErr
+	}
+	return products, nil
+}
+
+//inventoryCSV converts the articles to csv records with the same columns the upload accepts
+func inventoryCSV(stocks []data.Stock) [][]string {
+	records := [][]string{inventoryColumns}
+	for _, stock := range stocks {
+		var dimensions data.Dimensions
+		if stock.Dimensions != nil {
+			dimensions = *stock.Dimensions
+		}
+		keys := make([]string, 0, len(stock.Attributes))
+		for key := range stock.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		attributes := make([]string, 0, len(keys))
+		for _, key := range keys {
+			attributes = append(attributes, key+csvValueSeparator+stock.Attributes[key])
+		}
+		records = append(records, []string{
+			stock.ArtId,
+			stock.Name,
+			stock.Stock,
+			stock.Unit,
+			csvNumber(stock.WeightKg),
+			csvNumber(dimensions.LengthCm),
+			csvNumber(dimensions.WidthCm),
+			csvNumber(dimensions.HeightCm),
+			stock.Category,
+			strings.Join(stock.Barcodes, csvListSeparator),
+			strings.Join(attributes, csvListSeparator),
+			csvNumber(stock.UnitCost),
+		})
+	}
+	return records
+}
+
+//productStockCSV converts the product stocks to csv records
+func productStockCSV(stocks data.ProductStocks) [][]string {
+	records := [][]string{productStockColumns}
+	for _, stock := range stocks {
+		records = append(records, []string{stock.Name, stock.AvailableProductNo})
+	}
+	return records
+}
+
+//csvNumber formats the optional number, zero is left empty
+func csvNumber(number float64) string {
+	if number == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
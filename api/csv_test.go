@@ -0,0 +1,237 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestServer_uploadInventoryCSV(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		body       string
+		expected   data.Inventory
+		callDB     bool
+		statusCode int
+		message    string
+	}{
+		{
+			name:   "required_columns",
+			fields: fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:   "art_id,name,stock\n1,leg,12\n2,screw,17\n",
+			expected: data.Inventory{Inventory: []data.Stock{
+				{ArtId: "1", Name: "leg", Stock: "12"},
+				{ArtId: "2", Name: "screw", Stock: "17"},
+			}},
+			callDB:     true,
+			statusCode: http.StatusOK,
+			message:    "2 item inserted",
+		},
+		{
+			name:   "master_data_columns",
+			fields: fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body: "\ufeffStock,art_id,name,unit,weight_kg,length_cm,width_cm,height_cm,category,barcodes,attributes,unit_cost\n" +
+				"12,1,leg,pcs,0.4,70,4,4,legs,4006381333931|5901234123457,color=white|material=pine,2.75\n",
+			expected: data.Inventory{Inventory: []data.Stock{
+				{ArtId: "1", Name: "leg", Stock: "12", Unit: "pcs", WeightKg: 0.4,
+					Dimensions: &data.Dimensions{LengthCm: 70, WidthCm: 4, HeightCm: 4}, Barcodes: []string{"4006381333931", "5901234123457"},
+					Category: "legs", Attributes: map[string]string{"color": "white", "material": "pine"}, UnitCost: 2.75},
+			}},
+			callDB:     true,
+			statusCode: http.StatusOK,
+			message:    "1 item inserted",
+		},
+		{
+			name:       "missing_column",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:       "art_id,name\n1,leg\n",
+			statusCode: http.StatusBadRequest,
+			message:    `invalid csv header, required column "stock" is missing`,
+		},
+		{
+			name:       "unknown_column",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:       "art_id,name,stock,colour\n1,leg,12,white\n",
+			statusCode: http.StatusBadRequest,
+			message:    `invalid csv header, unknown column "colour", columns are ` + strings.Join(inventoryColumns, ","),
+		},
+		{
+			name:       "invalid_rows",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:       "art_id,name,stock,barcodes\n1,leg,12,\n2,screw,many,\n3,top,1,4006381333932\n4,shelf\n",
+			statusCode: http.StatusBadRequest,
+			message: `invalid csv, row 3: stock "many" is not a non negative number; ` +
+				"row 4: barcode 4006381333932 is not a valid GTIN; row 5: expected 4 fields, got 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+			context.Request = &http.Request{
+				Header: http.Header{"Content-Type": []string{"text/csv; charset=utf-8"}},
+				Body:   ioutil.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			if tt.callDB {
+				inventory.EXPECT().UploadInventory(gomock.Any(), tt.expected).Return(nil, len(tt.expected.Inventory))
+			}
+
+			server.uploadInventory(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+		})
+	}
+}
+
+func TestServer_uploadProductsCSV(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		body       string
+		expected   data.Products
+		callDB     bool
+		statusCode int
+		message    string
+	}{
+		{
+			name:   "rows_grouped_per_product",
+			fields: fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:   "name,art_id,amount_of,barcodes\nDining Chair,1,4,5901234123457\nDining Table,1,4,\nDining Chair,2,8,5901234123457\n",
+			expected: data.Products{Products: []data.Product{
+				{Name: "Dining Chair", ContainArticles: []data.ArticleContain{{ArtId: "1", AmountOf: "4"}, {ArtId: "2", AmountOf: "8"}}, Barcodes: []string{"5901234123457"}},
+				{Name: "Dining Table", ContainArticles: []data.ArticleContain{{ArtId: "1", AmountOf: "4"}}},
+			}},
+			callDB:     true,
+			statusCode: http.StatusOK,
+			message:    "2 product inserted",
+		},
+		{
+			name:       "invalid_rows",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:       "name,art_id,amount_of\nDining Chair,1,4\n,2,0\n",
+			statusCode: http.StatusBadRequest,
+			message:    `invalid csv, row 3: name is required; row 3: amount_of "0" is not a positive number`,
+		},
+		{
+			name:       "duplicated_column",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:       "name,art_id,amount_of,art_id\nDining Chair,1,4,1\n",
+			statusCode: http.StatusBadRequest,
+			message:    `invalid csv header, column "art_id" is duplicated`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+			context.Request = &http.Request{
+				Header: http.Header{"Content-Type": []string{"text/csv"}},
+				Body:   ioutil.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			if tt.callDB {
+				inventory.EXPECT().UploadProducts(gomock.Any(), tt.expected).Return(nil, len(tt.expected.Products))
+			}
+
+			server.uploadProducts(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+		})
+	}
+}
+
+func TestServer_exportCSV(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := &Server{
+		Inventory: inventory,
+		router:    gin.New(),
+		Config:    Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"},
+		Logger:    logrus.NewEntry(logrus.New()),
+	}
+
+	t.Run("inventory", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context, _ := gin.CreateTestContext(recorder)
+		context.Request = &http.Request{URL: &url.URL{}, Header: http.Header{"Accept": []string{"text/csv"}}}
+		inventory.EXPECT().GetInventory(gomock.Any()).Return(nil, []data.Stock{
+			{ArtId: "1", Name: "leg", Stock: "12", Unit: "pcs", WeightKg: 0.4,
+				Dimensions: &data.Dimensions{LengthCm: 70, WidthCm: 4, HeightCm: 4}, Barcodes: []string{"4006381333931", "5901234123457"},
+				Category: "legs", Attributes: map[string]string{"material": "pine", "color": "white"}, UnitCost: 2.5},
+			{ArtId: "2", Name: "screw, small", Stock: "17"},
+		})
+
+		server.getInventory(context)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, "art_id,name,stock,unit,weight_kg,length_cm,width_cm,height_cm,category,barcodes,attributes,unit_cost\n"+
+			"1,leg,12,pcs,0.4,70,4,4,legs,4006381333931|5901234123457,color=white|material=pine,2.5\n"+
+			"2,\"screw, small\",17,,,,,,,,,\n", recorder.Body.String())
+	})
+
+	t.Run("product_stock", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		context, _ := gin.CreateTestContext(recorder)
+		context.Request = &http.Request{URL: &url.URL{}, Header: http.Header{"Accept": []string{"text/csv"}}}
+		inventory.EXPECT().GetProductStock(gomock.Any()).Return(nil, data.ProductStocks{{Name: "Dining Chair", AvailableProductNo: "2"}})
+
+		server.getProductStock(context)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "product_name,stock_of_product\nDining Chair,2\n", recorder.Body.String())
+	})
+}
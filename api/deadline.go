@@ -0,0 +1,38 @@
+package api
+
+import (
+	"context"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+//withTimeout returns the request with a context that is done after the timeout or when the client disconnects
+func withTimeout(httpRequest *http.Request, timeout time.Duration) (*http.Request, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(httpRequest.Context(), timeout)
+	return httpRequest.WithContext(ctx), cancel
+}
+
+//detachedTimeout returns a context with the values of the request that is done after the timeout only, not when the
+//request timed out or the client disconnected
+func detachedTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(request.Detach(ctx), timeout)
+}
+
+//deadlineExceeded checks if the backend timeout of the request has passed
+func deadlineExceeded(httpRequest *http.Request) bool {
+	return httpRequest != nil && httpRequest.Context().Err() == context.DeadlineExceeded
+}
+
+//respondError writes the error response. When the request failed because its deadline passed, the response is
+//504 with the deadline exceeded code and the error of the handler is kept in errors
+func respondError(context *gin.Context, statusCode int, response ResponseError) {
+	if deadlineExceeded(context.Request) {
+		statusCode = http.StatusGatewayTimeout
+		response.StatusCode = codeDeadlineExceeded
+		response.Error = response.Message
+		response.Message = "request is not completed within the backend timeout"
+	}
+	context.JSON(statusCode, response)
+}
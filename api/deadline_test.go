@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServer_setDeadline(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+
+	tests := []struct {
+		name           string
+		backendTimeout string
+		block          bool
+		statusCode     int
+		code           int
+		message        string
+	}{
+		{
+			name:           "in_time",
+			backendTimeout: "1s",
+			block:          false,
+			statusCode:     http.StatusNotFound,
+			message:        "inventory is not readable",
+		},
+		{
+			name:           "deadline_exceeded",
+			backendTimeout: "20ms",
+			block:          true,
+			statusCode:     http.StatusGatewayTimeout,
+			code:           codeDeadlineExceeded,
+			message:        "request is not completed within the backend timeout",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(inventory, nil, nil, Configuration{BackendTimeout: tt.backendTimeout, AuthDisabled: true}, logrus.NewEntry(logrus.New()))
+			inventory.EXPECT().GetInventory(gomock.Any()).DoAndReturn(func(ctx context.Context) (error, []data.Stock) {
+				_, hasDeadline := ctx.Deadline()
+				assert.Equal(t, hasDeadline, true)
+				if tt.block {
+					<-ctx.Done()
+					return errors.New("pq: canceling statement due to user request"), nil
+				}
+				return errors.New("inventory is not readable"), nil
+			})
+
+			recorder := httptest.NewRecorder()
+			httpRequest, _ := http.NewRequest(http.MethodGet, "/warehouse/v1/inventory", nil)
+			server.router.ServeHTTP(recorder, httpRequest)
+
+			assert.Equal(t, recorder.Code, tt.statusCode)
+			var response ResponseError
+			_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+			assert.Equal(t, response.StatusCode, tt.code)
+			assert.Equal(t, response.Message, tt.message)
+			assert.Equal(t, response.RID, recorder.Header().Get(requestIDHeader))
+			if tt.block {
+				assert.Equal(t, response.Error, "pq: canceling statement due to user request")
+			}
+		})
+	}
+}
+
+func TestServer_sellProductDeadline(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := NewServer(inventory, nil, nil, Configuration{BackendTimeout: "20ms", AuthDisabled: true}, logrus.NewEntry(logrus.New()))
+	inventory.EXPECT().SellProduct(gomock.Any(), "Dinning Table").DoAndReturn(func(ctx context.Context, productName string) error {
+		<-ctx.Done()
+		return errors.New("pq: canceling statement due to user request")
+	})
+
+	recorder := httptest.NewRecorder()
+	httpRequest, _ := http.NewRequest(http.MethodPost, "/warehouse/v1/product/Dinning%20Table", nil)
+	server.router.ServeHTTP(recorder, httpRequest)
+
+	assert.Equal(t, recorder.Code, http.StatusGatewayTimeout)
+	var response ResponseError
+	_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.Equal(t, response.StatusCode, codeDeadlineExceeded)
+	assert.Equal(t, response.Error, "pq: canceling statement due to user request")
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"errors"
+	"github.com/auknl/warehouse/db"
+	"net/http"
+)
+
+//codes of the error responses, they tell apart the failures sharing a status
+const (
+	//codeDeadlineExceeded is the code of the requests not completed within the backend timeout
+	codeDeadlineExceeded = 1001
+)
+
+//errorStatusCode maps the known database errors to a response status, others get the given status
+func errorStatusCode(err error, defaultStatus int) int {
+	switch {
+	case errors.Is(err, db.ErrOrderedProductNotInSystem), errors.Is(err, db.ErrReceivedArticleNotOrdered),
+		errors.Is(err, db.ErrProductNotReturnable), errors.Is(err, db.ErrInvalidDamagedArticle):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrOrderNotFound), errors.Is(err, db.ErrPurchaseOrderNotFound),
+		errors.Is(err, db.ErrArticleNotFound), errors.Is(err, db.ErrSupplierNotFound), errors.Is(err, db.ErrBarcodeNotFound),
+		errors.Is(err, db.ErrImportJobNotFound), errors.Is(err, db.ErrProductNotFound), errors.Is(err, db.ErrPriceNotFound),
+		errors.Is(err, db.ErrAPIKeyNotFound), errors.Is(err, db.ErrSaleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrInvalidOrderTransition), errors.Is(err, db.ErrInsufficientStock),
+		errors.Is(err, db.ErrPurchaseOrderClosed), errors.Is(err, db.ErrSupplierExists),
+		errors.Is(err, db.ErrBarcodeNotAdjustable), errors.Is(err, db.ErrSaleOfOtherProduct), errors.Is(err, db.ErrReturnExceedsSale):
+		return http.StatusConflict
+	}
+	return defaultStatus
+}
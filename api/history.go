@@ -0,0 +1,41 @@
+package api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// queryDate is the date only form of the time query parameters
+const queryDate = "2006-01-02"
+
+//parseTimeQuery gets the time of the query parameter, nil when it is not given. A date is the start of that day (UTC),
+//or its end when endOfDay is set
+func parseTimeQuery(context *gin.Context, name string, endOfDay bool) (*time.Time, error) {
+	value := context.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	moment, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return &moment, nil
+	}
+	moment, err = time.Parse(queryDate, value)
+	if err == nil {
+		if endOfDay {
+			moment = moment.AddDate(0, 0, 1).Add(-time.Microsecond)
+		}
+		return &moment, nil
+	}
+	return nil, fmt.Errorf("%s %q must be an RFC 3339 time like 2021-03-31T23:59:59Z or a date like 2021-03-31", name, value)
+}
+
+//parseAsOf gets the time of the as_of query parameter, ok is false when the current state is asked.
+//For a date the state at the end of that day is given
+func parseAsOf(context *gin.Context) (asOf time.Time, ok bool, err error) {
+	moment, err := parseTimeQuery(context, asOfParam, true)
+	if err != nil || moment == nil {
+		return asOf, false, err
+	}
+	return *moment, true, nil
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestServer_getInventoryAsOf(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+	stocks := []data.Stock{{ArtId: "1", Name: "leg", Stock: "12"}}
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		asOf       string
+		callDB     bool
+		expected   time.Time
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "time",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			asOf:       "2021-03-31T12:00:00+02:00",
+			callDB:     true,
+			expected:   time.Date(2021, 3, 31, 10, 0, 0, 0, time.UTC),
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "end_of_the_day",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			asOf:       "2021-03-31",
+			callDB:     true,
+			expected:   time.Date(2021, 3, 31, 23, 59, 59, 999999000, time.UTC),
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "invalid_time",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			asOf:       "31.03.2021",
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    `as_of "31.03.2021" must be an RFC 3339 time like 2021-03-31T23:59:59Z or a date like 2021-03-31`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+			context.Request = &http.Request{URL: &url.URL{RawQuery: url.Values{asOfParam: {tt.asOf}}.Encode()}}
+
+			if tt.callDB {
+				inventory.EXPECT().GetInventoryAsOf(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ interface{}, asOf time.Time) (error, []data.Stock) {
+						assert.Equal(t, asOf.Equal(tt.expected), true)
+						return nil, stocks
+					})
+			}
+
+			server.getInventory(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+			if tt.callDB {
+				assert.Equal(t, response.Inventory, stocks)
+			}
+		})
+	}
+}
+
+func TestServer_getProductStockAsOf(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+	server := &Server{
+		Inventory: inventory,
+		router:    gin.New(),
+		Config:    Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"},
+		Logger:    logrus.NewEntry(logrus.New()),
+	}
+	context.Request = &http.Request{URL: &url.URL{RawQuery: asOfParam + "=2021-03-31T10:00:00Z"}}
+	stocks := data.ProductStocks{{Name: "Dining Chair", AvailableProductNo: "2"}}
+	inventory.EXPECT().GetProductStockAsOf(gomock.Any(), time.Date(2021, 3, 31, 10, 0, 0, 0, time.UTC)).Return(nil, stocks)
+
+	server.getProductStock(context)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response ResponseProduct
+	byteArr, _ := ioutil.ReadAll(recorder.Body)
+	_ = json.Unmarshal(byteArr, &response)
+	assert.Equal(t, response.ProductStocks, stocks)
+}
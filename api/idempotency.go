@@ -0,0 +1,96 @@
+package api
+
+import (
+	"bytes"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+//responseRecorder keeps a copy of the response body while writing it to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
+
+func (recorder *responseRecorder) WriteString(data string) (int, error) {
+	recorder.body.WriteString(data)
+	return recorder.ResponseWriter.WriteString(data)
+}
+
+//idempotent executes the request only once per Idempotency-Key header and replays the stored response for the repeats
+func (server *Server) idempotent(context *gin.Context) {
+	key := context.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		context.Next()
+		return
+	}
+
+	log := server.requestLog(context).WithField("idempotency_key", key)
+	retention, err := time.ParseDuration(server.Config.IdempotencyRetention)
+	if err != nil {
+		log.WithField("err", err).Error("Could not parse idempotency retention duration")
+		retention = defaultIdempotencyRetention
+	}
+	staleAfter, err := time.ParseDuration(server.Config.IdempotencyStaleAfter)
+	if err != nil {
+		log.WithField("err", err).Error("Could not parse idempotency stale after duration")
+		staleAfter = defaultIdempotencyStaleAfter
+	}
+
+	err, stored := server.Inventory.ReserveIdempotencyKey(context.Request.Context(), key, context.Request.Method, context.Request.URL.Path, retention, staleAfter)
+	switch {
+	case err == db.ErrIdempotencyInProgress:
+		context.AbortWithStatusJSON(http.StatusConflict, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	case err == db.ErrIdempotencyKeyReused:
+		context.AbortWithStatusJSON(http.StatusUnprocessableEntity, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	case err != nil:
+		context.Abort()
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	case stored != nil:
+		log.Debug("idempotent, replays the stored response")
+		context.Header(idempotentReplayHeader, "true")
+		context.Data(stored.StatusCode, gin.MIMEJSON, stored.Body)
+		context.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: context.Writer, body: &bytes.Buffer{}}
+	context.Writer = recorder
+	context.Next()
+
+	//the request context is done after a timeout or when the client left, the key is released or saved without it
+	ctx, cancel := detachedTimeout(context.Request.Context(), idempotencyStoreTimeout)
+	defer cancel()
+	// server side failures are not stored, so the client can retry with the same key
+	if context.Writer.Status() >= http.StatusInternalServerError {
+		err = server.Inventory.ReleaseIdempotencyKey(ctx, key)
+		if err != nil {
+			log.WithField("err", err).Error("Could not release idempotency key")
+		}
+		return
+	}
+	err = server.Inventory.SaveIdempotentResponse(ctx, key, context.Writer.Status(), recorder.body.Bytes())
+	if err != nil {
+		log.WithField("err", err).Error("Could not store idempotent response")
+	}
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServer_idempotent(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := &Server{
+		Inventory: inventory,
+		Config:    Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s", IdempotencyRetention: "1h"},
+		Logger:    logrus.NewEntry(logrus.New()),
+	}
+
+	tests := []struct {
+		name          string
+		key           string
+		handlerStatus int
+		prepare       func()
+		wantExecuted  bool
+		statusCode    int
+		body          string
+	}{
+		{
+			name:          "without_key",
+			handlerStatus: http.StatusOK,
+			prepare:       func() {},
+			wantExecuted:  true,
+			statusCode:    http.StatusOK,
+			body:          `{"message":"executed"}`,
+		},
+		{
+			name:          "first_request",
+			key:           "key-1",
+			handlerStatus: http.StatusOK,
+			prepare: func() {
+				inventory.EXPECT().ReserveIdempotencyKey(gomock.Any(), "key-1", http.MethodPost, "/sell", gomock.Any(), gomock.Any()).Return(nil, nil)
+				inventory.EXPECT().SaveIdempotentResponse(gomock.Any(), "key-1", http.StatusOK, []byte(`{"message":"executed"}`)).Return(nil)
+			},
+			wantExecuted: true,
+			statusCode:   http.StatusOK,
+			body:         `{"message":"executed"}`,
+		},
+		{
+			name:          "repeated_request",
+			key:           "key-1",
+			handlerStatus: http.StatusOK,
+			prepare: func() {
+				inventory.EXPECT().ReserveIdempotencyKey(gomock.Any(), "key-1", http.MethodPost, "/sell", gomock.Any(), gomock.Any()).
+					Return(nil, &data.IdempotentResponse{Key: "key-1", StatusCode: http.StatusOK, Body: []byte(`{"message":"stored"}`)})
+			},
+			wantExecuted: false,
+			statusCode:   http.StatusOK,
+			body:         `{"message":"stored"}`,
+		},
+		{
+			name:          "concurrent_request",
+			key:           "key-2",
+			handlerStatus: http.StatusOK,
+			prepare: func() {
+				inventory.EXPECT().ReserveIdempotencyKey(gomock.Any(), "key-2", http.MethodPost, "/sell", gomock.Any(), gomock.Any()).Return(db.ErrIdempotencyInProgress, nil)
+			},
+			wantExecuted: false,
+			statusCode:   http.StatusConflict,
+		},
+		{
+			name:          "reused_key",
+			key:           "key-3",
+			handlerStatus: http.StatusOK,
+			prepare: func() {
+				inventory.EXPECT().ReserveIdempotencyKey(gomock.Any(), "key-3", http.MethodPost, "/sell", gomock.Any(), gomock.Any()).Return(db.ErrIdempotencyKeyReused, nil)
+			},
+			wantExecuted: false,
+			statusCode:   http.StatusUnprocessableEntity,
+		},
+		{
+			name:          "reserve_failed",
+			key:           "key-4",
+			handlerStatus: http.StatusOK,
+			prepare: func() {
+				inventory.EXPECT().ReserveIdempotencyKey(gomock.Any(), "key-4", http.MethodPost, "/sell", gomock.Any(), gomock.Any()).Return(errors.New("db down"), nil)
+			},
+			wantExecuted: false,
+			statusCode:   http.StatusInternalServerError,
+		},
+		{
+			name:          "server_error_released",
+			key:           "key-5",
+			handlerStatus: http.StatusInternalServerError,
+			prepare: func() {
+				inventory.EXPECT().ReserveIdempotencyKey(gomock.Any(), "key-5", http.MethodPost, "/sell", gomock.Any(), gomock.Any()).Return(nil, nil)
+				inventory.EXPECT().ReleaseIdempotencyKey(gomock.Any(), "key-5").Return(nil)
+			},
+			wantExecuted: true,
+			statusCode:   http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executed := false
+			router := gin.New()
+			router.POST("/sell", server.idempotent, func(context *gin.Context) {
+				executed = true
+				context.Data(tt.handlerStatus, gin.MIMEJSON, []byte(`{"message":"executed"}`))
+			})
+			tt.prepare()
+
+			recorder := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/sell", nil)
+			if tt.key != "" {
+				req.Header.Set(idempotencyKeyHeader, tt.key)
+			}
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.wantExecuted, executed)
+			assert.Equal(t, tt.statusCode, recorder.Code)
+			if tt.body != "" {
+				assert.Equal(t, tt.body, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_idempotentDeadline(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := NewServer(inventory, nil, nil, Configuration{BackendTimeout: "20ms", IdempotencyRetention: "1h", AuthDisabled: true}, logrus.NewEntry(logrus.New()))
+
+	var rid, tenant string
+	var releaseErr error
+	inventory.EXPECT().ReserveIdempotencyKey(gomock.Any(), "key-6", http.MethodPost, "/warehouse/v1/product/Chair", gomock.Any(), gomock.Any()).Return(nil, nil)
+	inventory.EXPECT().SellProduct(gomock.Any(), "Chair").DoAndReturn(func(ctx context.Context, productName string) error {
+		<-ctx.Done()
+		return errors.New("pq: canceling statement due to user request")
+	})
+	inventory.EXPECT().ReleaseIdempotencyKey(gomock.Any(), "key-6").DoAndReturn(func(ctx context.Context, key string) error {
+		//the key is released after the request timed out, on a context that keeps the values of the request
+		releaseErr = ctx.Err()
+		rid = request.GetRID(ctx)
+		tenant = request.GetTenant(ctx)
+		return nil
+	})
+
+	recorder := httptest.NewRecorder()
+	httpRequest, _ := http.NewRequest(http.MethodPost, "/warehouse/v1/product/Chair", nil)
+	httpRequest.Header.Set(idempotencyKeyHeader, "key-6")
+	httpRequest.Header.Set(tenantHeader, "acme")
+	server.router.ServeHTTP(recorder, httpRequest)
+
+	assert.Equal(t, recorder.Code, http.StatusGatewayTimeout)
+	assert.Equal(t, releaseErr, nil)
+	assert.Equal(t, rid, recorder.Header().Get(requestIDHeader))
+	assert.Equal(t, tenant, "acme")
+}
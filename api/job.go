@@ -0,0 +1,395 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//importBatch keeps the decoded records of an import job until they are uploaded together
+type importBatch struct {
+	kind     data.ImportKind
+	records  []int
+	articles []data.Stock
+	products []data.Product
+}
+
+//add decodes and validates the record
+func (batch *importBatch) add(record int, line []byte) error {
+	switch batch.kind {
+	case data.ImportInventory:
+		var article data.Stock
+		err := json.Unmarshal(line, &article)
+		if err != nil {
+			return err
+		}
+		err = validateArticleBarcodes(data.Inventory{Inventory: []data.Stock{article}})
+		if err != nil {
+			return err
+		}
+		batch.articles = append(batch.articles, article)
+	case data.ImportProducts:
+		var product data.Product
+		err := json.Unmarshal(line, &product)
+		if err != nil {
+			return err
+		}
+		err = validateProductBarcodes(data.Products{Products: []data.Product{product}})
+		if err != nil {
+			return err
+		}
+		batch.products = append(batch.products, product)
+	default:
+		return fmt.Errorf("unknown import kind %s", batch.kind)
+	}
+	batch.records = append(batch.records, record)
+	return nil
+}
+
+//single gets the i-th record as a batch of its own
+func (batch *importBatch) single(i int) importBatch {
+	single := importBatch{kind: batch.kind, records: batch.records[i : i+1]}
+	if batch.kind == data.ImportInventory {
+		single.articles = batch.articles[i : i+1]
+	} else {
+		single.products = batch.products[i : i+1]
+	}
+	return single
+}
+
+//isAsync checks if the client prefers the request to be processed in the background
+func isAsync(context *gin.Context) bool {
+	for _, preference := range strings.Split(context.GetHeader(preferHeader), ",") {
+		if strings.EqualFold(strings.TrimSpace(preference), respondAsync) {
+			return true
+		}
+	}
+	return false
+}
+
+//createImportJob queues the upload as an import job and responds with the job right away
+func (server *Server) createImportJob(context *gin.Context, kind data.ImportKind) {
+	log := server.requestLog(context)
+	log.Debug("createImportJob")
+	payload, total, err := importPayload(kind, context.ContentType(), context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, job := server.Inventory.CreateImportJob(context.Request.Context(), data.ImportJob{Kind: kind, Total: total, Payload: payload})
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	// wake the worker up without waiting for the next poll
+	select {
+	case server.jobs <- struct{}{}:
+	default:
+	}
+
+	context.Header(preferenceAppliedHeader, respondAsync)
+	context.Header("Location", fmt.Sprintf("/warehouse/v1/jobs/%d", job.JobId))
+	context.JSON(http.StatusAccepted, ResponseProduct{
+		Job:     &job,
+		Message: fmt.Sprintf("Import job %d is queued", job.JobId),
+	})
+	return
+}
+
+//getImportJob provides the state, progress and record errors of the import job
+func (server *Server) getImportJob(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getImportJob")
+	jobId, err := strconv.Atoi(context.Param(jobID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "job id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, job := server.Inventory.GetImportJob(context.Request.Context(), jobId)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Job: &job,
+	})
+	return
+}
+
+//importPayload converts the upload to one json record per line, so the job can continue from any record
+func importPayload(kind data.ImportKind, contentType string, body io.Reader) ([]byte, int, error) {
+	if contentType == mimeNDJSON {
+		payload, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, 0, err
+		}
+		total := 0
+		for _, line := range bytes.Split(payload, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				total++
+			}
+		}
+		return payload, total, nil
+	}
+
+	var records []interface{}
+	switch kind {
+	case data.ImportInventory:
+		var inventory data.Inventory
+		var err error
+		if contentType == mimeCSV {
+			inventory, err = parseInventoryCSV(body)
+		} else {
+			err = json.NewDecoder(body).Decode(&inventory)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, article := range inventory.Inventory {
+			records = append(records, article)
+		}
+	case data.ImportProducts:
+		var products data.Products
+		var err error
+		if contentType == mimeCSV {
+			products, err = parseProductsCSV(body)
+		} else {
+			err = json.NewDecoder(body).Decode(&products)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, product := range products.Products {
+			records = append(records, product)
+		}
+	}
+
+	var payload bytes.Buffer
+	encoder := json.NewEncoder(&payload)
+	for _, record := range records {
+		err := encoder.Encode(record)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return payload.Bytes(), len(records), nil
+}
+
+//startImportJobs runs the import jobs in the background until stopImportJobs
+func (server *Server) startImportJobs() {
+	ctx, cancel := context.WithCancel(context.Background())
+	server.stopJobs = cancel
+	server.jobsDone = make(chan struct{})
+	go func() {
+		defer close(server.jobsDone)
+		server.runImportJobs(ctx)
+	}()
+}
+
+//stopImportJobs cancels the import job worker and waits for it until the context is done. The batch in progress is
+//rolled back, the job continues from its last saved batch when it is taken over
+func (server *Server) stopImportJobs(ctx context.Context) error {
+	if server.stopJobs == nil {
+		return nil
+	}
+	server.stopJobs()
+	select {
+	case <-server.jobsDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//runImportJobs runs the queued import jobs until the context is done. The database is polled, so the jobs queued by
+//other instances and the jobs left running by a stopped process are picked up too
+func (server *Server) runImportJobs(ctx context.Context) {
+	pollInterval, err := time.ParseDuration(server.Config.JobPollInterval)
+	if err != nil {
+		server.Logger.WithField("err", err).Error("Could not parse job poll interval duration")
+		pollInterval = defaultJobPollInterval
+	}
+	for {
+		for server.runNextImportJob(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-server.jobs:
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+//runNextImportJob claims an import job and runs it, false is returned when there is no job to run
+func (server *Server) runNextImportJob(ctx context.Context) bool {
+	staleAfter, err := time.ParseDuration(server.Config.JobStaleAfter)
+	if err != nil {
+		server.Logger.WithField("err", err).Error("Could not parse job stale after duration")
+		staleAfter = defaultJobStaleAfter
+	}
+	err, job := server.Inventory.ClaimImportJob(ctx, server.worker, staleAfter)
+	if err != nil {
+		server.Logger.WithField("err", err).Error("Could not claim import job")
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	// the job is run in the tenant it was created in
+	jobCtx, cancel := context.WithCancel(request.WithTenant(request.WithID(ctx, fmt.Sprintf("job-%d", job.JobId)), job.TenantId))
+	renewing := make(chan struct{})
+	go func() {
+		defer close(renewing)
+		server.renewImportJob(jobCtx, cancel, *job, staleAfter/3)
+	}()
+	err = server.runImportJob(jobCtx, *job)
+	cancel()
+	<-renewing
+	if err != nil {
+		server.Logger.WithField("job_id", job.JobId).WithField("err", err).Error("Import job is interrupted")
+	}
+	return ctx.Err() == nil
+}
+
+//renewImportJob renews the lease of the worker on the job every interval until the context is done, so a long batch
+//is not taken over by another worker. The job is cancelled when it is lost to another worker anyway
+func (server *Server) renewImportJob(ctx context.Context, cancel context.CancelFunc, job data.ImportJob, interval time.Duration) {
+	log := server.Logger.WithField("rid", request.GetRID(ctx)).WithField("tenant", request.GetTenant(ctx))
+	if interval <= 0 {
+		interval = defaultJobStaleAfter / 3
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := server.Inventory.RenewImportJob(ctx, job.JobId, job.Worker)
+		if err == db.ErrImportJobLost {
+			log.Error("renewImportJob, the job is taken over by another worker, it is cancelled")
+			cancel()
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			log.WithField("err", err).Error("Could not renew import job")
+		}
+	}
+}
+
+//runImportJob uploads the records of the job in batches and saves the progress after every batch.
+//The records processed before are skipped, so a job taken over from a stopped process continues where it was left
+func (server *Server) runImportJob(ctx context.Context, job data.ImportJob) error {
+	log := server.Logger.WithField("rid", request.GetRID(ctx)).WithField("tenant", request.GetTenant(ctx))
+	log.WithField("processed", job.Processed).Info("runImportJob, started")
+	batch := importBatch{kind: job.Kind}
+	record := 0
+	commit := func(finish bool) error {
+		if len(batch.records) > 0 {
+			inserted, err := server.uploadImportBatch(ctx, batch)
+			if err != nil {
+				if err := server.Inventory.Ping(); err != nil {
+					return err
+				}
+				// nothing of the failed batch is committed, the records are uploaded one by one to find the failing ones
+				inserted = 0
+				for i := range batch.records {
+					single, err := server.uploadImportBatch(ctx, batch.single(i))
+					if err != nil {
+						job.Errors = append(job.Errors, data.RecordError{Record: batch.records[i], Message: err.Error()})
+						continue
+					}
+					inserted += single
+				}
+			}
+			job.Records += inserted
+			job.Batches++
+		}
+		job.Processed = record
+		if finish {
+			job.State = finishedState(job)
+		}
+		batch = importBatch{kind: job.Kind}
+		return server.Inventory.UpdateImportJob(ctx, job)
+	}
+
+	for _, line := range bytes.Split(job.Payload, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		record++
+		if record <= job.Processed {
+			continue
+		}
+		err := batch.add(record, line)
+		if err != nil {
+			job.Errors = append(job.Errors, data.RecordError{Record: record, Message: err.Error()})
+		}
+		if len(batch.records) == server.uploadBatchSize() {
+			err = commit(false)
+			if err != nil {
+				return err
+			}
+			log.WithField("processed", job.Processed).WithField("total", job.Total).Info("runImportJob, committed a batch")
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	err := commit(true)
+	if err != nil {
+		return err
+	}
+	log.WithField("state", job.State).WithField("records", job.Records).Info("runImportJob, finished")
+	return nil
+}
+
+//uploadImportBatch uploads the records of the batch in a single transaction
+func (server *Server) uploadImportBatch(ctx context.Context, batch importBatch) (int, error) {
+	if batch.kind == data.ImportInventory {
+		err, inserted := server.Inventory.UploadInventory(ctx, data.Inventory{Inventory: batch.articles})
+		return inserted, err
+	}
+	err, inserted := server.Inventory.UploadProducts(ctx, data.Products{Products: batch.products})
+	return inserted, err
+}
+
+//finishedState tells how the job ended by its record errors
+func finishedState(job data.ImportJob) data.JobState {
+	switch {
+	case len(job.Errors) == 0:
+		return data.JobSucceeded
+	case job.Records == 0:
+		return data.JobFailed
+	}
+	return data.JobPartiallySucceeded
+}
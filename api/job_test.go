@@ -0,0 +1,324 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer_createImportJob(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name        string
+		fields      fields
+		contentType string
+		body        string
+		callDB      bool
+		payload     string
+		total       int
+		statusCode  int
+		message     string
+	}{
+		{
+			name:        "json_upload",
+			fields:      fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			contentType: "application/json",
+			body:        `{"inventory":[{"art_id":"1","name":"leg","stock":"12"},{"art_id":"2","name":"screw","stock":"17"}]}`,
+			callDB:      true,
+			payload:     `{"art_id":"1","name":"leg","stock":"12"}` + "\n" + `{"art_id":"2","name":"screw","stock":"17"}` + "\n",
+			total:       2,
+			statusCode:  http.StatusAccepted,
+			message:     "Import job 1 is queued",
+		},
+		{
+			name:        "csv_upload",
+			fields:      fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			contentType: "text/csv",
+			body:        "art_id,name,stock\n1,leg,12\n",
+			callDB:      true,
+			payload:     `{"art_id":"1","name":"leg","stock":"12"}` + "\n",
+			total:       1,
+			statusCode:  http.StatusAccepted,
+			message:     "Import job 1 is queued",
+		},
+		{
+			name:        "ndjson_upload_is_kept",
+			fields:      fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			contentType: "application/x-ndjson",
+			body:        `{"art_id":"1","name":"leg","stock":"12"}` + "\n\n" + `not json` + "\n",
+			callDB:      true,
+			payload:     `{"art_id":"1","name":"leg","stock":"12"}` + "\n\n" + `not json` + "\n",
+			total:       2,
+			statusCode:  http.StatusAccepted,
+			message:     "Import job 1 is queued",
+		},
+		{
+			name:        "invalid_csv_header",
+			fields:      fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			contentType: "text/csv",
+			body:        "art_id,name\n1,leg\n",
+			callDB:      false,
+			statusCode:  http.StatusBadRequest,
+			message:     `invalid csv header, required column "stock" is missing`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+			context.Request = &http.Request{
+				Header: http.Header{"Content-Type": []string{tt.contentType}, "Prefer": []string{"respond-async, wait=10"}},
+				Body:   ioutil.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			if tt.callDB {
+				job := data.ImportJob{Kind: data.ImportInventory, Total: tt.total, Payload: []byte(tt.payload)}
+				queued := job
+				queued.JobId = 1
+				queued.State = data.JobQueued
+				inventory.EXPECT().CreateImportJob(gomock.Any(), job).Return(nil, queued)
+			}
+
+			server.uploadInventory(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+			if tt.callDB {
+				assert.Equal(t, recorder.Header().Get("Location"), "/warehouse/v1/jobs/1")
+				assert.Equal(t, response.Job.State, data.JobQueued)
+			}
+		})
+	}
+}
+
+func TestServer_getImportJob(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		jobId      string
+		id         int
+		callDB     bool
+		dbErr      error
+		job        data.ImportJob
+		statusCode int
+	}{
+		{
+			name:   "job_with_errors",
+			fields: fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			jobId:  "1",
+			id:     1,
+			callDB: true,
+			job: data.ImportJob{JobId: 1, Kind: data.ImportInventory, State: data.JobPartiallySucceeded, Total: 3, Processed: 3, Records: 2, Batches: 1,
+				Errors: []data.RecordError{{Record: 2, Message: "barcode 1 of article 2 is not a valid GTIN"}}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "job_not_found",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			jobId:      "2",
+			id:         2,
+			callDB:     true,
+			dbErr:      db.ErrImportJobNotFound,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "job_id_not_a_number",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			jobId:      "first",
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			context.Params = []gin.Param{
+				{
+					Key:   jobID,
+					Value: tt.jobId,
+				},
+			}
+			context.Request, _ = http.NewRequest(http.MethodGet, "/warehouse/v1/jobs/"+tt.jobId, nil)
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			if tt.callDB {
+				inventory.EXPECT().GetImportJob(gomock.Any(), tt.id).Return(tt.dbErr, tt.job)
+			}
+
+			server.getImportJob(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			if tt.statusCode == http.StatusOK {
+				var response ResponseProduct
+				byteArr, _ := ioutil.ReadAll(recorder.Body)
+				_ = json.Unmarshal(byteArr, &response)
+				assert.Equal(t, *response.Job, tt.job)
+			}
+		})
+	}
+}
+
+func TestServer_runImportJob(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := &Server{
+		Inventory: inventory,
+		router:    gin.New(),
+		Config:    Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s", UploadBatchSize: 2},
+		Logger:    logrus.NewEntry(logrus.New()),
+	}
+	leg := data.Stock{ArtId: "1", Name: "leg", Stock: "12"}
+	seat := data.Stock{ArtId: "3", Name: "seat", Stock: "2"}
+	top := data.Stock{ArtId: "4", Name: "table top", Stock: "1"}
+	var payload bytes.Buffer
+	encoder := json.NewEncoder(&payload)
+	_ = encoder.Encode(leg)
+	payload.WriteString("{\"art_id\":\"2\",\"stock\":17}\n")
+	_ = encoder.Encode(seat)
+	_ = encoder.Encode(top)
+	invalidRecord := data.RecordError{Record: 2, Message: "json: cannot unmarshal number into Go struct field Stock.stock of type string"}
+	failedRecord := data.RecordError{Record: 4, Message: "duplicate key value violates unique constraint"}
+
+	t.Run("failing_records_are_reported", func(t *testing.T) {
+		job := data.ImportJob{JobId: 1, Kind: data.ImportInventory, State: data.JobRunning, Total: 4, Payload: payload.Bytes(), Worker: "worker"}
+		firstBatch := job
+		firstBatch.Processed, firstBatch.Records, firstBatch.Batches = 3, 2, 1
+		firstBatch.Errors = []data.RecordError{invalidRecord}
+		finished := firstBatch
+		finished.State = data.JobPartiallySucceeded
+		finished.Processed, finished.Batches = 4, 2
+		finished.Errors = []data.RecordError{invalidRecord, failedRecord}
+
+		gomock.InOrder(
+			inventory.EXPECT().UploadInventory(gomock.Any(), data.Inventory{Inventory: []data.Stock{leg, seat}}).Return(nil, 2),
+			inventory.EXPECT().UpdateImportJob(gomock.Any(), firstBatch).Return(nil),
+			inventory.EXPECT().UploadInventory(gomock.Any(), data.Inventory{Inventory: []data.Stock{top}}).Return(errors.New(failedRecord.Message), 0),
+			inventory.EXPECT().Ping().Return(nil),
+			inventory.EXPECT().UploadInventory(gomock.Any(), data.Inventory{Inventory: []data.Stock{top}}).Return(errors.New(failedRecord.Message), 0),
+			inventory.EXPECT().UpdateImportJob(gomock.Any(), finished).Return(nil),
+		)
+
+		err := server.runImportJob(context.Background(), job)
+		assert.Equal(t, err, nil)
+	})
+
+	t.Run("continues_after_the_processed_records", func(t *testing.T) {
+		job := data.ImportJob{JobId: 1, Kind: data.ImportInventory, State: data.JobRunning, Total: 4, Processed: 3, Records: 2, Batches: 1,
+			Errors: []data.RecordError{invalidRecord}, Payload: payload.Bytes(), Worker: "worker"}
+		finished := job
+		finished.State = data.JobPartiallySucceeded
+		finished.Processed, finished.Records, finished.Batches = 4, 3, 2
+
+		gomock.InOrder(
+			inventory.EXPECT().UploadInventory(gomock.Any(), data.Inventory{Inventory: []data.Stock{top}}).Return(nil, 1),
+			inventory.EXPECT().UpdateImportJob(gomock.Any(), finished).Return(nil),
+		)
+
+		err := server.runImportJob(context.Background(), job)
+		assert.Equal(t, err, nil)
+	})
+
+	t.Run("stops_when_the_job_is_lost", func(t *testing.T) {
+		job := data.ImportJob{JobId: 1, Kind: data.ImportInventory, State: data.JobRunning, Total: 4, Payload: payload.Bytes(), Worker: "worker"}
+
+		gomock.InOrder(
+			inventory.EXPECT().UploadInventory(gomock.Any(), data.Inventory{Inventory: []data.Stock{leg, seat}}).Return(nil, 2),
+			inventory.EXPECT().UpdateImportJob(gomock.Any(), gomock.Any()).Return(db.ErrImportJobLost),
+		)
+
+		err := server.runImportJob(context.Background(), job)
+		assert.Equal(t, err, db.ErrImportJobLost)
+	})
+}
+
+func TestServer_runNextImportJob(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := &Server{
+		Inventory: inventory,
+		router:    gin.New(),
+		Config:    Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s", UploadBatchSize: 2, JobStaleAfter: "30ms"},
+		Logger:    logrus.NewEntry(logrus.New()),
+	}
+	payload := []byte("{\"art_id\":\"1\",\"stock\":\"12\"}\n")
+
+	t.Run("renews_the_lease_of_a_long_batch", func(t *testing.T) {
+		job := data.ImportJob{JobId: 1, Kind: data.ImportInventory, State: data.JobRunning, Total: 1, Payload: payload, Worker: "worker", TenantId: "acme"}
+		inventory.EXPECT().ClaimImportJob(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, &job)
+		renewed := make(chan struct{})
+		inventory.EXPECT().RenewImportJob(gomock.Any(), 1, "worker").DoAndReturn(func(ctx context.Context, jobId int, worker string) error {
+			select {
+			case renewed <- struct{}{}:
+			default:
+			}
+			return nil
+		}).MinTimes(1)
+		inventory.EXPECT().UploadInventory(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, inventory data.Inventory) (error, int) {
+			<-renewed
+			return nil, 1
+		})
+		inventory.EXPECT().UpdateImportJob(gomock.Any(), gomock.Any()).Return(nil)
+
+		assert.Equal(t, server.runNextImportJob(context.Background()), true)
+	})
+
+	t.Run("cancels_the_job_when_the_lease_is_lost", func(t *testing.T) {
+		job := data.ImportJob{JobId: 2, Kind: data.ImportInventory, State: data.JobRunning, Total: 1, Payload: payload, Worker: "worker", TenantId: "acme"}
+		inventory.EXPECT().ClaimImportJob(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, &job)
+		inventory.EXPECT().RenewImportJob(gomock.Any(), 2, "worker").Return(db.ErrImportJobLost)
+		inventory.EXPECT().UploadInventory(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, inventory data.Inventory) (error, int) {
+			<-ctx.Done()
+			return ctx.Err(), 0
+		})
+		inventory.EXPECT().Ping().Return(errors.New("context canceled"))
+
+		assert.Equal(t, server.runNextImportJob(context.Background()), true)
+	})
+}
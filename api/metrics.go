@@ -0,0 +1,34 @@
+package api
+
+import (
+	"errors"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/metrics"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+//unmatchedRoute is the route label of the requests that match no route, so that unknown paths do not create new series
+const unmatchedRoute = "unmatched"
+
+//observeRequest records the count and the latency of the request per route and status
+func (server *Server) observeRequest(context *gin.Context) {
+	start := time.Now()
+	context.Next()
+	route := context.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	server.Metrics.ObserveRequest(context.Request.Method, route, context.Writer.Status(), time.Since(start))
+}
+
+//sellFailureReason gives the reason of the failed sell to be counted
+func sellFailureReason(err error) string {
+	switch {
+	case errors.Is(err, db.ErrProductNotInSystem):
+		return metrics.SellFailureUnknownProduct
+	case errors.Is(err, db.ErrProductOutOfStock):
+		return metrics.SellFailureOutOfStock
+	}
+	return metrics.SellFailureError
+}
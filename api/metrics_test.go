@@ -0,0 +1,93 @@
+package api
+
+import (
+	"database/sql"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/metrics"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//poolStats is a fixed connection pool stats source
+type poolStats sql.DBStats
+
+func (stats poolStats) Stats() sql.DBStats {
+	return sql.DBStats(stats)
+}
+
+func TestServer_metrics(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	serviceMetrics := metrics.New()
+	serviceMetrics.RegisterDBStats(poolStats{MaxOpenConnections: 10, OpenConnections: 3, InUse: 1, Idle: 2})
+	serviceMetrics.RegisterLowStock(inventory, 5, time.Second)
+	server := NewServer(inventory, serviceMetrics, nil, Configuration{BackendTimeout: "25s", AuthDisabled: true}, logrus.NewEntry(logrus.New()))
+
+	inventory.EXPECT().SellProduct(gomock.Any(), "Dining Chair").Return(nil)
+	inventory.EXPECT().SellProduct(gomock.Any(), "Dining Table").Return(db.ErrProductOutOfStock)
+	inventory.EXPECT().SellProduct(gomock.Any(), "Sofa").Return(db.ErrProductNotInSystem)
+	inventory.EXPECT().CountLowStockArticles(gomock.Any(), 5).Return(nil, map[string]int{"acme": 1, "default": 2, "globex": 0})
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodPost, path: "/warehouse/v1/product/Dining%20Chair"},
+		{method: http.MethodPost, path: "/warehouse/v1/product/Dining%20Table"},
+		{method: http.MethodPost, path: "/warehouse/v1/product/Sofa"},
+		{method: http.MethodGet, path: "/warehouse/v1/unknown"},
+	}
+	for _, r := range requests {
+		httpRequest, _ := http.NewRequest(r.method, r.path, nil)
+		server.router.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	}
+
+	recorder := httptest.NewRecorder()
+	httpRequest, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	server.router.ServeHTTP(recorder, httpRequest)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	byteArr, _ := ioutil.ReadAll(recorder.Body)
+	exposition := string(byteArr)
+
+	for _, line := range []string{
+		`warehouse_http_requests_total{method="POST",route="/warehouse/v1/product/:product_name",status="200"} 1`,
+		`warehouse_http_requests_total{method="POST",route="/warehouse/v1/product/:product_name",status="400"} 2`,
+		`warehouse_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`warehouse_http_request_duration_seconds_count{method="POST",route="/warehouse/v1/product/:product_name",status="400"} 2`,
+		`warehouse_sells_total 1`,
+		`warehouse_sell_failures_total{reason="out_of_stock"} 1`,
+		`warehouse_sell_failures_total{reason="unknown_product"} 1`,
+		`warehouse_db_open_connections 3`,
+		`warehouse_db_max_open_connections 10`,
+		`warehouse_articles_below_threshold{tenant="acme",threshold="5"} 1`,
+		`warehouse_articles_below_threshold{tenant="default",threshold="5"} 2`,
+		`warehouse_articles_below_threshold{tenant="globex",threshold="5"} 0`,
+	} {
+		assert.Equal(t, strings.Contains(exposition, line+"\n"), true)
+	}
+}
+
+func Test_sellFailureReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		reason string
+	}{
+		{name: "unknown_product", err: db.ErrProductNotInSystem, reason: metrics.SellFailureUnknownProduct},
+		{name: "out_of_stock", err: db.ErrProductOutOfStock, reason: metrics.SellFailureOutOfStock},
+		{name: "other_error", err: sql.ErrConnDone, reason: metrics.SellFailureError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, sellFailureReason(tt.err), tt.reason)
+		})
+	}
+}
@@ -1,6 +1,101 @@
 package api
 
+import "time"
+
 // text constants related to the service endpoints input
 const (
-	productName string = "product_name"
+	productName     string = "product_name"
+	orderID         string = "order_id"
+	orderStatus     string = "status"
+	purchaseOrderID string = "purchase_order_id"
+	artID           string = "art_id"
+	supplierID      string = "supplier_id"
+	barcodeParam    string = "barcode"
+	jobID           string = "job_id"
+	asOfParam       string = "as_of"
+	costMethod      string = "method"
+	fromParam       string = "from"
+	toParam         string = "to"
+	currencyParam   string = "currency"
+	rankBy          string = "by"
+	limitParam      string = "limit"
+	intervalParam   string = "interval"
+	keyID           string = "key_id"
+)
+
+// header names used by the service endpoints
+const (
+	idempotencyKeyHeader    string = "Idempotency-Key"
+	idempotentReplayHeader  string = "Idempotent-Replayed"
+	preferHeader            string = "Prefer"
+	preferenceAppliedHeader string = "Preference-Applied"
+	requestIDHeader         string = "X-Request-ID"
+	apiKeyHeader            string = "X-API-Key"
+	authorizationHeader     string = "Authorization"
+	authenticateHeader      string = "WWW-Authenticate"
+	retryAfterHeader        string = "Retry-After"
+	tenantHeader            string = "X-Tenant-ID"
+)
+
+// bearerScheme is the Authorization scheme of the tokens
+const bearerScheme = "Bearer"
+
+// gin context keys of the request id, of the log fields identifying the client and of the id of the client
+const (
+	ridKey      = "rid"
+	clientKey   = "client"
+	clientIDKey = "client_id"
+	//clientTenantKey is the tenant of the credential of the client, tenantKey the one the request is scoped by
+	clientTenantKey = "client_tenant"
+	tenantKey       = "tenant"
+)
+
+// route groups the rate limits are configured for
+const (
+	rateGroupRead  = "read"
+	rateGroupWrite = "write"
+	rateGroupSell  = "sell"
+	rateGroupAdmin = "admin"
+)
+
+// maxRIDLength limits the request id taken from the client, a longer one is replaced by a new id
+const maxRIDLength = 128
+
+// respondAsync is the Prefer header value asking an upload to run as an import job
+const respondAsync = "respond-async"
+
+// content types of the uploads and downloads besides json
+const (
+	mimeCSV    = "text/csv"
+	mimeNDJSON = "application/x-ndjson"
+)
+
+// top products are limited to defaultTopLimit unless another limit up to maxTopLimit is asked
+const (
+	defaultTopLimit = 10
+	maxTopLimit     = 100
+)
+
+// defaultUploadBatchSize is used when no batch size is configured for the streaming uploads
+const defaultUploadBatchSize = 1000
+
+// defaultIdempotencyRetention is used when the configured retention cannot be parsed
+const defaultIdempotencyRetention = 24 * time.Hour
+
+// defaultIdempotencyStaleAfter is used when the configured stale after duration cannot be parsed
+const defaultIdempotencyStaleAfter = time.Minute
+
+// idempotencyStoreTimeout bounds the release or the save of an idempotency key after the request is handled
+const idempotencyStoreTimeout = 5 * time.Second
+
+// defaultShutdownDelay is used when the configured shutdown delay cannot be parsed
+const defaultShutdownDelay = 5 * time.Second
+
+// defaultBackendTimeout is used when the configured backend timeout cannot be parsed
+const defaultBackendTimeout = 25 * time.Second
+
+// defaults of the import job worker, used when the configured durations cannot be parsed
+const (
+	defaultJobPollInterval = 5 * time.Second
+	defaultJobStaleAfter   = 5 * time.Minute
 )
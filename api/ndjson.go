@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+//uploadStream uploads the records of the body in batches and reports the progress after every batch
+type uploadStream func(ctx context.Context, body io.Reader, progress func(data.UploadProgress)) (data.UploadProgress, error)
+
+//isNDJSON checks if the request body is newline delimited json, one record per line
+func isNDJSON(context *gin.Context) bool {
+	return context.ContentType() == mimeNDJSON
+}
+
+//uploadNDJSON streams the upload and responds with the number of committed records and batches
+func (server *Server) uploadNDJSON(context *gin.Context, stream uploadStream, record string) {
+	log := server.requestLog(context)
+	log.Debug("uploadNDJSON")
+	uploaded, err := stream(context.Request.Context(), context.Request.Body, func(progress data.UploadProgress) {
+		log.WithField("records", progress.Records).WithField("batches", progress.Batches).Info("uploadNDJSON, committed a batch")
+	})
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("%s, %d %s committed in %d batches before the failure", err.Error(), uploaded.Records, record, uploaded.Batches),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Upload:  &uploaded,
+		Message: fmt.Sprintf("%d %s inserted", uploaded.Records, record),
+	})
+	return
+}
+
+//uploadBatchSize gets the configured number of records committed together by a streaming upload
+func (server *Server) uploadBatchSize() int {
+	if server.Config.UploadBatchSize <= 0 {
+		return defaultUploadBatchSize
+	}
+	return server.Config.UploadBatchSize
+}
+
+//streamInventory decodes the articles one by one and uploads them in batches, so only one batch is kept in memory.
+//The batches committed before a failure stay in the inventory
+func (server *Server) streamInventory(ctx context.Context, body io.Reader, progress func(data.UploadProgress)) (data.UploadProgress, error) {
+	batch := make([]data.Stock, 0, server.uploadBatchSize())
+	add := func(decoder *json.Decoder) error {
+		var article data.Stock
+		err := decoder.Decode(&article)
+		if err != nil {
+			return err
+		}
+		err = validateArticleBarcodes(data.Inventory{Inventory: []data.Stock{article}})
+		if err != nil {
+			return err
+		}
+		batch = append(batch, article)
+		return nil
+	}
+	flush := func() (int, error) {
+		err, inserted := server.Inventory.UploadInventory(ctx, data.Inventory{Inventory: batch})
+		batch = batch[:0]
+		return inserted, err
+	}
+	return streamNDJSON(body, server.uploadBatchSize(), add, flush, progress)
+}
+
+//streamProducts decodes the products one by one and uploads them in batches, so only one batch is kept in memory.
+//The batches committed before a failure stay in the system
+func (server *Server) streamProducts(ctx context.Context, body io.Reader, progress func(data.UploadProgress)) (data.UploadProgress, error) {
+	batch := make([]data.Product, 0, server.uploadBatchSize())
+	add := func(decoder *json.Decoder) error {
+		var product data.Product
+		err := decoder.Decode(&product)
+		if err != nil {
+			return err
+		}
+		err = validateProductBarcodes(data.Products{Products: []data.Product{product}})
+		if err != nil {
+			return err
+		}
+		batch = append(batch, product)
+		return nil
+	}
+	flush := func() (int, error) {
+		err, inserted := server.Inventory.UploadProducts(ctx, data.Products{Products: batch})
+		batch = batch[:0]
+		return inserted, err
+	}
+	return streamNDJSON(body, server.uploadBatchSize(), add, flush, progress)
+}
+
+//streamNDJSON calls add for every record and flush for every batchSize records and at the end of the body.
+//progress is called after every committed batch
+func streamNDJSON(body io.Reader, batchSize int, add func(*json.Decoder) error, flush func() (int, error),
+	progress func(data.UploadProgress)) (data.UploadProgress, error) {
+	var uploaded data.UploadProgress
+	decoder := json.NewDecoder(body)
+	pending := 0
+	commit := func() error {
+		inserted, err := flush()
+		if err != nil {
+			return fmt.Errorf("batch %d failed: %w", uploaded.Batches+1, err)
+		}
+		pending = 0
+		uploaded.Records += inserted
+		uploaded.Batches++
+		if progress != nil {
+			progress(uploaded)
+		}
+		return nil
+	}
+
+	for record := 1; ; record++ {
+		err := add(decoder)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return uploaded, fmt.Errorf("record %d: %w", record, err)
+		}
+		pending++
+		if pending == batchSize {
+			err = commit()
+			if err != nil {
+				return uploaded, err
+			}
+		}
+	}
+	if pending > 0 {
+		err := commit()
+		if err != nil {
+			return uploaded, err
+		}
+	}
+	return uploaded, nil
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer_uploadInventoryNDJSON(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		body       string
+		batches    []data.Inventory
+		dbErr      error
+		statusCode int
+		message    string
+	}{
+		{
+			name:   "committed_in_batches",
+			fields: fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s", UploadBatchSize: 2}},
+			body: `{"art_id":"1","name":"leg","stock":"12"}` + "\n" + `{"art_id":"2","name":"screw","stock":"17"}` + "\n\n" +
+				`{"art_id":"3","name":"seat","stock":"2"}` + "\n",
+			batches: []data.Inventory{
+				{Inventory: []data.Stock{{ArtId: "1", Name: "leg", Stock: "12"}, {ArtId: "2", Name: "screw", Stock: "17"}}},
+				{Inventory: []data.Stock{{ArtId: "3", Name: "seat", Stock: "2"}}},
+			},
+			statusCode: http.StatusOK,
+			message:    "3 item inserted",
+		},
+		{
+			name:   "invalid_record_after_a_batch",
+			fields: fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s", UploadBatchSize: 2}},
+			body: `{"art_id":"1","name":"leg","stock":"12"}` + "\n" + `{"art_id":"2","name":"screw","stock":"17"}` + "\n" +
+				`{"art_id":"3","name":"seat","stock":2}` + "\n",
+			batches: []data.Inventory{
+				{Inventory: []data.Stock{{ArtId: "1", Name: "leg", Stock: "12"}, {ArtId: "2", Name: "screw", Stock: "17"}}},
+			},
+			statusCode: http.StatusBadRequest,
+			message: "record 3: json: cannot unmarshal number into Go struct field Stock.stock of type string, " +
+				"2 item committed in 1 batches before the failure",
+		},
+		{
+			name:       "failed_batch",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:       `{"art_id":"1","name":"leg","stock":"12"}` + "\n",
+			batches:    []data.Inventory{{Inventory: []data.Stock{{ArtId: "1", Name: "leg", Stock: "12"}}}},
+			dbErr:      errors.New("duplicate key value violates unique constraint"),
+			statusCode: http.StatusBadRequest,
+			message:    "batch 1 failed: duplicate key value violates unique constraint, 0 item committed in 0 batches before the failure",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+			context.Request = &http.Request{
+				Header: http.Header{"Content-Type": []string{"application/x-ndjson"}},
+				Body:   ioutil.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			var calls []*gomock.Call
+			for i, batch := range tt.batches {
+				dbErr := error(nil)
+				if i == len(tt.batches)-1 {
+					dbErr = tt.dbErr
+				}
+				calls = append(calls, inventory.EXPECT().UploadInventory(gomock.Any(), batch).Return(dbErr, len(batch.Inventory)))
+			}
+			gomock.InOrder(calls...)
+
+			server.uploadInventory(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+			if tt.statusCode == http.StatusOK {
+				assert.Equal(t, *response.Upload, data.UploadProgress{Records: 3, Batches: 2})
+			}
+		})
+	}
+}
+
+func TestServer_uploadProductsNDJSON(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+	server := &Server{
+		Inventory: inventory,
+		router:    gin.New(),
+		Config:    Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"},
+		Logger:    logrus.NewEntry(logrus.New()),
+	}
+	context.Request = &http.Request{
+		Header: http.Header{"Content-Type": []string{"application/x-ndjson"}},
+		Body: ioutil.NopCloser(strings.NewReader(`{"name":"Dining Chair","contain_articles":[{"art_id":"1","amount_of":"4"}]}` + "\n" +
+			`{"name":"Dining Table","barcodes":["5901234123458"],"contain_articles":[{"art_id":"1","amount_of":"4"}]}` + "\n")),
+	}
+
+	server.uploadProducts(context)
+
+	assert.Equal(t, http.StatusBadRequest, context.Writer.Status())
+	var response ResponseProduct
+	byteArr, _ := ioutil.ReadAll(recorder.Body)
+	_ = json.Unmarshal(byteArr, &response)
+	assert.Equal(t, response.Message, "record 2: barcode 5901234123458 of product Dining Table is not a valid GTIN, "+
+		"0 product committed in 0 batches before the failure")
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+//createOrder creates an order and allocates the stock of its products
+func (server *Server) createOrder(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("createOrder")
+	var order data.Order
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err = json.Unmarshal(jsonData, &order)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if len(order.Lines) == 0 {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "order has no lines",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	for _, line := range order.Lines {
+		if line.ProductName == "" || line.Quantity <= 0 {
+			respondError(context, http.StatusBadRequest, ResponseError{
+				Message: "product_name and a positive quantity are required for every line",
+				RID:     request.GetRID(context.Request.Context()),
+			})
+			return
+		}
+	}
+
+	err, order = server.Inventory.CreateOrder(context.Request.Context(), order)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	message := fmt.Sprintf("Order %d is created and allocated", order.OrderId)
+	if order.Status == data.OrderCreated {
+		message = fmt.Sprintf("Order %d is created but not allocated, not enough articles in stock", order.OrderId)
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Order:   &order,
+		Message: message,
+	})
+	return
+}
+
+//getOrders provides the orders, filtered by the status query parameter if given
+func (server *Server) getOrders(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getOrders")
+	status := data.OrderStatus(context.Query(orderStatus))
+	if status != "" && !status.IsValid() {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("unknown order status %s", status),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, orders := server.Inventory.GetOrders(context.Request.Context(), status)
+	if err != nil {
+		respondError(context, http.StatusNotFound, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if len(orders) == 0 {
+		context.JSON(http.StatusOK, ResponseProduct{
+			Message: "No order found",
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Orders: orders,
+	})
+	return
+}
+
+//getOrder provides a single order
+func (server *Server) getOrder(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getOrder")
+	orderId, err := strconv.Atoi(context.Param(orderID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "order id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, order := server.Inventory.GetOrder(context.Request.Context(), orderId)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusNotFound), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Order: &order,
+	})
+	return
+}
+
+//updateOrderStatus moves the order to the requested status
+func (server *Server) updateOrderStatus(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("updateOrderStatus")
+	orderId, err := strconv.Atoi(context.Param(orderID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "order id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	var change data.OrderStatusChange
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err = json.Unmarshal(jsonData, &change)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if !change.Status.IsValid() {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("unknown order status %s", change.Status),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, order := server.Inventory.UpdateOrderStatus(context.Request.Context(), orderId, change.Status)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	message := fmt.Sprintf("Order %d is %s", order.OrderId, order.Status)
+	context.JSON(http.StatusOK, ResponseProduct{
+		Order:   &order,
+		Message: message,
+	})
+	return
+}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestServer_createOrder(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	type args struct {
+		context *gin.Context
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		order      data.Order
+		callDB     bool
+		dbErr      error
+		dbStatus   data.OrderStatus
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "order_allocated",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			order:      data.Order{Lines: []data.OrderLine{{ProductName: "product_test", Quantity: 1}}},
+			callDB:     true,
+			dbStatus:   data.OrderAllocated,
+			statusCode: http.StatusOK,
+			message:    "Order 1 is created and allocated",
+		},
+		{
+			name:       "order_not_allocated",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			order:      data.Order{Lines: []data.OrderLine{{ProductName: "product_test", Quantity: 100}}},
+			callDB:     true,
+			dbStatus:   data.OrderCreated,
+			statusCode: http.StatusOK,
+			message:    "Order 1 is created but not allocated, not enough articles in stock",
+		},
+		{
+			name:       "order_failed",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			order:      data.Order{Lines: []data.OrderLine{{ProductName: "product_test", Quantity: 1}}},
+			callDB:     true,
+			dbErr:      fmt.Errorf("%w: product_test", db.ErrOrderedProductNotInSystem),
+			statusCode: http.StatusBadRequest,
+			message:    "ordered product is not in system: product_test",
+		},
+		{
+			name:       "order_query_failed",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			order:      data.Order{Lines: []data.OrderLine{{ProductName: "product_test", Quantity: 1}}},
+			callDB:     true,
+			dbErr:      errors.New("pq: could not serialize access due to concurrent update"),
+			statusCode: http.StatusInternalServerError,
+			message:    "pq: could not serialize access due to concurrent update",
+		},
+		{
+			name:       "order_without_lines",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			order:      data.Order{},
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "order has no lines",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Body.Reset()
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			reqBodyBytes := new(bytes.Buffer)
+			json.NewEncoder(reqBodyBytes).Encode(tt.order)
+			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBuffer(reqBodyBytes.Bytes()))}
+
+			if tt.callDB {
+				created := tt.order
+				created.OrderId = 1
+				created.Status = tt.dbStatus
+				inventory.EXPECT().CreateOrder(gomock.Any(), gomock.Any()).Return(tt.dbErr, created)
+			}
+
+			server.createOrder(tt.args.context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+		})
+	}
+}
+
+func TestServer_getOrders(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		status         string
+		callDB         bool
+		expectedOrders []data.Order
+		statusCode     int
+	}{
+		{
+			name:           "orders_by_status",
+			fields:         fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			status:         "picked",
+			callDB:         true,
+			expectedOrders: []data.Order{{OrderId: 1, Status: data.OrderPicked, Lines: []data.OrderLine{{ProductName: "product_test", Quantity: 1}}}},
+			statusCode:     http.StatusOK,
+		},
+		{
+			name:       "unknown_status",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			status:     "lost",
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+			context.Request = &http.Request{URL: &url.URL{RawQuery: orderStatus + "=" + tt.status}}
+
+			if tt.callDB {
+				inventory.EXPECT().GetOrders(gomock.Any(), data.OrderStatus(tt.status)).Return(nil, tt.expectedOrders)
+			}
+
+			server.getOrders(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			if tt.callDB {
+				var response ResponseProduct
+				byteArr, _ := ioutil.ReadAll(recorder.Body)
+				_ = json.Unmarshal(byteArr, &response)
+				assert.Equal(t, len(response.Orders), len(tt.expectedOrders))
+				assert.Equal(t, response.Orders[0].Status, tt.expectedOrders[0].Status)
+			}
+		})
+	}
+}
+
+func TestServer_updateOrderStatus(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+	context.Params = []gin.Param{
+		{
+			Key:   orderID,
+			Value: "1",
+		},
+	}
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	type args struct {
+		context *gin.Context
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		status     data.OrderStatus
+		callDB     bool
+		dbErr      error
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "order_shipped",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			status:     data.OrderShipped,
+			callDB:     true,
+			statusCode: http.StatusOK,
+			message:    "Order 1 is shipped",
+		},
+		{
+			name:       "invalid_transition",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			status:     data.OrderPicked,
+			callDB:     true,
+			dbErr:      fmt.Errorf("%w: shipped to picked", db.ErrInvalidOrderTransition),
+			statusCode: http.StatusConflict,
+			message:    "order cannot move to the requested status: shipped to picked",
+		},
+		{
+			name:       "order_not_found",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			status:     data.OrderCancelled,
+			callDB:     true,
+			dbErr:      db.ErrOrderNotFound,
+			statusCode: http.StatusNotFound,
+			message:    "order is not found",
+		},
+		{
+			name:       "unknown_status",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			status:     "lost",
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "unknown order status lost",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Body.Reset()
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			reqBodyBytes := new(bytes.Buffer)
+			json.NewEncoder(reqBodyBytes).Encode(data.OrderStatusChange{Status: tt.status})
+			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBuffer(reqBodyBytes.Bytes()))}
+
+			if tt.callDB {
+				inventory.EXPECT().UpdateOrderStatus(gomock.Any(), 1, tt.status).Return(tt.dbErr, data.Order{OrderId: 1, Status: tt.status})
+			}
+
+			server.updateOrderStatus(tt.args.context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+//createPurchaseOrder creates a purchase order of the expected articles
+func (server *Server) createPurchaseOrder(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("createPurchaseOrder")
+	var purchaseOrder data.PurchaseOrder
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err = json.Unmarshal(jsonData, &purchaseOrder)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if len(purchaseOrder.Lines) == 0 {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "purchase order has no lines",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	for _, line := range purchaseOrder.Lines {
+		if line.ArtId == "" || line.Expected <= 0 {
+			respondError(context, http.StatusBadRequest, ResponseError{
+				Message: "art_id and a positive expected quantity are required for every line",
+				RID:     request.GetRID(context.Request.Context()),
+			})
+			return
+		}
+	}
+
+	err, purchaseOrder = server.Inventory.CreatePurchaseOrder(context.Request.Context(), purchaseOrder)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	message := fmt.Sprintf("Purchase order %d is created", purchaseOrder.PurchaseOrderId)
+	context.JSON(http.StatusOK, ResponseProduct{
+		PurchaseOrder: &purchaseOrder,
+		Message:       message,
+	})
+	return
+}
+
+//getPurchaseOrders provides the purchase orders, filtered by the status query parameter if given
+func (server *Server) getPurchaseOrders(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getPurchaseOrders")
+	status := data.PurchaseOrderStatus(context.Query(orderStatus))
+	if status != "" && !status.IsValid() {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("unknown purchase order status %s", status),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, purchaseOrders := server.Inventory.GetPurchaseOrders(context.Request.Context(), status)
+	if err != nil {
+		respondError(context, http.StatusNotFound, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if len(purchaseOrders) == 0 {
+		context.JSON(http.StatusOK, ResponseProduct{
+			Message: "No purchase order found",
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		PurchaseOrders: purchaseOrders,
+	})
+	return
+}
+
+//getPurchaseOrder provides a purchase order with its delivery report
+func (server *Server) getPurchaseOrder(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getPurchaseOrder")
+	purchaseOrderId, err := strconv.Atoi(context.Param(purchaseOrderID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "purchase order id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, purchaseOrder := server.Inventory.GetPurchaseOrder(context.Request.Context(), purchaseOrderId)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusNotFound), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		PurchaseOrder: &purchaseOrder,
+		Message:       deliveryReport(purchaseOrder),
+	})
+	return
+}
+
+//receiveGoods records a full or partial delivery of a purchase order
+func (server *Server) receiveGoods(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("receiveGoods")
+	purchaseOrderId, err := strconv.Atoi(context.Param(purchaseOrderID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "purchase order id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	var receipt data.GoodsReceipt
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err = json.Unmarshal(jsonData, &receipt)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if len(receipt.Lines) == 0 {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "receipt has no lines",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	for _, line := range receipt.Lines {
+		if line.ArtId == "" || line.Quantity <= 0 {
+			respondError(context, http.StatusBadRequest, ResponseError{
+				Message: "art_id and a positive quantity are required for every line",
+				RID:     request.GetRID(context.Request.Context()),
+			})
+			return
+		}
+		if line.UnitCost < 0 {
+			respondError(context, http.StatusBadRequest, ResponseError{
+				Message: "unit_cost cannot be negative",
+				RID:     request.GetRID(context.Request.Context()),
+			})
+			return
+		}
+	}
+	receipt.PurchaseOrderId = purchaseOrderId
+
+	err, purchaseOrder := server.Inventory.ReceiveGoods(context.Request.Context(), receipt)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		PurchaseOrder: &purchaseOrder,
+		Message:       deliveryReport(purchaseOrder),
+	})
+	return
+}
+
+//closePurchaseOrder closes the purchase order, the outstanding quantities are reported as under delivery
+func (server *Server) closePurchaseOrder(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("closePurchaseOrder")
+	purchaseOrderId, err := strconv.Atoi(context.Param(purchaseOrderID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "purchase order id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, purchaseOrder := server.Inventory.ClosePurchaseOrder(context.Request.Context(), purchaseOrderId)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		PurchaseOrder: &purchaseOrder,
+		Message:       deliveryReport(purchaseOrder),
+	})
+	return
+}
+
+//deliveryReport summarizes the under and over delivered articles of the purchase order
+func deliveryReport(purchaseOrder data.PurchaseOrder) string {
+	underDelivered, overDelivered := 0, 0
+	for _, line := range purchaseOrder.Lines {
+		if line.Outstanding > 0 {
+			underDelivered++
+		}
+		if line.OverDelivered > 0 {
+			overDelivered++
+		}
+	}
+	return fmt.Sprintf("Purchase order %d is %s, %d article under delivered, %d article over delivered",
+		purchaseOrder.PurchaseOrderId, purchaseOrder.Status, underDelivered, overDelivered)
+}
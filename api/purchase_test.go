@@ -0,0 +1,183 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServer_createPurchaseOrder(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	type args struct {
+		context *gin.Context
+	}
+	tests := []struct {
+		name          string
+		fields        fields
+		args          args
+		purchaseOrder data.PurchaseOrder
+		callDB        bool
+		statusCode    int
+		message       string
+	}{
+		{
+			name:          "purchase_order_created",
+			fields:        fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:          args{context: context},
+			purchaseOrder: data.PurchaseOrder{Lines: []data.PurchaseOrderLine{{ArtId: "1", Expected: 10}}},
+			callDB:        true,
+			statusCode:    http.StatusOK,
+			message:       "Purchase order 1 is created",
+		},
+		{
+			name:          "missing_expected_quantity",
+			fields:        fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:          args{context: context},
+			purchaseOrder: data.PurchaseOrder{Lines: []data.PurchaseOrderLine{{ArtId: "1"}}},
+			callDB:        false,
+			statusCode:    http.StatusBadRequest,
+			message:       "art_id and a positive expected quantity are required for every line",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Body.Reset()
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			reqBodyBytes := new(bytes.Buffer)
+			json.NewEncoder(reqBodyBytes).Encode(tt.purchaseOrder)
+			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBuffer(reqBodyBytes.Bytes()))}
+
+			if tt.callDB {
+				created := tt.purchaseOrder
+				created.PurchaseOrderId = 1
+				created.Status = data.PurchaseOrderOpen
+				inventory.EXPECT().CreatePurchaseOrder(gomock.Any(), tt.purchaseOrder).Return(nil, created)
+			}
+
+			server.createPurchaseOrder(tt.args.context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+		})
+	}
+}
+
+func TestServer_receiveGoods(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+	context.Params = []gin.Param{
+		{
+			Key:   purchaseOrderID,
+			Value: "1",
+		},
+	}
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	type args struct {
+		context *gin.Context
+	}
+	tests := []struct {
+		name          string
+		fields        fields
+		args          args
+		receipt       data.GoodsReceipt
+		dbErr         error
+		purchaseOrder data.PurchaseOrder
+		statusCode    int
+		message       string
+	}{
+		{
+			name:    "partially_received",
+			fields:  fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:    args{context: context},
+			receipt: data.GoodsReceipt{Lines: []data.ReceiptLine{{ArtId: "1", Quantity: 12}, {ArtId: "2", Quantity: 3}}},
+			purchaseOrder: data.PurchaseOrder{PurchaseOrderId: 1, Status: data.PurchaseOrderPartiallyReceived, Lines: []data.PurchaseOrderLine{
+				{ArtId: "1", Expected: 10, Received: 12, OverDelivered: 2},
+				{ArtId: "2", Expected: 5, Received: 3, Outstanding: 2},
+			}},
+			statusCode: http.StatusOK,
+			message:    "Purchase order 1 is partially_received, 1 article under delivered, 1 article over delivered",
+		},
+		{
+			name:       "purchase_order_closed",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			receipt:    data.GoodsReceipt{Lines: []data.ReceiptLine{{ArtId: "1", Quantity: 1}}},
+			dbErr:      db.ErrPurchaseOrderClosed,
+			statusCode: http.StatusConflict,
+			message:    "purchase order is closed",
+		},
+		{
+			name:       "purchase_order_not_found",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			receipt:    data.GoodsReceipt{Lines: []data.ReceiptLine{{ArtId: "1", Quantity: 1}}},
+			dbErr:      db.ErrPurchaseOrderNotFound,
+			statusCode: http.StatusNotFound,
+			message:    "purchase order is not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Body.Reset()
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			reqBodyBytes := new(bytes.Buffer)
+			json.NewEncoder(reqBodyBytes).Encode(tt.receipt)
+			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBuffer(reqBodyBytes.Bytes()))}
+
+			expectedReceipt := tt.receipt
+			expectedReceipt.PurchaseOrderId = 1
+			inventory.EXPECT().ReceiveGoods(gomock.Any(), expectedReceipt).Return(tt.dbErr, tt.purchaseOrder)
+
+			server.receiveGoods(tt.args.context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//rateLimitStorePostgres shares the rate limit buckets of the instances in the database
+const rateLimitStorePostgres = "postgres"
+
+//rateLimitGroupAuth is the group of the per IP limit taken before the authentication of every guarded request
+const rateLimitGroupAuth = "auth"
+
+//rateLimiter keeps the token buckets of the rate limits
+type rateLimiter interface {
+	TakeRateLimitToken(ctx context.Context, key string, limit data.RateLimit) (error, data.RateDecision)
+}
+
+//rateLimit takes a token from the bucket of the client for the route group and responds 429 when there is none.
+//The client is its API key or token subject in its tenant, or its IP when it is not authenticated. A failing limiter
+//lets the request through
+func (server *Server) rateLimit(group string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		clientID := context.GetString(clientIDKey)
+		if clientID == "" {
+			clientID = "ip:" + context.ClientIP()
+		}
+		server.takeRateLimitToken(context, group, clientID)
+	}
+}
+
+//rateLimitIP takes a token from the bucket of the IP of the request for the group before the request is
+//authenticated, so the requests with missing or invalid credentials are limited before they reach the database
+func (server *Server) rateLimitIP(group string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		server.takeRateLimitToken(context, group, "ip:"+context.ClientIP())
+	}
+}
+
+//takeRateLimitToken takes a token from the bucket of the client for the group and aborts with 429 when there is none
+func (server *Server) takeRateLimitToken(context *gin.Context, group, clientID string) {
+	limit, ok := server.rateLimits[group]
+	if !ok || server.limiter == nil {
+		return
+	}
+
+	err, decision := server.limiter.TakeRateLimitToken(context.Request.Context(), group+"|"+request.GetTenant(context.Request.Context())+"|"+clientID, limit)
+	if err != nil {
+		server.requestLog(context).WithField("err", err).Error("rateLimit, limiter failed, request is let through")
+		return
+	}
+	if !decision.Allowed {
+		retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		server.requestLog(context).WithField("group", group).Info("rateLimit, rate limit is exceeded")
+		context.Abort()
+		context.Header(retryAfterHeader, strconv.Itoa(retryAfter))
+		respondError(context, http.StatusTooManyRequests, ResponseError{
+			Message: fmt.Sprintf("rate limit of the %s endpoints is exceeded, retry after %s", group, time.Duration(retryAfter)*time.Second),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServer_rateLimit(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := NewServer(inventory, nil, nil, Configuration{
+		BackendTimeout: "25s",
+		AuthDisabled:   true,
+		RateLimits:     "sell=2/m",
+	}, logrus.NewEntry(logrus.New()))
+
+	sell := func(remoteAddr string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		httpRequest, _ := http.NewRequest(http.MethodPost, "/warehouse/v1/product/Dining%20Chair", nil)
+		httpRequest.RemoteAddr = remoteAddr
+		server.router.ServeHTTP(recorder, httpRequest)
+		return recorder
+	}
+
+	inventory.EXPECT().SellProduct(gomock.Any(), "Dining Chair").Return(nil).Times(3)
+	assert.Equal(t, sell("192.0.2.1:40000").Code, http.StatusOK)
+	assert.Equal(t, sell("192.0.2.1:40001").Code, http.StatusOK)
+
+	recorder := sell("192.0.2.1:40002")
+	assert.Equal(t, recorder.Code, http.StatusTooManyRequests)
+	assert.Equal(t, recorder.Header().Get(retryAfterHeader), "30")
+	var response ResponseError
+	_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.Equal(t, response.Message, "rate limit of the sell endpoints is exceeded, retry after 30s")
+	assert.Equal(t, response.RID, recorder.Header().Get(requestIDHeader))
+
+	//another client and the groups without a limit are not affected
+	assert.Equal(t, sell("192.0.2.2:40000").Code, http.StatusOK)
+	inventory.EXPECT().GetInventory(gomock.Any()).Return(nil, []data.Stock{})
+	recorder = httptest.NewRecorder()
+	httpRequest, _ := http.NewRequest(http.MethodGet, "/warehouse/v1/inventory", nil)
+	httpRequest.RemoteAddr = "192.0.2.1:40003"
+	server.router.ServeHTTP(recorder, httpRequest)
+	assert.Equal(t, recorder.Code, http.StatusOK)
+}
+
+func TestServer_rateLimitShared(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := NewServer(inventory, nil, nil, Configuration{
+		BackendTimeout: "25s",
+		RateLimits:     "sell=5/s:10",
+		RateLimitStore: rateLimitStorePostgres,
+	}, logrus.NewEntry(logrus.New()))
+
+	tests := []struct {
+		name       string
+		limitErr   error
+		decision   data.RateDecision
+		statusCode int
+	}{
+		{
+			name:       "allowed",
+			decision:   data.RateDecision{Allowed: true, Remaining: 9},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "limited",
+			decision:   data.RateDecision{RetryAfter: 150 * time.Millisecond},
+			statusCode: http.StatusTooManyRequests,
+		},
+		{
+			name:       "limiter_failed",
+			limitErr:   errors.New("connection refused"),
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inventory.EXPECT().GetActiveAPIKey(gomock.Any(), gomock.Any()).
+				Return(nil, data.APIKey{KeyId: 7, Name: "till", Scopes: []data.Scope{data.ScopeProductSell}})
+			inventory.EXPECT().TakeRateLimitToken(gomock.Any(), "sell|default|api_key:7", data.RateLimit{Rate: 5, Burst: 10}).
+				Return(tt.limitErr, tt.decision)
+			if tt.statusCode == http.StatusOK {
+				inventory.EXPECT().SellProduct(gomock.Any(), "Dining Chair").Return(nil)
+			}
+
+			recorder := httptest.NewRecorder()
+			httpRequest, _ := http.NewRequest(http.MethodPost, "/warehouse/v1/product/Dining%20Chair", nil)
+			httpRequest.Header.Set(apiKeyHeader, "wh_till")
+			server.router.ServeHTTP(recorder, httpRequest)
+
+			assert.Equal(t, recorder.Code, tt.statusCode)
+			if tt.statusCode == http.StatusTooManyRequests {
+				assert.Equal(t, recorder.Header().Get(retryAfterHeader), "1")
+			}
+		})
+	}
+}
+
+func TestServer_rateLimitAuth(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := NewServer(inventory, nil, nil, Configuration{
+		BackendTimeout: "25s",
+		RateLimits:     "auth=2/m",
+	}, logrus.NewEntry(logrus.New()))
+
+	sell := func(remoteAddr string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		httpRequest, _ := http.NewRequest(http.MethodPost, "/warehouse/v1/product/Dining%20Chair", nil)
+		httpRequest.Header.Set(apiKeyHeader, "wh_invalid")
+		httpRequest.RemoteAddr = remoteAddr
+		server.router.ServeHTTP(recorder, httpRequest)
+		return recorder
+	}
+
+	//the invalid credentials are limited before they are looked up
+	inventory.EXPECT().GetActiveAPIKey(gomock.Any(), gomock.Any()).Return(db.ErrAPIKeyNotFound, data.APIKey{}).Times(3)
+	assert.Equal(t, sell("192.0.2.1:40000").Code, http.StatusUnauthorized)
+	assert.Equal(t, sell("192.0.2.1:40001").Code, http.StatusUnauthorized)
+	recorder := sell("192.0.2.1:40002")
+	assert.Equal(t, recorder.Code, http.StatusTooManyRequests)
+	var response ResponseError
+	_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.Equal(t, response.Message, "rate limit of the auth endpoints is exceeded, retry after 30s")
+
+	assert.Equal(t, sell("192.0.2.2:40000").Code, http.StatusUnauthorized)
+}
@@ -7,13 +7,36 @@ type ResponseError struct {
 	StatusCode int    `json:"code,omitempty"` //in case new error codes need to be designed
 	Message    string `json:"message,omitempty"`
 	Error      string `json:"errors,omitempty"`
+	//RID is the request id, the same as the X-Request-ID response header
+	RID string `json:"rid,omitempty"`
 }
 
 // ResponseData is the holder for the actual data in an API response
 type ResponseProduct struct {
-	StatusCode    int                `json:"code,omitempty"` //in case new error codes need to be designed
-	Products      []data.Product     `json:"products,omitempty"`
-	Inventory     []data.Stock       `json:"inventory,omitempty"`
-	ProductStocks data.ProductStocks `json:"product_stocks,omitempty"`
-	Message       string             `json:"message,omitempty"`
+	StatusCode      int                   `json:"code,omitempty"` //in case new error codes need to be designed
+	Products        []data.Product        `json:"products,omitempty"`
+	Inventory       []data.Stock          `json:"inventory,omitempty"`
+	ProductStocks   data.ProductStocks    `json:"product_stocks,omitempty"`
+	Return          *data.ProductReturn   `json:"return,omitempty"`
+	Orders          []data.Order          `json:"orders,omitempty"`
+	Order           *data.Order           `json:"order,omitempty"`
+	PurchaseOrders  []data.PurchaseOrder  `json:"purchase_orders,omitempty"`
+	PurchaseOrder   *data.PurchaseOrder   `json:"purchase_order,omitempty"`
+	Article         *data.Stock           `json:"article,omitempty"`
+	Suppliers       []data.Supplier       `json:"suppliers,omitempty"`
+	Supplier        *data.Supplier        `json:"supplier,omitempty"`
+	SupplierArticle *data.SupplierArticle `json:"supplier_article,omitempty"`
+	Scan            *data.ScanResult      `json:"scan,omitempty"`
+	Upload          *data.UploadProgress  `json:"upload,omitempty"`
+	Job             *data.ImportJob       `json:"job,omitempty"`
+	Valuation       *data.Valuation       `json:"valuation,omitempty"`
+	Price           *data.Price           `json:"price,omitempty"`
+	Sales           []data.Sale           `json:"sales,omitempty"`
+	Revenue         *data.SalesRevenue    `json:"revenue,omitempty"`
+	TopProducts     []data.TopProduct     `json:"top_products,omitempty"`
+	Series          []data.ProductSeries  `json:"series,omitempty"`
+	SellThrough     []data.SellThrough    `json:"sell_through,omitempty"`
+	APIKey          *data.APIKey          `json:"api_key,omitempty"`
+	APIKeys         []data.APIKey         `json:"api_keys,omitempty"`
+	Message         string                `json:"message,omitempty"`
 }
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+)
+
+//returnProduct handles the return of sold products and restocks their articles
+func (server *Server) returnProduct(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("returnProduct")
+	var productReturn data.ProductReturn
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err = json.Unmarshal(jsonData, &productReturn)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if productReturn.ProductName == "" || productReturn.Quantity <= 0 {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "product_name and a positive quantity are required",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, productReturn = server.Inventory.ReturnProduct(context.Request.Context(), productReturn)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	message := fmt.Sprintf("%d %s returned and inventory is updated accordingly", productReturn.Quantity, productReturn.ProductName)
+	context.JSON(http.StatusOK, ResponseProduct{
+		Return:  &productReturn,
+		Message: message,
+	})
+	return
+}
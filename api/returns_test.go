@@ -0,0 +1,163 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServer_returnProduct(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	type args struct {
+		context *gin.Context
+	}
+	tests := []struct {
+		name          string
+		fields        fields
+		args          args
+		productReturn data.ProductReturn
+		callDB        bool
+		dbErr         error
+		wantFail      bool
+		statusCode    int
+		message       string
+	}{
+		{
+			name:          "product_returned",
+			fields:        fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:          args{context: context},
+			productReturn: data.ProductReturn{ProductName: "product_test", Quantity: 2, SaleId: 7, DamagedArticles: []data.DamagedArticle{{ArtId: "1", Amount: 1}}},
+			callDB:        true,
+			wantFail:      false,
+			statusCode:    http.StatusOK,
+			message:       "2 product_test returned and inventory is updated accordingly",
+		},
+		{
+			name:          "return_failed",
+			fields:        fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:          args{context: context},
+			productReturn: data.ProductReturn{ProductName: "product_test", Quantity: 1, SaleId: 7},
+			callDB:        true,
+			dbErr:         db.ErrProductNotReturnable,
+			wantFail:      true,
+			statusCode:    http.StatusBadRequest,
+			message:       "this product is not in system, cannot be returned",
+		},
+		{
+			name:          "return_query_failed",
+			fields:        fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:          args{context: context},
+			productReturn: data.ProductReturn{ProductName: "product_test", Quantity: 1},
+			callDB:        true,
+			wantFail:      true,
+			statusCode:    http.StatusInternalServerError,
+			message:       "pq: could not serialize access due to concurrent update",
+		},
+		{
+			name:          "sale_not_found",
+			fields:        fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:          args{context: context},
+			productReturn: data.ProductReturn{ProductName: "product_test", Quantity: 1, SaleId: 8},
+			callDB:        true,
+			dbErr:         db.ErrSaleNotFound,
+			wantFail:      true,
+			statusCode:    http.StatusNotFound,
+			message:       db.ErrSaleNotFound.Error(),
+		},
+		{
+			name:          "return_exceeds_sale",
+			fields:        fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:          args{context: context},
+			productReturn: data.ProductReturn{ProductName: "product_test", Quantity: 2, SaleId: 7},
+			callDB:        true,
+			dbErr:         db.ErrReturnExceedsSale,
+			wantFail:      true,
+			statusCode:    http.StatusConflict,
+			message:       db.ErrReturnExceedsSale.Error(),
+		},
+		{
+			name:          "returned_without_sale",
+			fields:        fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:          args{context: context},
+			productReturn: data.ProductReturn{ProductName: "product_test", Quantity: 1},
+			callDB:        true,
+			wantFail:      false,
+			statusCode:    http.StatusOK,
+			message:       "1 product_test returned and inventory is updated accordingly",
+		},
+		{
+			name:          "missing_quantity",
+			fields:        fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:          args{context: context},
+			productReturn: data.ProductReturn{ProductName: "product_test"},
+			callDB:        false,
+			wantFail:      true,
+			statusCode:    http.StatusBadRequest,
+			message:       "product_name and a positive quantity are required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Body.Reset()
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			reqBodyBytes := new(bytes.Buffer)
+			json.NewEncoder(reqBodyBytes).Encode(tt.productReturn)
+			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBuffer(reqBodyBytes.Bytes()))}
+
+			if tt.callDB && tt.wantFail {
+				err := tt.dbErr
+				if err == nil {
+					err = errors.New(tt.message)
+				}
+				inventory.EXPECT().ReturnProduct(gomock.Any(), gomock.Any()).Return(err, tt.productReturn)
+			} else if tt.callDB {
+				returned := tt.productReturn
+				returned.ReturnId = 1
+				inventory.EXPECT().ReturnProduct(gomock.Any(), tt.productReturn).Return(nil, returned)
+			}
+
+			server.returnProduct(tt.args.context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			var responseErr ResponseError
+			if !tt.wantFail {
+				byteArr, _ := ioutil.ReadAll(recorder.Body)
+				_ = json.Unmarshal(byteArr, &response)
+				assert.Equal(t, response.Message, tt.message)
+				assert.Equal(t, response.Return.ReturnId, 1)
+			} else {
+				byteArr, _ := ioutil.ReadAll(recorder.Body)
+				_ = json.Unmarshal(byteArr, &responseErr)
+				assert.Equal(t, responseErr.Message, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+//validCurrency checks if the currency is an ISO 4217 code like EUR
+func validCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+//setProductPrice sets the selling price and the currency of the product
+func (server *Server) setProductPrice(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("setProductPrice")
+	var price data.Price
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err = json.Unmarshal(jsonData, &price)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	price.ProductName = context.Param(productName)
+	price.Currency = strings.ToUpper(strings.TrimSpace(price.Currency))
+	if price.Price < 0 || !validCurrency(price.Currency) {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "a non negative price and a currency code like EUR are required",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, price = server.Inventory.SetProductPrice(context.Request.Context(), price)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Price:   &price,
+		Message: fmt.Sprintf("Price of %s is %.2f %s", price.ProductName, price.Price, price.Currency),
+	})
+	return
+}
+
+//getProductPrice provides the selling price of the product
+func (server *Server) getProductPrice(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getProductPrice")
+	err, price := server.Inventory.GetProductPrice(context.Request.Context(), context.Param(productName))
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Price: &price,
+	})
+	return
+}
+
+//salesFilter gets the product, the currency and the period of the sales query, a date as to includes that whole day
+func salesFilter(context *gin.Context) (data.SalesFilter, error) {
+	filter := data.SalesFilter{
+		ProductName: context.Query(productName),
+		Currency:    strings.ToUpper(context.Query(currencyParam)),
+	}
+	var err error
+	filter.From, err = parseTimeQuery(context, fromParam, false)
+	if err != nil {
+		return filter, err
+	}
+	filter.To, err = parseTimeQuery(context, toParam, true)
+	if err != nil {
+		return filter, err
+	}
+	if filter.From != nil && filter.To != nil && filter.To.Before(*filter.From) {
+		return filter, fmt.Errorf("%s cannot be before %s", toParam, fromParam)
+	}
+	return filter, nil
+}
+
+//getSales provides the sales, optionally of one product and in a period
+func (server *Server) getSales(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getSales")
+	filter, err := salesFilter(context)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, sales := server.Inventory.GetSales(context.Request.Context(), filter)
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if len(sales) == 0 {
+		context.JSON(http.StatusOK, ResponseProduct{
+			Message: "No sale is found",
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Sales: sales,
+	})
+	return
+}
+
+//getSalesRevenue provides the quantity sold and the revenue per product and currency, optionally in a period
+func (server *Server) getSalesRevenue(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getSalesRevenue")
+	filter, err := salesFilter(context)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, revenue := server.Inventory.GetSalesRevenue(context.Request.Context(), filter)
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Revenue: &revenue,
+	})
+	return
+}
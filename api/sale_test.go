@@ -0,0 +1,210 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer_setProductPrice(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		body       string
+		callDB     bool
+		expected   data.Price
+		dbErr      error
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "price_is_set",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:       `{"price":49.9,"currency":"eur"}`,
+			callDB:     true,
+			expected:   data.Price{ProductName: "Dining Chair", Price: 49.9, Currency: "EUR"},
+			statusCode: http.StatusOK,
+			message:    "Price of Dining Chair is 49.90 EUR",
+		},
+		{
+			name:       "product_not_found",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:       `{"price":49.9,"currency":"EUR"}`,
+			callDB:     true,
+			expected:   data.Price{ProductName: "Dining Chair", Price: 49.9, Currency: "EUR"},
+			dbErr:      db.ErrProductNotFound,
+			statusCode: http.StatusNotFound,
+			message:    db.ErrProductNotFound.Error(),
+		},
+		{
+			name:       "invalid_currency",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:       `{"price":49.9,"currency":"euro"}`,
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "a non negative price and a currency code like EUR are required",
+		},
+		{
+			name:       "negative_price",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			body:       `{"price":-1,"currency":"EUR"}`,
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "a non negative price and a currency code like EUR are required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			context.Params = []gin.Param{{Key: productName, Value: "Dining Chair"}}
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+			context.Request = &http.Request{Body: ioutil.NopCloser(strings.NewReader(tt.body))}
+
+			if tt.callDB {
+				inventory.EXPECT().SetProductPrice(gomock.Any(), tt.expected).Return(tt.dbErr, tt.expected)
+			}
+
+			server.setProductPrice(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+		})
+	}
+}
+
+func TestServer_getSales(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+	from := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 3, 31, 23, 59, 59, 999999000, time.UTC)
+	sales := []data.Sale{{SaleId: 1, ProductName: "Dining Chair", Quantity: 1, UnitPrice: 49.9, Currency: "EUR", SoldAt: from}}
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		query      url.Values
+		callDB     bool
+		filter     data.SalesFilter
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "all_sales",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			query:      url.Values{},
+			callDB:     true,
+			filter:     data.SalesFilter{},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "product_in_a_month",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			query:      url.Values{productName: {"Dining Chair"}, fromParam: {"2021-03-01"}, toParam: {"2021-03-31"}},
+			callDB:     true,
+			filter:     data.SalesFilter{ProductName: "Dining Chair", From: &from, To: &to},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "to_before_from",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			query:      url.Values{fromParam: {"2021-03-31"}, toParam: {"2021-03-01"}},
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "to cannot be before from",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+			context.Request = &http.Request{URL: &url.URL{RawQuery: tt.query.Encode()}}
+
+			if tt.callDB {
+				inventory.EXPECT().GetSales(gomock.Any(), tt.filter).Return(nil, sales)
+			}
+
+			server.getSales(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+			if tt.callDB {
+				assert.Equal(t, response.Sales, sales)
+			}
+		})
+	}
+}
+
+func TestServer_getSalesRevenue(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+	server := &Server{
+		Inventory: inventory,
+		router:    gin.New(),
+		Config:    Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"},
+		Logger:    logrus.NewEntry(logrus.New()),
+	}
+	context.Request = &http.Request{URL: &url.URL{RawQuery: fromParam + "=2021-03-01T00:00:00Z"}}
+	from := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	revenue := data.SalesRevenue{From: &from, Products: []data.ProductRevenue{
+		{ProductName: "Dining Chair", Currency: "EUR", Quantity: 3, Revenue: 149.7},
+		{ProductName: "Dining Table", Quantity: 1},
+	}}
+	inventory.EXPECT().GetSalesRevenue(gomock.Any(), data.SalesFilter{From: &from}).Return(nil, revenue)
+
+	server.getSalesRevenue(context)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var response ResponseProduct
+	byteArr, _ := ioutil.ReadAll(recorder.Body)
+	_ = json.Unmarshal(byteArr, &response)
+	assert.Equal(t, response.Revenue.Products, revenue.Products)
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+)
+
+//lookupBarcode resolves a scanned GTIN to the article or the product with its stock
+func (server *Server) lookupBarcode(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("lookupBarcode")
+	barcode := context.Param(barcodeParam)
+	if !data.ValidGTIN(barcode) {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("%s is not a valid GTIN", barcode),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, result := server.Inventory.LookupBarcode(context.Request.Context(), barcode)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Scan: &result,
+	})
+	return
+}
+
+//scanBarcode puts one article into the stock or takes one out of it for every scan
+func (server *Server) scanBarcode(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("scanBarcode")
+	barcode := context.Param(barcodeParam)
+	if !data.ValidGTIN(barcode) {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("%s is not a valid GTIN", barcode),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	var scan data.Scan
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err = json.Unmarshal(jsonData, &scan)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	var delta int
+	switch scan.Direction {
+	case data.ScanIn:
+		delta = 1
+	case data.ScanOut:
+		delta = -1
+	default:
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("unknown scan direction %s, use %s or %s", scan.Direction, data.ScanIn, data.ScanOut),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, article := server.Inventory.AdjustStockByScan(context.Request.Context(), barcode, delta)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Article: &article,
+		Message: fmt.Sprintf("Stock of article %s is %s", article.ArtId, article.Stock),
+	})
+	return
+}
+
+//validateArticleBarcodes checks the check digits of the uploaded article barcodes
+func validateArticleBarcodes(inventory data.Inventory) error {
+	for _, article := range inventory.Inventory {
+		for _, barcode := range article.Barcodes {
+			if !data.ValidGTIN(barcode) {
+				return fmt.Errorf("barcode %s of article %s is not a valid GTIN", barcode, article.ArtId)
+			}
+		}
+	}
+	return nil
+}
+
+//validateProductBarcodes checks the check digits of the uploaded product barcodes
+func validateProductBarcodes(products data.Products) error {
+	for _, product := range products.Products {
+		for _, barcode := range product.Barcodes {
+			if !data.ValidGTIN(barcode) {
+				return fmt.Errorf("barcode %s of product %s is not a valid GTIN", barcode, product.Name)
+			}
+		}
+	}
+	return nil
+}
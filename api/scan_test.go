@@ -0,0 +1,227 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServer_lookupBarcode(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		barcode    string
+		callDB     bool
+		dbErr      error
+		result     data.ScanResult
+		statusCode int
+	}{
+		{
+			name:       "article_barcode",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			barcode:    "4006381333931",
+			callDB:     true,
+			result:     data.ScanResult{Barcode: "4006381333931", Article: &data.Stock{ArtId: "1", Name: "leg", Stock: "12"}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "product_barcode",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			barcode:    "036000291452",
+			callDB:     true,
+			result:     data.ScanResult{Barcode: "036000291452", Product: &data.ProductStock{Name: "table", AvailableProductNo: "3"}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "unknown_barcode",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			barcode:    "96385074",
+			callDB:     true,
+			dbErr:      db.ErrBarcodeNotFound,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "wrong_check_digit",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			barcode:    "4006381333932",
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "not_a_number",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			barcode:    "40063813339A1",
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			context.Params = []gin.Param{
+				{
+					Key:   barcodeParam,
+					Value: tt.barcode,
+				},
+			}
+			context.Request, _ = http.NewRequest(http.MethodGet, "/warehouse/v1/scan/"+tt.barcode, nil)
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			if tt.callDB {
+				inventory.EXPECT().LookupBarcode(gomock.Any(), tt.barcode).Return(tt.dbErr, tt.result)
+			}
+
+			server.lookupBarcode(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			if tt.statusCode == http.StatusOK {
+				var response ResponseProduct
+				byteArr, _ := ioutil.ReadAll(recorder.Body)
+				_ = json.Unmarshal(byteArr, &response)
+				assert.Equal(t, *response.Scan, tt.result)
+			}
+		})
+	}
+}
+
+func TestServer_scanBarcode(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+	context.Params = []gin.Param{
+		{
+			Key:   barcodeParam,
+			Value: "4006381333931",
+		},
+	}
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	type args struct {
+		context *gin.Context
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		scan       data.Scan
+		callDB     bool
+		delta      int
+		dbErr      error
+		stock      string
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "scan_in",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			scan:       data.Scan{Direction: data.ScanIn},
+			callDB:     true,
+			delta:      1,
+			stock:      "13",
+			statusCode: http.StatusOK,
+			message:    "Stock of article 1 is 13",
+		},
+		{
+			name:       "scan_out",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			scan:       data.Scan{Direction: data.ScanOut},
+			callDB:     true,
+			delta:      -1,
+			stock:      "11",
+			statusCode: http.StatusOK,
+			message:    "Stock of article 1 is 11",
+		},
+		{
+			name:       "out_of_stock",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			scan:       data.Scan{Direction: data.ScanOut},
+			callDB:     true,
+			delta:      -1,
+			dbErr:      db.ErrInsufficientStock,
+			statusCode: http.StatusConflict,
+			message:    db.ErrInsufficientStock.Error(),
+		},
+		{
+			name:       "product_barcode",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			scan:       data.Scan{Direction: data.ScanIn},
+			callDB:     true,
+			delta:      1,
+			dbErr:      db.ErrBarcodeNotAdjustable,
+			statusCode: http.StatusConflict,
+			message:    "barcode belongs to a product, only article stock can be adjusted by scan",
+		},
+		{
+			name:       "unknown_direction",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			scan:       data.Scan{Direction: "sideways"},
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "unknown scan direction sideways, use in or out",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Body.Reset()
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			reqBodyBytes := new(bytes.Buffer)
+			json.NewEncoder(reqBodyBytes).Encode(tt.scan)
+			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBuffer(reqBodyBytes.Bytes()))}
+
+			if tt.callDB {
+				inventory.EXPECT().AdjustStockByScan(gomock.Any(), "4006381333931", tt.delta).Return(tt.dbErr, data.Stock{ArtId: "1", Name: "leg", Stock: tt.stock})
+			}
+
+			server.scanBarcode(tt.args.context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+		})
+	}
+}
@@ -1,15 +1,21 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/auknl/warehouse/auth"
 	"github.com/auknl/warehouse/data"
 	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/metrics"
+	"github.com/auknl/warehouse/ratelimit"
 	"github.com/auknl/warehouse/request"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"io/ioutil"
 	"net/http"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,83 +25,309 @@ type Server struct {
 	router    *gin.Engine
 	Config    Configuration
 	Logger    *logrus.Entry
+	//Metrics records the requests and the business events, nil records nothing
+	Metrics *metrics.Metrics
+	//Tokens verifies the bearer tokens, nil accepts only API keys
+	Tokens *auth.Verifier
+	//rateLimits are the limits of the route groups, taken from the limiter
+	rateLimits map[string]data.RateLimit
+	limiter    rateLimiter
+	//worker is the lease of this process on the import jobs it runs
+	worker string
+	//jobs wakes the import job worker up when a job is queued
+	jobs chan struct{}
+	//stopJobs cancels the import job worker, jobsDone is closed when it returns
+	stopJobs context.CancelFunc
+	jobsDone chan struct{}
+	//httpServer serves the router until Shutdown
+	httpServer *http.Server
+	//ready is 1 while the server takes requests and 0 once it shuts down, accessed atomically
+	ready int32
 }
 
 // Configuration keeps required info for running server
 type Configuration struct {
-	BackendTimeout string `default:"25s"`
-	ListenAddress  string `default:":8080"`
+	BackendTimeout       string `default:"25s"`
+	ListenAddress        string `default:":8080"`
+	IdempotencyRetention string `default:"24h"`
+	//IdempotencyStaleAfter is the time after that a reservation still in progress is taken over, longer than the
+	//backend timeout so that only the reservations of a crashed instance are
+	IdempotencyStaleAfter string `default:"1m"`
+	UploadBatchSize       int    `default:"1000"`
+	JobPollInterval       string `default:"5s"`
+	JobStaleAfter         string `default:"5m"`
+	//AuthDisabled serves every endpoint without an API key or a token, only for local development
+	AuthDisabled bool `default:"false"`
+	//RateLimits are the token bucket limits per client and route group, "<group>=<requests>/<s|m|h>[:<burst>]"
+	//separated by commas, the groups are read, write, sell and admin. Empty limits nothing
+	RateLimits string
+	//RateLimitStore keeps the buckets in the process with "memory", or shares them between instances with "postgres"
+	RateLimitStore string `default:"memory"`
+	//ShutdownDelay is the time the server keeps serving as unready before draining, so load balancers stop sending
+	ShutdownDelay string `default:"5s"`
 }
 
 // NewServer creates a new HTTP server and set up routing.
-func NewServer(inventory db.Inventory, configuration Configuration, logger *logrus.Entry) *Server {
-	server := &Server{Inventory: inventory}
+func NewServer(inventory db.Inventory, serviceMetrics *metrics.Metrics, tokens *auth.Verifier, configuration Configuration, logger *logrus.Entry) *Server {
+	server := &Server{Inventory: inventory, Metrics: serviceMetrics, Tokens: tokens, worker: uuid.New().String(), jobs: make(chan struct{}, 1)}
 	router := gin.New()
 
 	router.Use(
 		gin.Recovery(),
-		server.setDeadline, //TODO: use deadline while querying db
+		server.traceRequest,
+		server.setRID,
+		server.observeRequest,
+		server.setDeadline,
 	)
 
+	inventoryRead := server.guard(data.ScopeInventoryRead, rateGroupRead)
+	inventoryWrite := server.guard(data.ScopeInventoryWrite, rateGroupWrite)
+	productSell := server.guard(data.ScopeProductSell, rateGroupSell)
+	orderRead := server.guard(data.ScopeOrderRead, rateGroupRead)
+	orderWrite := server.guard(data.ScopeOrderWrite, rateGroupWrite)
+	purchaseRead := server.guard(data.ScopePurchaseRead, rateGroupRead)
+	purchaseWrite := server.guard(data.ScopePurchaseWrite, rateGroupWrite)
+	salesRead := server.guard(data.ScopeSalesRead, rateGroupRead)
+	priceWrite := server.guard(data.ScopePriceWrite, rateGroupWrite)
+	admin := server.guard(data.ScopeAdmin, rateGroupAdmin)
+
+	if serviceMetrics != nil {
+		router.GET("metrics", gin.WrapH(serviceMetrics.Handler()))
+	}
 	router.GET("warehouse/v1/health", server.isHealthy)
-	router.GET("warehouse/v1/inventory", server.getInventory)
-	router.GET("warehouse/v1/product", server.getProductStock)
-	router.POST("warehouse/v1/product", server.uploadProducts)
-	router.POST("warehouse/v1/inventory", server.uploadInventory)
-	router.POST("warehouse/v1/product/:"+productName, server.sellProduct)
+	router.GET("warehouse/v1/ready", server.isReady)
+	router.GET("warehouse/v1/inventory", inventoryRead, server.getInventory)
+	router.GET("warehouse/v1/inventory/:"+artID, inventoryRead, server.getArticle)
+	router.GET("warehouse/v1/product", inventoryRead, server.getProductStock)
+	router.POST("warehouse/v1/product", inventoryWrite, server.idempotent, server.uploadProducts)
+	router.POST("warehouse/v1/inventory", inventoryWrite, server.idempotent, server.uploadInventory)
+	router.POST("warehouse/v1/product/:"+productName, productSell, server.idempotent, server.sellProduct)
+	router.POST("warehouse/v1/returns", inventoryWrite, server.idempotent, server.returnProduct)
+	router.GET("warehouse/v1/orders", orderRead, server.getOrders)
+	router.GET("warehouse/v1/orders/:"+orderID, orderRead, server.getOrder)
+	router.POST("warehouse/v1/orders", orderWrite, server.idempotent, server.createOrder)
+	router.POST("warehouse/v1/orders/:"+orderID+"/status", orderWrite, server.idempotent, server.updateOrderStatus)
+	router.GET("warehouse/v1/purchase-orders", purchaseRead, server.getPurchaseOrders)
+	router.GET("warehouse/v1/purchase-orders/:"+purchaseOrderID, purchaseRead, server.getPurchaseOrder)
+	router.POST("warehouse/v1/purchase-orders", purchaseWrite, server.idempotent, server.createPurchaseOrder)
+	router.POST("warehouse/v1/purchase-orders/:"+purchaseOrderID+"/receipts", purchaseWrite, server.idempotent, server.receiveGoods)
+	router.POST("warehouse/v1/purchase-orders/:"+purchaseOrderID+"/close", purchaseWrite, server.closePurchaseOrder)
+	router.GET("warehouse/v1/suppliers", purchaseRead, server.getSuppliers)
+	router.GET("warehouse/v1/suppliers/:"+supplierID, purchaseRead, server.getSupplier)
+	router.POST("warehouse/v1/suppliers", purchaseWrite, server.idempotent, server.createSupplier)
+	router.PUT("warehouse/v1/suppliers/:"+supplierID, purchaseWrite, server.updateSupplier)
+	router.DELETE("warehouse/v1/suppliers/:"+supplierID, purchaseWrite, server.deleteSupplier)
+	router.PUT("warehouse/v1/suppliers/:"+supplierID+"/articles/:"+artID, purchaseWrite, server.saveSupplierArticle)
+	router.DELETE("warehouse/v1/suppliers/:"+supplierID+"/articles/:"+artID, purchaseWrite, server.deleteSupplierArticle)
+	router.GET("warehouse/v1/scan/:"+barcodeParam, inventoryRead, server.lookupBarcode)
+	router.POST("warehouse/v1/scan/:"+barcodeParam, inventoryWrite, server.idempotent, server.scanBarcode)
+	router.GET("warehouse/v1/jobs/:"+jobID, inventoryRead, server.getImportJob)
+	router.GET("warehouse/v1/admin/snapshot", admin, server.getSnapshot)
+	router.GET("warehouse/v1/valuation", inventoryRead, server.getValuation)
+	router.GET("warehouse/v1/product/:"+productName+"/price", salesRead, server.getProductPrice)
+	router.PUT("warehouse/v1/product/:"+productName+"/price", priceWrite, server.setProductPrice)
+	router.GET("warehouse/v1/sales", salesRead, server.getSales)
+	router.GET("warehouse/v1/sales/revenue", salesRead, server.getSalesRevenue)
+	router.GET("warehouse/v1/sales/top", salesRead, server.getTopProducts)
+	router.GET("warehouse/v1/sales/series", salesRead, server.getSalesSeries)
+	router.GET("warehouse/v1/sales/sell-through", salesRead, server.getSellThrough)
+	router.POST("warehouse/v1/admin/snapshot", admin, server.restoreSnapshot)
+	router.GET("warehouse/v1/admin/api-keys", admin, server.getAPIKeys)
+	router.POST("warehouse/v1/admin/api-keys", admin, server.createAPIKey)
+	router.DELETE("warehouse/v1/admin/api-keys/:"+keyID, admin, server.revokeAPIKey)
 
 	server.router = router
+	server.httpServer = &http.Server{Addr: configuration.ListenAddress, Handler: router}
 	server.Config = configuration
 	server.Logger = logger
+	server.setRateLimits()
 	return server
 }
 
-// Start runs the HTTP server on a specific address.
+//setRateLimits parses the configured rate limits and picks their store, the limits are off when they cannot be parsed
+func (server *Server) setRateLimits() {
+	limits, err := ratelimit.ParseLimits(server.Config.RateLimits)
+	if err != nil {
+		server.Logger.WithField("err", err).Error("Could not parse rate limits, requests are not limited")
+		return
+	}
+	if len(limits) == 0 {
+		return
+	}
+	server.rateLimits = limits
+	if server.Config.RateLimitStore == rateLimitStorePostgres {
+		server.limiter = server.Inventory
+		return
+	}
+	server.limiter = ratelimit.NewMemory()
+}
+
+// Start runs the HTTP server on a specific address until Shutdown, which makes it return nil.
 func (server *Server) Start() error {
-	return server.router.Run(server.Config.ListenAddress)
+	server.startImportJobs()
+	atomic.StoreInt32(&server.ready, 1)
+	err := server.httpServer.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
 }
 
-//setDeadline sets the deadline to limit the process time of the request
+// Shutdown flips the server to unready and keeps serving for the shutdown delay, then it stops taking requests and
+// waits for the in-flight ones and the import job worker until the context is done.
+func (server *Server) Shutdown(ctx context.Context) error {
+	atomic.StoreInt32(&server.ready, 0)
+	shutdownDelay, err := time.ParseDuration(server.Config.ShutdownDelay)
+	if err != nil {
+		server.Logger.WithField("err", err).Error("Could not parse shutdown delay duration")
+		shutdownDelay = defaultShutdownDelay
+	}
+	server.Logger.WithField("delay", shutdownDelay.String()).Info("Shutdown, server is unready")
+	select {
+	case <-time.After(shutdownDelay):
+	case <-ctx.Done():
+	}
+
+	err = server.httpServer.Shutdown(ctx)
+	if err != nil {
+		server.Logger.WithField("err", err).Error("Shutdown, in-flight requests are not drained")
+	}
+	jobErr := server.stopImportJobs(ctx)
+	if jobErr != nil {
+		server.Logger.WithField("err", jobErr).Error("Shutdown, import job worker is not stopped")
+	}
+	if err != nil {
+		return err
+	}
+	return jobErr
+}
+
+//setRID takes the request id from the X-Request-ID header, or the trace id of the request, or generates it. The id is
+//kept in the gin context and in the request context that reaches the database layer, and echoed in the response header
+func (server *Server) setRID(context *gin.Context) {
+	rid := context.GetString(ridKey)
+	if rid == "" && context.Request != nil {
+		rid = context.GetHeader(requestIDHeader)
+		if !validRID(rid) {
+			rid = request.IDFromContext(context.Request.Context())
+		}
+	}
+	if rid == "" {
+		rid = uuid.New().String()
+	}
+
+	context.Set(ridKey, rid)
+	if context.Request != nil {
+		context.Request = context.Request.WithContext(request.WithID(context.Request.Context(), rid))
+	}
+	context.Header(requestIDHeader, rid)
+}
+
+//validRID checks if the request id sent by the client can be logged as it is
+func validRID(rid string) bool {
+	if rid == "" || len(rid) > maxRIDLength {
+		return false
+	}
+	for _, r := range rid {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+//setDeadline limits the process time of the request to the backend timeout. The database calls of the request get
+//its context, so they are cancelled when the deadline passes or the client disconnects
 func (server *Server) setDeadline(context *gin.Context) {
 	backendTimeout, err := time.ParseDuration(server.Config.BackendTimeout)
 	if err != nil {
 		server.Logger.WithField("err", err).Error("Could not parse backend timeout duration")
+		backendTimeout = defaultBackendTimeout
 	}
 
-	deadline := time.Now().Add(backendTimeout)
-	context.Set("deadline", deadline)
+	httpRequest, cancel := withTimeout(context.Request, backendTimeout)
+	defer cancel()
+	context.Request = httpRequest
+	context.Next()
 }
 
 //isHealthy checks if the service is available to respond
 func (server *Server) isHealthy(context *gin.Context) {
-	log := server.Logger.WithField("rid", request.GetRID(context))
+	log := server.requestLog(context)
 	log.Debug("isHealthy")
 	err := server.Inventory.Ping()
 	if err != nil {
 		log.WithField("err", err.Error()).Error("IsHealthy ping failed")
-		context.JSON(http.StatusInternalServerError, ResponseError{
+		respondError(context, http.StatusInternalServerError, ResponseError{
 			Message: "unhealthy endpoint",
+			RID:     request.GetRID(context.Request.Context()),
 		})
 		return
 	}
 	context.JSON(http.StatusOK, ResponseError{
 		Message: "healthy endpoint",
+		RID:     request.GetRID(context.Request.Context()),
+	})
+	return
+}
+
+//isReady checks if the service takes requests, it is unready while shutting down or when the database is unreachable
+func (server *Server) isReady(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("isReady")
+	if atomic.LoadInt32(&server.ready) == 0 {
+		respondError(context, http.StatusServiceUnavailable, ResponseError{
+			Message: "shutting down",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err := server.Inventory.Ping()
+	if err != nil {
+		log.WithField("err", err.Error()).Error("IsReady ping failed")
+		respondError(context, http.StatusServiceUnavailable, ResponseError{
+			Message: "unready endpoint",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseError{
+		Message: "ready endpoint",
+		RID:     request.GetRID(context.Request.Context()),
 	})
 	return
 }
 
 //getInventory provides inventory/stock info
 func (server *Server) getInventory(context *gin.Context) {
-	log := server.Logger.WithField("rid", request.GetRID(context))
+	log := server.requestLog(context)
 	log.Debug("getInventory")
-	err, stocks := server.Inventory.GetInventory(context)
+	asOf, historic, err := parseAsOf(context)
 	if err != nil {
-		context.JSON(http.StatusNotFound, ResponseError{
+		respondError(context, http.StatusBadRequest, ResponseError{
 			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	var stocks []data.Stock
+	if historic {
+		err, stocks = server.Inventory.GetInventoryAsOf(context.Request.Context(), asOf)
+	} else {
+		err, stocks = server.Inventory.GetInventory(context.Request.Context())
+	}
+	if err != nil {
+		respondError(context, http.StatusNotFound, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
 		})
 		return
 	}
 
+	if acceptsCSV(context) {
+		writeCSV(context, inventoryCSV(stocks))
+		return
+	}
 	context.JSON(http.StatusOK, ResponseProduct{
 		Inventory: stocks,
 	})
@@ -104,16 +336,34 @@ func (server *Server) getInventory(context *gin.Context) {
 
 // getProductStock provides the stock info of available products in system
 func (server *Server) getProductStock(context *gin.Context) {
-	log := server.Logger.WithField("rid", request.GetRID(context))
+	log := server.requestLog(context)
 	log.Debug("getProductStock")
-	err, stocks := server.Inventory.GetProductStock(context)
+	asOf, historic, err := parseAsOf(context)
 	if err != nil {
-		context.JSON(http.StatusNotFound, ResponseError{
+		respondError(context, http.StatusBadRequest, ResponseError{
 			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	var stocks data.ProductStocks
+	if historic {
+		err, stocks = server.Inventory.GetProductStockAsOf(context.Request.Context(), asOf)
+	} else {
+		err, stocks = server.Inventory.GetProductStock(context.Request.Context())
+	}
+	if err != nil {
+		respondError(context, http.StatusNotFound, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
 		})
 		return
 	}
 
+	if acceptsCSV(context) {
+		writeCSV(context, productStockCSV(stocks))
+		return
+	}
 	var product ResponseProduct
 	if len(stocks) == 0 {
 		product = ResponseProduct{
@@ -131,30 +381,50 @@ func (server *Server) getProductStock(context *gin.Context) {
 
 //uploadProducts inserts given products to system
 func (server *Server) uploadProducts(context *gin.Context) {
-	log := server.Logger.WithField("rid", request.GetRID(context))
+	log := server.requestLog(context)
 	log.Debug("uploadProducts")
+	if isAsync(context) {
+		server.createImportJob(context, data.ImportProducts)
+		return
+	}
+	if isNDJSON(context) {
+		server.uploadNDJSON(context, server.streamProducts, "product")
+		return
+	}
 	var products data.Products
-	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	var err error
+	if isCSV(context) {
+		products, err = parseProductsCSV(context.Request.Body)
+	} else {
+		var jsonData []byte
+		jsonData, err = ioutil.ReadAll(context.Request.Body)
+		if err == nil {
+			err = json.Unmarshal(jsonData, &products)
+		}
+	}
 	if err != nil {
-		context.JSON(http.StatusBadRequest, ResponseError{
+		respondError(context, http.StatusBadRequest, ResponseError{
 			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
 		})
 		return
 	}
 
-	err = json.Unmarshal(jsonData, &products)
+	err = validateProductBarcodes(products)
 	if err != nil {
-		context.JSON(http.StatusBadRequest, ResponseError{
+		respondError(context, http.StatusBadRequest, ResponseError{
 			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
 		})
 		return
 	}
 
 	insertedRecord := 0
-	err, insertedRecord = server.Inventory.UploadProducts(context, products)
+	err, insertedRecord = server.Inventory.UploadProducts(context.Request.Context(), products)
 	if err != nil {
-		context.JSON(http.StatusBadRequest, ResponseError{
+		respondError(context, http.StatusBadRequest, ResponseError{
 			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
 		})
 		return
 	}
@@ -168,29 +438,50 @@ func (server *Server) uploadProducts(context *gin.Context) {
 
 //uploadInventory inserts given inventory/stock info to system
 func (server *Server) uploadInventory(context *gin.Context) {
-	log := server.Logger.WithField("rid", request.GetRID(context))
+	log := server.requestLog(context)
 	log.Debug("uploadInventory")
+	if isAsync(context) {
+		server.createImportJob(context, data.ImportInventory)
+		return
+	}
+	if isNDJSON(context) {
+		server.uploadNDJSON(context, server.streamInventory, "item")
+		return
+	}
 	var inventory data.Inventory
-	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	var err error
+	if isCSV(context) {
+		inventory, err = parseInventoryCSV(context.Request.Body)
+	} else {
+		var jsonData []byte
+		jsonData, err = ioutil.ReadAll(context.Request.Body)
+		if err == nil {
+			err = json.Unmarshal(jsonData, &inventory)
+		}
+	}
 	if err != nil {
-		context.JSON(http.StatusBadRequest, ResponseError{
+		respondError(context, http.StatusBadRequest, ResponseError{
 			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
 		})
 		return
 	}
-	err = json.Unmarshal(jsonData, &inventory)
+
+	err = validateArticleBarcodes(inventory)
 	if err != nil {
-		context.JSON(http.StatusBadRequest, ResponseError{
+		respondError(context, http.StatusBadRequest, ResponseError{
 			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
 		})
 		return
 	}
 
 	insertedInventory := 0
-	err, insertedInventory = server.Inventory.UploadInventory(context, inventory)
+	err, insertedInventory = server.Inventory.UploadInventory(context.Request.Context(), inventory)
 	if err != nil {
-		context.JSON(http.StatusBadRequest, ResponseError{
+		respondError(context, http.StatusBadRequest, ResponseError{
 			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
 		})
 		return
 	}
@@ -204,16 +495,24 @@ func (server *Server) uploadInventory(context *gin.Context) {
 
 //sellProduct handles the sell product request
 func (server *Server) sellProduct(context *gin.Context) {
-	log := server.Logger.WithField("rid", request.GetRID(context))
+	log := server.requestLog(context)
 	log.Debug("sellProduct")
 	productName := context.Param(productName)
-	err := server.Inventory.SellProduct(context, productName)
+	err := server.Inventory.SellProduct(context.Request.Context(), productName)
 	if err != nil {
-		context.JSON(http.StatusBadRequest, ResponseError{
+		reason := sellFailureReason(err)
+		server.Metrics.SellFailed(reason)
+		statusCode := http.StatusBadRequest
+		if reason == metrics.SellFailureError {
+			statusCode = http.StatusInternalServerError
+		}
+		respondError(context, statusCode, ResponseError{
 			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
 		})
 		return
 	}
+	server.Metrics.SellSucceeded()
 	message := fmt.Sprintf("Product %s is sold and inventory is updated accordingly", productName)
 	context.JSON(http.StatusOK, ResponseProduct{
 		Message: message,
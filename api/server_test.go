@@ -2,11 +2,13 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/auknl/warehouse/api/mocks"
 	"github.com/auknl/warehouse/data"
 	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"github.com/golang/mock/gomock"
@@ -14,6 +16,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 )
 
@@ -23,6 +26,7 @@ func TestServer_getInventory(t *testing.T) {
 	recorder := httptest.NewRecorder()
 	context, engine := gin.CreateTestContext(recorder)
 	inventory := mocks.NewMockInventory(controller)
+	context.Request = &http.Request{URL: &url.URL{}}
 	stock := data.Stock{Stock: "9", Name: "test_item", ArtId: "1"}
 	stockList := []data.Stock{stock}
 
@@ -72,9 +76,9 @@ func TestServer_getInventory(t *testing.T) {
 		}
 
 		if !tt.wantFail {
-			inventory.EXPECT().GetInventory(context).Return(nil, stockList)
+			inventory.EXPECT().GetInventory(gomock.Any()).Return(nil, stockList)
 		} else {
-			inventory.EXPECT().GetInventory(context).Return(errors.New("query test err"), nil)
+			inventory.EXPECT().GetInventory(gomock.Any()).Return(errors.New("query test err"), nil)
 		}
 		t.Run(tt.name, f)
 		assert.Equal(t, tt.statusCode, context.Writer.Status())
@@ -92,6 +96,7 @@ func TestServer_getProductStock(t *testing.T) {
 	recorder := httptest.NewRecorder()
 	context, engine := gin.CreateTestContext(recorder)
 	inventory := mocks.NewMockInventory(controller)
+	context.Request = &http.Request{URL: &url.URL{}}
 
 	type fields struct {
 		Inventory db.Inventory
@@ -150,9 +155,9 @@ func TestServer_getProductStock(t *testing.T) {
 			}
 
 			if tt.queryFail {
-				inventory.EXPECT().GetProductStock(context).Return(errors.New("query failed test"), nil)
+				inventory.EXPECT().GetProductStock(gomock.Any()).Return(errors.New("query failed test"), nil)
 			} else {
-				inventory.EXPECT().GetProductStock(context).Return(nil, tt.expectedStock)
+				inventory.EXPECT().GetProductStock(gomock.Any()).Return(nil, tt.expectedStock)
 			}
 
 			server.getProductStock(tt.args.context)
@@ -223,9 +228,9 @@ func TestServer_uploadInventory(t *testing.T) {
 			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBuffer(reqBodyBytes.Bytes()))}
 
 			if tt.wantFail {
-				inventory.EXPECT().UploadInventory(context, gomock.Any()).Return(errors.New("upload failed test"), 0)
+				inventory.EXPECT().UploadInventory(gomock.Any(), gomock.Any()).Return(errors.New("upload failed test"), 0)
 			} else {
-				inventory.EXPECT().UploadInventory(context, gomock.Any()).Return(nil, 1)
+				inventory.EXPECT().UploadInventory(gomock.Any(), gomock.Any()).Return(nil, 1)
 			}
 
 			server.uploadInventory(tt.args.context)
@@ -302,9 +307,9 @@ func TestServer_uploadProducts(t *testing.T) {
 			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBuffer(reqBodyBytes.Bytes()))}
 
 			if tt.wantFail {
-				inventory.EXPECT().UploadProducts(context, gomock.Any()).Return(errors.New("upload product failed test"), 0)
+				inventory.EXPECT().UploadProducts(gomock.Any(), gomock.Any()).Return(errors.New("upload product failed test"), 0)
 			} else {
-				inventory.EXPECT().UploadProducts(context, gomock.Any()).Return(nil, 1)
+				inventory.EXPECT().UploadProducts(gomock.Any(), gomock.Any()).Return(nil, 1)
 			}
 
 			server.uploadProducts(tt.args.context)
@@ -337,6 +342,7 @@ func TestServer_sellProduct(t *testing.T) {
 			Value: "product_test",
 		},
 	}
+	context.Request, _ = http.NewRequest(http.MethodPost, "/warehouse/v1/product/product_test", nil)
 
 	type fields struct {
 		Inventory db.Inventory
@@ -352,6 +358,7 @@ func TestServer_sellProduct(t *testing.T) {
 		fields     fields
 		args       args
 		wantFail   bool
+		sellErr    error
 		statusCode int
 		message    string
 	}{
@@ -368,8 +375,18 @@ func TestServer_sellProduct(t *testing.T) {
 			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
 			args:       args{context: context},
 			wantFail:   true,
+			sellErr:    db.ErrProductOutOfStock,
 			statusCode: http.StatusBadRequest,
-			message:    "sell product failed",
+			message:    db.ErrProductOutOfStock.Error(),
+		},
+		{
+			name:       "sell_query_failed",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			wantFail:   true,
+			sellErr:    errors.New("pq: relation \"product\" does not exist"),
+			statusCode: http.StatusInternalServerError,
+			message:    "pq: relation \"product\" does not exist",
 		},
 	}
 	for _, tt := range tests {
@@ -381,11 +398,7 @@ func TestServer_sellProduct(t *testing.T) {
 				Logger:    tt.fields.Logger,
 			}
 
-			if tt.wantFail {
-				inventory.EXPECT().SellProduct(context, gomock.Any()).Return(errors.New("sell product failed"))
-			} else {
-				inventory.EXPECT().SellProduct(context, gomock.Any()).Return(nil)
-			}
+			inventory.EXPECT().SellProduct(gomock.Any(), gomock.Any()).Return(tt.sellErr)
 
 			server.sellProduct(tt.args.context)
 
@@ -410,6 +423,7 @@ func TestServer_isHealthy(t *testing.T) {
 	controller := gomock.NewController(t)
 	recorder := httptest.NewRecorder()
 	context, engine := gin.CreateTestContext(recorder)
+	context.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
 	inventory := mocks.NewMockInventory(controller)
 
 	type fields struct {
@@ -535,3 +549,60 @@ func TestServer_setRID(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_requestID(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := NewServer(inventory, nil, nil, Configuration{BackendTimeout: "25s", AuthDisabled: true}, logrus.NewEntry(logrus.New()))
+
+	tests := []struct {
+		name      string
+		requestID string
+		keepID    bool
+	}{
+		{
+			name:      "client_id",
+			requestID: "checkout-42",
+			keepID:    true,
+		},
+		{
+			name:      "no_id",
+			requestID: "",
+			keepID:    false,
+		},
+		{
+			name:      "id_with_spaces",
+			requestID: "bad id\nwith new line",
+			keepID:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dbRID string
+			inventory.EXPECT().SellProduct(gomock.Any(), "Sofa").DoAndReturn(func(ctx context.Context, productName string) error {
+				dbRID = request.GetRID(ctx)
+				return db.ErrProductNotInSystem
+			})
+
+			recorder := httptest.NewRecorder()
+			httpRequest, _ := http.NewRequest(http.MethodPost, "/warehouse/v1/product/Sofa", nil)
+			if tt.requestID != "" {
+				httpRequest.Header.Set(requestIDHeader, tt.requestID)
+			}
+			server.router.ServeHTTP(recorder, httpRequest)
+
+			rid := recorder.Header().Get(requestIDHeader)
+			var response ResponseError
+			_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+			if tt.keepID {
+				assert.Equal(t, rid, tt.requestID)
+			} else {
+				assert.NotEqual(t, rid, "")
+				assert.NotEqual(t, rid, tt.requestID)
+			}
+			assert.Equal(t, dbRID, rid)
+			assert.Equal(t, response.RID, rid)
+			assert.Equal(t, response.Message, db.ErrProductNotInSystem.Error())
+		})
+	}
+}
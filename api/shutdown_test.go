@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+//waitForStatus polls the url until it responds with the status
+func waitForStatus(t *testing.T, url string, statusCode int) {
+	for i := 0; i < 100; i++ {
+		response, err := http.Get(url)
+		if err == nil {
+			response.Body.Close()
+			if response.StatusCode == statusCode {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s did not respond %d", url, statusCode)
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Equal(t, err, nil)
+	address := listener.Addr().String()
+	listener.Close()
+
+	server := NewServer(inventory, nil, nil, Configuration{
+		ListenAddress:   address,
+		BackendTimeout:  "5s",
+		JobPollInterval: "1h",
+		ShutdownDelay:   "300ms",
+		AuthDisabled:    true,
+	}, logrus.NewEntry(logrus.New()))
+
+	sellStarted := make(chan struct{})
+	releaseSell := make(chan struct{})
+	inventory.EXPECT().Ping().Return(nil).AnyTimes()
+	inventory.EXPECT().ClaimImportJob(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	inventory.EXPECT().SellProduct(gomock.Any(), "Dining Chair").DoAndReturn(func(ctx context.Context, productName string) error {
+		close(sellStarted)
+		<-releaseSell
+		return nil
+	})
+
+	started := make(chan error, 1)
+	go func() {
+		started <- server.Start()
+	}()
+	waitForStatus(t, "http://"+address+"/warehouse/v1/ready", http.StatusOK)
+
+	sold := make(chan int, 1)
+	go func() {
+		response, err := http.Post("http://"+address+"/warehouse/v1/product/Dining%20Chair", "application/json", nil)
+		if err != nil {
+			sold <- 0
+			return
+		}
+		response.Body.Close()
+		sold <- response.StatusCode
+	}()
+	<-sellStarted
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- server.Shutdown(context.Background())
+	}()
+	//the server is unready but still serving during the shutdown delay
+	waitForStatus(t, "http://"+address+"/warehouse/v1/ready", http.StatusServiceUnavailable)
+
+	close(releaseSell)
+	assert.Equal(t, <-sold, http.StatusOK)
+	assert.Equal(t, <-stopped, nil)
+	assert.Equal(t, <-started, nil)
+	_, err = http.Get("http://" + address + "/warehouse/v1/ready")
+	assert.NotEqual(t, err, nil)
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+//getSnapshot exports all articles, products and stock as a downloadable snapshot
+func (server *Server) getSnapshot(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getSnapshot")
+	err, snapshot := server.Inventory.GetSnapshot(context.Request.Context())
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	context.Header("Content-Disposition", fmt.Sprintf("attachment; filename=warehouse-snapshot-%s.json", snapshot.TakenAt.Format("20060102T150405Z")))
+	context.JSON(http.StatusOK, snapshot)
+	return
+}
+
+//restoreSnapshot loads a snapshot into the database, the existing articles and products are overwritten
+func (server *Server) restoreSnapshot(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("restoreSnapshot")
+	var snapshot data.Snapshot
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err = json.Unmarshal(jsonData, &snapshot)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if snapshot.Version < 1 || snapshot.Version > data.SnapshotVersion {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("snapshot version %d is not supported, the supported versions are 1 to %d", snapshot.Version, data.SnapshotVersion),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err = validateArticleBarcodes(data.Inventory{Inventory: snapshot.Articles})
+	if err == nil {
+		err = validateProductBarcodes(data.Products{Products: snapshot.Products})
+	}
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err = server.Inventory.RestoreSnapshot(context.Request.Context(), snapshot)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Message: fmt.Sprintf("Snapshot of %s is restored, %d article and %d product", snapshot.TakenAt.Format(time.RFC3339),
+			len(snapshot.Articles), len(snapshot.Products)),
+	})
+	return
+}
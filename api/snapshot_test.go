@@ -0,0 +1,134 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServer_getSnapshot(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	inventory := mocks.NewMockInventory(controller)
+	server := &Server{
+		Inventory: inventory,
+		router:    gin.New(),
+		Config:    Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"},
+		Logger:    logrus.NewEntry(logrus.New()),
+	}
+	snapshot := data.Snapshot{
+		Version:  data.SnapshotVersion,
+		TakenAt:  time.Date(2021, 1, 20, 10, 0, 0, 0, time.UTC),
+		Articles: []data.Stock{{ArtId: "1", Name: "leg", Stock: "12", Barcodes: []string{"4006381333931"}}},
+		Products: []data.Product{{Name: "Dining Chair", ContainArticles: []data.ArticleContain{{ArtId: "1", AmountOf: "4"}}}},
+	}
+	inventory.EXPECT().GetSnapshot(gomock.Any()).Return(nil, snapshot)
+
+	server.getSnapshot(context)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, recorder.Header().Get("Content-Disposition"), "attachment; filename=warehouse-snapshot-20210120T100000Z.json")
+	var response data.Snapshot
+	byteArr, _ := ioutil.ReadAll(recorder.Body)
+	_ = json.Unmarshal(byteArr, &response)
+	assert.Equal(t, response, snapshot)
+}
+
+func TestServer_restoreSnapshot(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	type args struct {
+		context *gin.Context
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		snapshot   data.Snapshot
+		callDB     bool
+		statusCode int
+		message    string
+	}{
+		{
+			name:   "snapshot_restored",
+			fields: fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:   args{context: context},
+			snapshot: data.Snapshot{
+				Version:  1,
+				TakenAt:  time.Date(2021, 1, 20, 10, 0, 0, 0, time.UTC),
+				Articles: []data.Stock{{ArtId: "1", Name: "leg", Stock: "12"}},
+				Products: []data.Product{{Name: "Dining Chair", ContainArticles: []data.ArticleContain{{ArtId: "1", AmountOf: "4"}}}},
+			},
+			callDB:     true,
+			statusCode: http.StatusOK,
+			message:    "Snapshot of 2021-01-20T10:00:00Z is restored, 1 article and 1 product",
+		},
+		{
+			name:       "newer_version",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			snapshot:   data.Snapshot{Version: 2},
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "snapshot version 2 is not supported, the supported versions are 1 to 1",
+		},
+		{
+			name:       "missing_version",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			snapshot:   data.Snapshot{Articles: []data.Stock{{ArtId: "1", Name: "leg", Stock: "12"}}},
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "snapshot version 0 is not supported, the supported versions are 1 to 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Body.Reset()
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			reqBodyBytes := new(bytes.Buffer)
+			json.NewEncoder(reqBodyBytes).Encode(tt.snapshot)
+			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBuffer(reqBodyBytes.Bytes()))}
+
+			if tt.callDB {
+				inventory.EXPECT().RestoreSnapshot(gomock.Any(), tt.snapshot).Return(nil)
+			}
+
+			server.restoreSnapshot(tt.args.context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+		})
+	}
+}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+//getArticle provides the article detail with its suppliers
+func (server *Server) getArticle(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getArticle")
+	err, article := server.Inventory.GetArticle(context.Request.Context(), context.Param(artID))
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusNotFound), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Article: &article,
+	})
+	return
+}
+
+//getSuppliers provides all suppliers
+func (server *Server) getSuppliers(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getSuppliers")
+	err, suppliers := server.Inventory.GetSuppliers(context.Request.Context())
+	if err != nil {
+		respondError(context, http.StatusNotFound, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if len(suppliers) == 0 {
+		context.JSON(http.StatusOK, ResponseProduct{
+			Message: "No supplier found",
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Suppliers: suppliers,
+	})
+	return
+}
+
+//getSupplier provides the supplier with its article catalog
+func (server *Server) getSupplier(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getSupplier")
+	supplierId, err := strconv.Atoi(context.Param(supplierID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "supplier id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, supplier := server.Inventory.GetSupplier(context.Request.Context(), supplierId)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusNotFound), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Supplier: &supplier,
+	})
+	return
+}
+
+//createSupplier inserts a new supplier
+func (server *Server) createSupplier(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("createSupplier")
+	var supplier data.Supplier
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err = json.Unmarshal(jsonData, &supplier)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if supplier.Name == "" {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "supplier name is required",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, supplier = server.Inventory.CreateSupplier(context.Request.Context(), supplier)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	message := fmt.Sprintf("Supplier %d is created", supplier.SupplierId)
+	context.JSON(http.StatusOK, ResponseProduct{
+		Supplier: &supplier,
+		Message:  message,
+	})
+	return
+}
+
+//updateSupplier updates the supplier info
+func (server *Server) updateSupplier(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("updateSupplier")
+	supplierId, err := strconv.Atoi(context.Param(supplierID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "supplier id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	var supplier data.Supplier
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err = json.Unmarshal(jsonData, &supplier)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if supplier.Name == "" {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "supplier name is required",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	supplier.SupplierId = supplierId
+
+	err, supplier = server.Inventory.UpdateSupplier(context.Request.Context(), supplier)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	message := fmt.Sprintf("Supplier %d is updated", supplier.SupplierId)
+	context.JSON(http.StatusOK, ResponseProduct{
+		Supplier: &supplier,
+		Message:  message,
+	})
+	return
+}
+
+//deleteSupplier deletes the supplier and its article catalog
+func (server *Server) deleteSupplier(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("deleteSupplier")
+	supplierId, err := strconv.Atoi(context.Param(supplierID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "supplier id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err = server.Inventory.DeleteSupplier(context.Request.Context(), supplierId)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	message := fmt.Sprintf("Supplier %d is deleted", supplierId)
+	context.JSON(http.StatusOK, ResponseProduct{
+		Message: message,
+	})
+	return
+}
+
+//saveSupplierArticle adds the article to the supplier catalog or updates its purchasing info
+func (server *Server) saveSupplierArticle(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("saveSupplierArticle")
+	supplierId, err := strconv.Atoi(context.Param(supplierID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "supplier id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	var article data.SupplierArticle
+	jsonData, err := ioutil.ReadAll(context.Request.Body)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	err = json.Unmarshal(jsonData, &article)
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	if article.UnitCost < 0 || article.LeadTimeDays < 0 || article.MinOrderQuantity < 0 {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "unit_cost, lead_time_days and min_order_quantity cannot be negative",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	article.SupplierId = supplierId
+	article.ArtId = context.Param(artID)
+
+	err, article = server.Inventory.SaveSupplierArticle(context.Request.Context(), article)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	message := fmt.Sprintf("Article %s is saved to supplier %d", article.ArtId, article.SupplierId)
+	context.JSON(http.StatusOK, ResponseProduct{
+		SupplierArticle: &article,
+		Message:         message,
+	})
+	return
+}
+
+//deleteSupplierArticle removes the article from the supplier catalog
+func (server *Server) deleteSupplierArticle(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("deleteSupplierArticle")
+	supplierId, err := strconv.Atoi(context.Param(supplierID))
+	if err != nil {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: "supplier id must be a number",
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	artId := context.Param(artID)
+
+	err = server.Inventory.DeleteSupplierArticle(context.Request.Context(), supplierId, artId)
+	if err != nil {
+		respondError(context, errorStatusCode(err, http.StatusInternalServerError), ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	message := fmt.Sprintf("Article %s is removed from supplier %d", artId, supplierId)
+	context.JSON(http.StatusOK, ResponseProduct{
+		Message: message,
+	})
+	return
+}
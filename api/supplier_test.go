@@ -0,0 +1,270 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServer_createSupplier(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	type args struct {
+		context *gin.Context
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		supplier   data.Supplier
+		callDB     bool
+		dbErr      error
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "supplier_created",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			supplier:   data.Supplier{Name: "Acme Screws", Email: "sales@acme.test"},
+			callDB:     true,
+			statusCode: http.StatusOK,
+			message:    "Supplier 1 is created",
+		},
+		{
+			name:       "supplier_exists",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			supplier:   data.Supplier{Name: "Acme Screws"},
+			callDB:     true,
+			dbErr:      db.ErrSupplierExists,
+			statusCode: http.StatusConflict,
+			message:    "supplier with this name already exists",
+		},
+		{
+			name:       "missing_name",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			supplier:   data.Supplier{Email: "sales@acme.test"},
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "supplier name is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Body.Reset()
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			reqBodyBytes := new(bytes.Buffer)
+			json.NewEncoder(reqBodyBytes).Encode(tt.supplier)
+			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBuffer(reqBodyBytes.Bytes()))}
+
+			if tt.callDB {
+				created := tt.supplier
+				created.SupplierId = 1
+				inventory.EXPECT().CreateSupplier(gomock.Any(), tt.supplier).Return(tt.dbErr, created)
+			}
+
+			server.createSupplier(tt.args.context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+		})
+	}
+}
+
+func TestServer_getArticle(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	context.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+	inventory := mocks.NewMockInventory(controller)
+	context.Params = []gin.Param{
+		{
+			Key:   artID,
+			Value: "1",
+		},
+	}
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	type args struct {
+		context *gin.Context
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		dbErr      error
+		article    data.Stock
+		statusCode int
+	}{
+		{
+			name:   "article_with_suppliers",
+			fields: fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:   args{context: context},
+			article: data.Stock{ArtId: "1", Name: "leg", Stock: "12", Suppliers: []data.SupplierArticle{
+				{ArtId: "1", SupplierId: 1, SupplierName: "Acme Legs", PartNumber: "AL-1", UnitCost: 2.5, LeadTimeDays: 7, MinOrderQuantity: 100},
+			}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "article_not_found",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			dbErr:      db.ErrArticleNotFound,
+			statusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Body.Reset()
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			inventory.EXPECT().GetArticle(gomock.Any(), "1").Return(tt.dbErr, tt.article)
+
+			server.getArticle(tt.args.context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			if tt.dbErr == nil {
+				var response ResponseProduct
+				byteArr, _ := ioutil.ReadAll(recorder.Body)
+				_ = json.Unmarshal(byteArr, &response)
+				assert.Equal(t, *response.Article, tt.article)
+			}
+		})
+	}
+}
+
+func TestServer_saveSupplierArticle(t *testing.T) {
+	controller := gomock.NewController(t)
+	recorder := httptest.NewRecorder()
+	context, engine := gin.CreateTestContext(recorder)
+	inventory := mocks.NewMockInventory(controller)
+	context.Params = []gin.Param{
+		{
+			Key:   supplierID,
+			Value: "1",
+		},
+		{
+			Key:   artID,
+			Value: "2",
+		},
+	}
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	type args struct {
+		context *gin.Context
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		article    data.SupplierArticle
+		callDB     bool
+		dbErr      error
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "article_saved",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			article:    data.SupplierArticle{PartNumber: "SCR-8", UnitCost: 0.05, LeadTimeDays: 3, MinOrderQuantity: 1000},
+			callDB:     true,
+			statusCode: http.StatusOK,
+			message:    "Article 2 is saved to supplier 1",
+		},
+		{
+			name:       "supplier_not_found",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			article:    data.SupplierArticle{PartNumber: "SCR-8"},
+			callDB:     true,
+			dbErr:      db.ErrSupplierNotFound,
+			statusCode: http.StatusNotFound,
+			message:    "supplier is not found",
+		},
+		{
+			name:       "negative_cost",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			args:       args{context: context},
+			article:    data.SupplierArticle{UnitCost: -1},
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    "unit_cost, lead_time_days and min_order_quantity cannot be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Body.Reset()
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+
+			reqBodyBytes := new(bytes.Buffer)
+			json.NewEncoder(reqBodyBytes).Encode(tt.article)
+			context.Request = &http.Request{Body: ioutil.NopCloser(bytes.NewBuffer(reqBodyBytes.Bytes()))}
+
+			if tt.callDB {
+				expected := tt.article
+				expected.SupplierId = 1
+				expected.ArtId = "2"
+				inventory.EXPECT().SaveSupplierArticle(gomock.Any(), expected).Return(tt.dbErr, expected)
+			}
+
+			server.saveSupplierArticle(tt.args.context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+//tenant scopes the request by the tenant of its credential. The X-Tenant-ID header is checked against it, it names
+//the tenant only when the authorization is disabled. The requests without one are of the default tenant
+func (server *Server) tenant() gin.HandlerFunc {
+	return func(context *gin.Context) {
+		tenant := context.GetHeader(tenantHeader)
+		if tenant != "" && !request.ValidTenant(tenant) {
+			context.Abort()
+			respondError(context, http.StatusBadRequest, ResponseError{
+				Message: fmt.Sprintf("tenant %q is not valid", tenant),
+				RID:     request.GetRID(context.Request.Context()),
+			})
+			return
+		}
+		if value, ok := context.Get(clientTenantKey); ok {
+			clientTenant := value.(string)
+			if clientTenant == "" {
+				clientTenant = request.DefaultTenant
+			}
+			if tenant != "" && tenant != clientTenant {
+				server.requestLog(context).WithField("header", tenant).Info("tenant, credential is of another tenant")
+				context.Abort()
+				respondError(context, http.StatusForbidden, ResponseError{
+					Message: fmt.Sprintf("credential is not valid for tenant %q", tenant),
+					RID:     request.GetRID(context.Request.Context()),
+				})
+				return
+			}
+			tenant = clientTenant
+		}
+		if tenant == "" {
+			tenant = request.DefaultTenant
+		}
+
+		context.Set(tenantKey, tenant)
+		context.Request = context.Request.WithContext(request.WithTenant(context.Request.Context(), tenant))
+	}
+}
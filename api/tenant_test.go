@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/auth"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServer_tenant(t *testing.T) {
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := NewServer(inventory, nil, nil, Configuration{BackendTimeout: "25s"}, logrus.NewEntry(logrus.New()))
+	open := NewServer(inventory, nil, nil, Configuration{BackendTimeout: "25s", AuthDisabled: true}, logrus.NewEntry(logrus.New()))
+
+	tests := []struct {
+		name       string
+		server     *Server
+		key        string
+		keyTenant  string
+		header     string
+		statusCode int
+		tenant     string
+		message    string
+	}{
+		{
+			name:       "tenant_of_the_key",
+			server:     server,
+			key:        "wh_acme",
+			keyTenant:  "acme",
+			statusCode: http.StatusOK,
+			tenant:     "acme",
+		},
+		{
+			name:       "header_of_the_key_tenant",
+			server:     server,
+			key:        "wh_acme",
+			keyTenant:  "acme",
+			header:     "acme",
+			statusCode: http.StatusOK,
+			tenant:     "acme",
+		},
+		{
+			name:       "header_of_another_tenant",
+			server:     server,
+			key:        "wh_acme",
+			keyTenant:  "acme",
+			header:     "globex",
+			statusCode: http.StatusForbidden,
+			message:    `credential is not valid for tenant "globex"`,
+		},
+		{
+			name:       "key_without_tenant",
+			server:     server,
+			key:        "wh_default",
+			header:     "globex",
+			statusCode: http.StatusForbidden,
+			message:    `credential is not valid for tenant "globex"`,
+		},
+		{
+			name:       "invalid_header",
+			server:     open,
+			header:     "Acme Inc",
+			statusCode: http.StatusBadRequest,
+			message:    `tenant "Acme Inc" is not valid`,
+		},
+		{
+			name:       "header_without_authorization",
+			server:     open,
+			header:     "globex",
+			statusCode: http.StatusOK,
+			tenant:     "globex",
+		},
+		{
+			name:       "default_tenant",
+			server:     open,
+			statusCode: http.StatusOK,
+			tenant:     request.DefaultTenant,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.key != "" {
+				inventory.EXPECT().GetActiveAPIKey(gomock.Any(), auth.HashAPIKey(tt.key)).Return(nil, data.APIKey{
+					KeyId: 1, Name: "dashboard", Scopes: []data.Scope{data.ScopeInventoryRead}, TenantId: tt.keyTenant,
+				})
+			}
+			var tenant string
+			if tt.statusCode == http.StatusOK {
+				inventory.EXPECT().GetInventory(gomock.Any()).DoAndReturn(func(ctx context.Context) (error, []data.Stock) {
+					tenant = request.GetTenant(ctx)
+					return nil, []data.Stock{}
+				})
+			}
+
+			recorder := httptest.NewRecorder()
+			httpRequest, _ := http.NewRequest(http.MethodGet, "/warehouse/v1/inventory", nil)
+			if tt.key != "" {
+				httpRequest.Header.Set(apiKeyHeader, tt.key)
+			}
+			if tt.header != "" {
+				httpRequest.Header.Set(tenantHeader, tt.header)
+			}
+			tt.server.router.ServeHTTP(recorder, httpRequest)
+
+			assert.Equal(t, recorder.Code, tt.statusCode)
+			assert.Equal(t, tenant, tt.tenant)
+			if tt.statusCode != http.StatusOK {
+				var response ResponseError
+				_ = json.Unmarshal(recorder.Body.Bytes(), &response)
+				assert.Equal(t, response.Message, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"github.com/auknl/warehouse/request"
+	"github.com/auknl/warehouse/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+//traceRequest creates the span of the request as the child of the incoming W3C traceparent. The trace id becomes the
+//rid of the request unless the client sends X-Request-ID, the span keeps the rid either way
+func (server *Server) traceRequest(context *gin.Context) {
+	ctx := otel.GetTextMapPropagator().Extract(context.Request.Context(), propagation.HeaderCarrier(context.Request.Header))
+	route := context.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	ctx, span := tracing.Tracer().Start(ctx, context.Request.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPMethodKey.String(context.Request.Method),
+			semconv.HTTPRouteKey.String(route),
+			semconv.HTTPTargetKey.String(context.Request.URL.RequestURI()),
+		))
+	defer span.End()
+
+	if request.IDFromContext(ctx) == "" && span.SpanContext().HasTraceID() {
+		ctx = request.WithID(ctx, span.SpanContext().TraceID().String())
+	}
+	context.Request = context.Request.WithContext(ctx)
+
+	context.Next()
+
+	status := context.Writer.Status()
+	span.SetAttributes(
+		attribute.String("rid", request.GetRID(context.Request.Context())),
+		semconv.HTTPStatusCodeKey.Int(status),
+	)
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/instrument"
+	"github.com/auknl/warehouse/request"
+	"github.com/auknl/warehouse/tracing"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServer_traceRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	controller := gomock.NewController(t)
+	inventory := mocks.NewMockInventory(controller)
+	server := NewServer(instrument.NewInventory(inventory, tracing.ObserveQuery), nil, nil,
+		Configuration{BackendTimeout: "25s", AuthDisabled: true}, logrus.NewEntry(logrus.New()))
+
+	var rid string
+	inventory.EXPECT().GetInventory(gomock.Any()).DoAndReturn(func(ctx context.Context) (error, []data.Stock) {
+		rid = request.GetRID(ctx)
+		return nil, []data.Stock{}
+	})
+
+	httpRequest, _ := http.NewRequest(http.MethodGet, "/warehouse/v1/inventory", nil)
+	httpRequest.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	server.router.ServeHTTP(httptest.NewRecorder(), httpRequest)
+
+	spans := recorder.Ended()
+	assert.Equal(t, len(spans), 2)
+	dbSpan, requestSpan := spans[0], spans[1]
+	assert.Equal(t, requestSpan.Name(), "GET /warehouse/v1/inventory")
+	assert.Equal(t, requestSpan.SpanKind(), trace.SpanKindServer)
+	assert.Equal(t, requestSpan.SpanContext().TraceID().String(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Equal(t, requestSpan.Parent().SpanID().String(), "00f067aa0ba902b7")
+	assert.Equal(t, dbSpan.Name(), "db.GetInventory")
+	assert.Equal(t, dbSpan.Parent().SpanID(), requestSpan.SpanContext().SpanID())
+	assert.Equal(t, rid, "4bf92f3577b34da6a3ce929d0e0e4736")
+}
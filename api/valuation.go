@@ -0,0 +1,36 @@
+package api
+
+import (
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+//getValuation provides the value of the stock and the cost of the goods sold by the cost method, FIFO by default
+func (server *Server) getValuation(context *gin.Context) {
+	log := server.requestLog(context)
+	log.Debug("getValuation")
+	method := data.CostMethod(context.DefaultQuery(costMethod, string(data.CostFIFO)))
+	if !method.IsValid() {
+		respondError(context, http.StatusBadRequest, ResponseError{
+			Message: fmt.Sprintf("method %q is not valid, the methods are %s and %s", method, data.CostFIFO, data.CostWeightedAverage),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+
+	err, valuation := server.Inventory.GetValuation(context.Request.Context(), method)
+	if err != nil {
+		respondError(context, http.StatusInternalServerError, ResponseError{
+			Message: err.Error(),
+			RID:     request.GetRID(context.Request.Context()),
+		})
+		return
+	}
+	context.JSON(http.StatusOK, ResponseProduct{
+		Valuation: &valuation,
+	})
+	return
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/auknl/warehouse/api/mocks"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestServer_getValuation(t *testing.T) {
+	controller := gomock.NewController(t)
+	engine := gin.New()
+	inventory := mocks.NewMockInventory(controller)
+
+	type fields struct {
+		Inventory db.Inventory
+		router    *gin.Engine
+		Config    Configuration
+		Logger    *logrus.Entry
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		query      string
+		callDB     bool
+		method     data.CostMethod
+		statusCode int
+		message    string
+	}{
+		{
+			name:       "fifo_by_default",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			query:      "",
+			callDB:     true,
+			method:     data.CostFIFO,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "weighted_average",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			query:      costMethod + "=weighted_average",
+			callDB:     true,
+			method:     data.CostWeightedAverage,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "unknown_method",
+			fields:     fields{Logger: logrus.NewEntry(logrus.New()), router: engine, Inventory: inventory, Config: Configuration{ListenAddress: "localhost:8080", BackendTimeout: "25s"}},
+			query:      costMethod + "=lifo",
+			callDB:     false,
+			statusCode: http.StatusBadRequest,
+			message:    `method "lifo" is not valid, the methods are fifo and weighted_average`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			context, _ := gin.CreateTestContext(recorder)
+			server := &Server{
+				Inventory: tt.fields.Inventory,
+				router:    tt.fields.router,
+				Config:    tt.fields.Config,
+				Logger:    tt.fields.Logger,
+			}
+			context.Request = &http.Request{URL: &url.URL{RawQuery: tt.query}}
+
+			valuation := data.Valuation{
+				Method:          tt.method,
+				Articles:        []data.ArticleValuation{{ArtId: "1", Name: "leg", Quantity: 4, UnitCost: 2.5, Value: 10}},
+				TotalValue:      10,
+				CostOfGoodsSold: 7.5,
+			}
+			if tt.callDB {
+				inventory.EXPECT().GetValuation(gomock.Any(), tt.method).Return(nil, valuation)
+			}
+
+			server.getValuation(context)
+
+			assert.Equal(t, tt.statusCode, context.Writer.Status())
+			var response ResponseProduct
+			byteArr, _ := ioutil.ReadAll(recorder.Body)
+			_ = json.Unmarshal(byteArr, &response)
+			assert.Equal(t, response.Message, tt.message)
+			if tt.callDB {
+				assert.Equal(t, *response.Valuation, valuation)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+)
+
+//apiKeyPrefix marks the API keys of the service, so that a leaked key can be recognized
+const apiKeyPrefix = "wh_"
+
+//apiKeyBytes is the entropy of an API key
+const apiKeyBytes = 32
+
+//prefixLength is the length of the part of the key kept in clear to tell the keys apart
+const prefixLength = 8
+
+//GenerateAPIKey creates a random API key and returns it with its prefix and hash, only the prefix and the hash are stored
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	random := make([]byte, apiKeyBytes)
+	_, err = rand.Read(random)
+	if err != nil {
+		return "", "", "", err
+	}
+	key = apiKeyPrefix + hex.EncodeToString(random)
+	return key, key[:len(apiKeyPrefix)+prefixLength], HashAPIKey(key), nil
+}
+
+//HashAPIKey gives the hash the API key is stored and looked up with. The keys are random, so a fast hash is enough
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+//NewAPIKey validates the name and the scopes and generates the key to be created, the key is returned only here
+func NewAPIKey(name string, scopes []data.Scope) (data.APIKey, error) {
+	if name == "" {
+		return data.APIKey{}, errors.New("API key name is required")
+	}
+	if len(scopes) == 0 {
+		return data.APIKey{}, errors.New("API key needs at least one scope")
+	}
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return data.APIKey{}, fmt.Errorf("scope %q is not valid", scope)
+		}
+	}
+	key, prefix, hash, err := GenerateAPIKey()
+	if err != nil {
+		return data.APIKey{}, err
+	}
+	return data.APIKey{Name: name, Key: key, Prefix: prefix, KeyHash: hash, Scopes: scopes}, nil
+}
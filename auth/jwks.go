@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+//jwksRefreshInterval is the least time between two loads of the key set, a token with an unknown key id
+//loads the key set again to pick up rotated keys
+const jwksRefreshInterval = time.Minute
+
+//jwksFetchTimeout is the timeout of fetching the key set from a url
+const jwksFetchTimeout = 10 * time.Second
+
+//ErrKeyNotFound is returned when the key set has no key with the key id of the token
+var ErrKeyNotFound = errors.New("signing key is not found in the key set")
+
+//errUnsupportedKey is returned for the keys of a type or a curve that is not used to verify the tokens
+var errUnsupportedKey = errors.New("key is not supported")
+
+//jwk is a key of a JSON Web Key Set, only the RSA and EC signing keys are used
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+//KeySet keeps the public keys of a JSON Web Key Set loaded from a file or a url
+type KeySet struct {
+	source   string
+	logger   *logrus.Entry
+	client   *http.Client
+	mu       sync.Mutex
+	keys     map[string]crypto.PublicKey
+	loadedAt time.Time
+	loading  *keySetLoad
+}
+
+//keySetLoad is a load of the key set in progress, the lookups of an unknown key id wait for it instead of loading
+//the key set again
+type keySetLoad struct {
+	done chan struct{}
+	err  error
+}
+
+//LoadKeySet loads the key set from the source, an http(s) url or a local file
+func LoadKeySet(source string, logger *logrus.Entry) (*KeySet, error) {
+	keySet := &KeySet{
+		source: source,
+		logger: logger.WithField("jwks", source),
+		client: &http.Client{Timeout: jwksFetchTimeout},
+	}
+	keys, err := keySet.load()
+	if err != nil {
+		return nil, err
+	}
+	keySet.keys = keys
+	keySet.loadedAt = time.Now()
+	return keySet, nil
+}
+
+//Key gets the public key with the key id, the key set is loaded again once per interval for an unknown id. The lock
+//is not held while the key set loads, so the known keys are served during the load
+func (keySet *KeySet) Key(kid string) (crypto.PublicKey, error) {
+	keySet.mu.Lock()
+	key, ok := keySet.keys[kid]
+	if ok {
+		keySet.mu.Unlock()
+		return key, nil
+	}
+	load := keySet.loading
+	if load == nil && time.Since(keySet.loadedAt) < jwksRefreshInterval {
+		keySet.mu.Unlock()
+		return nil, ErrKeyNotFound
+	}
+	if load == nil {
+		load = &keySetLoad{done: make(chan struct{})}
+		keySet.loading = load
+		keySet.loadedAt = time.Now()
+		keySet.mu.Unlock()
+		keySet.reload(load)
+	} else {
+		keySet.mu.Unlock()
+	}
+
+	<-load.done
+	if load.err != nil {
+		return nil, load.err
+	}
+	keySet.mu.Lock()
+	key, ok = keySet.keys[kid]
+	keySet.mu.Unlock()
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+//reload loads the key set without holding the lock and replaces the keys under it, the keys are kept if the load
+//fails. The waiting lookups are released when it ends
+func (keySet *KeySet) reload(load *keySetLoad) {
+	keys, err := keySet.load()
+	keySet.mu.Lock()
+	if err == nil {
+		keySet.keys = keys
+	}
+	load.err = err
+	keySet.loading = nil
+	keySet.mu.Unlock()
+	close(load.done)
+}
+
+//load reads the key set from the source and gives its keys. The keys of a type or a curve that is not supported
+//and the invalid keys are skipped, so one of them does not fail the other keys of the set
+func (keySet *KeySet) load() (map[string]crypto.PublicKey, error) {
+	body, err := keySet.read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the key set %s, %w", keySet.source, err)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	err = json.Unmarshal(body, &set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key set %s, %w", keySet.source, err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		publicKey, err := key.publicKey()
+		if errors.Is(err, errUnsupportedKey) {
+			keySet.logger.WithField("kid", key.Kid).WithField("err", err).Debug("load, the key is skipped")
+			continue
+		}
+		if err != nil {
+			keySet.logger.WithField("kid", key.Kid).WithField("err", err).Warn("load, the invalid key is skipped")
+			continue
+		}
+		keys[key.Kid] = publicKey
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("key set %s has no supported signing key", keySet.source)
+	}
+	return keys, nil
+}
+
+func (keySet *KeySet) read() ([]byte, error) {
+	if !strings.HasPrefix(keySet.source, "http://") && !strings.HasPrefix(keySet.source, "https://") {
+		return ioutil.ReadFile(keySet.source)
+	}
+	response, err := keySet.client.Get(keySet.source)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	return ioutil.ReadAll(response.Body)
+}
+
+//publicKey converts the key to an rsa or ecdsa public key
+func (key jwk) publicKey() (crypto.PublicKey, error) {
+	switch key.Kty {
+	case "RSA":
+		n, err := decodeBigInt(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(key.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch key.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("%w, curve %q", errUnsupportedKey, key.Crv)
+		}
+		x, err := decodeBigInt(key.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(key.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("%w, key type %q", errUnsupportedKey, key.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, errors.New("key parameter is missing")
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(decoded), nil
+}
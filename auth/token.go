@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/golang-jwt/jwt/v4"
+	"strings"
+	"time"
+)
+
+//signingMethods are the accepted token algorithms, the keys of a key set are asymmetric
+var signingMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+//defaultRoleClaim is the claim with the roles of the user when no claim is configured
+const defaultRoleClaim = "roles"
+
+//defaultTenantClaim is the claim with the tenant of the user when no claim is configured
+const defaultTenantClaim = "tenant"
+
+//TokenConfig keeps the expectations of the tokens, Issuer and Audience are checked when they are set
+type TokenConfig struct {
+	Issuer    string
+	Audience  string
+	RoleClaim string
+	//TenantClaim is the claim with the tenant of the user, the users without one belong to the default tenant
+	TenantClaim string
+}
+
+//Principal is the user of a verified token
+type Principal struct {
+	Subject string
+	Roles   []data.Role
+	Scopes  []data.Scope
+	Tenant  string
+}
+
+//Verifier verifies the bearer tokens against the keys of a key set
+type Verifier struct {
+	keys   *KeySet
+	config TokenConfig
+	parser *jwt.Parser
+}
+
+//NewVerifier creates a verifier of the tokens signed by the keys of the key set
+func NewVerifier(keys *KeySet, config TokenConfig) *Verifier {
+	if config.RoleClaim == "" {
+		config.RoleClaim = defaultRoleClaim
+	}
+	if config.TenantClaim == "" {
+		config.TenantClaim = defaultTenantClaim
+	}
+	return &Verifier{
+		keys:   keys,
+		config: config,
+		parser: jwt.NewParser(jwt.WithValidMethods(signingMethods)),
+	}
+}
+
+//Verify checks the signature, the expiry, the issuer and the audience of the token and maps its roles to scopes
+func (verifier *Verifier) Verify(token string) (Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := verifier.parser.ParseWithClaims(token, claims, verifier.key)
+	if err != nil {
+		return Principal{}, err
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return Principal{}, errors.New("token has no expiry or is expired")
+	}
+	if verifier.config.Issuer != "" && !claims.VerifyIssuer(verifier.config.Issuer, true) {
+		return Principal{}, errors.New("token issuer is not accepted")
+	}
+	if verifier.config.Audience != "" && !claims.VerifyAudience(verifier.config.Audience, true) {
+		return Principal{}, errors.New("token audience is not accepted")
+	}
+
+	subject, _ := claims["sub"].(string)
+	tenant, _ := claims[verifier.config.TenantClaim].(string)
+	if tenant == "" {
+		tenant = request.DefaultTenant
+	}
+	if !request.ValidTenant(tenant) {
+		return Principal{}, fmt.Errorf("token tenant %q is not valid", tenant)
+	}
+	roles := claimRoles(claims[verifier.config.RoleClaim])
+	return Principal{Subject: subject, Roles: roles, Scopes: data.ScopesOf(roles), Tenant: tenant}, nil
+}
+
+//key gets the key of the token from the key set by its key id
+func (verifier *Verifier) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no key id")
+	}
+	key, err := verifier.keys.Key(kid)
+	if err != nil {
+		return nil, fmt.Errorf("key %q, %w", kid, err)
+	}
+	return key, nil
+}
+
+//claimRoles reads the roles from a list or a space separated string claim
+func claimRoles(claim interface{}) []data.Role {
+	var roles []data.Role
+	switch value := claim.(type) {
+	case string:
+		for _, role := range strings.Fields(value) {
+			roles = append(roles, data.Role(role))
+		}
+	case []interface{}:
+		for _, role := range value {
+			if role, ok := role.(string); ok {
+				roles = append(roles, data.Role(role))
+			}
+		}
+	}
+	return roles
+}
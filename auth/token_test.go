@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+//writeKeySet writes a key set with an RSA and an EC key to a file
+func writeKeySet(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	set := map[string][]map[string]string{"keys": {
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": encodeBigInt(rsaKey.N), "e": encodeBigInt(big.NewInt(int64(rsaKey.E)))},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encodeBigInt(ecKey.X), "y": encodeBigInt(ecKey.Y)},
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": encodeBigInt(rsaKey.N), "e": "AQAB"},
+	}}
+	body, _ := json.Marshal(set)
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	err := ioutil.WriteFile(file, body, 0600)
+	assert.Equal(t, err, nil)
+	return file
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keys, err := LoadKeySet(writeKeySet(t, rsaKey, ecKey), logrus.NewEntry(logrus.New()))
+	assert.Equal(t, err, nil)
+	verifier := NewVerifier(keys, TokenConfig{Issuer: "https://id.example.test", Audience: "warehouse"})
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "alice",
+			"iss":   "https://id.example.test",
+			"aud":   []string{"warehouse", "billing"},
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"clerk"},
+		}
+	}
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		assert.Equal(t, err, nil)
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		valid   bool
+		subject string
+		roles   []data.Role
+		tenant  string
+	}{
+		{
+			name:    "rsa_token",
+			token:   sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, valid()),
+			valid:   true,
+			subject: "alice",
+			roles:   []data.Role{data.RoleClerk},
+			tenant:  request.DefaultTenant,
+		},
+		{
+			name: "ec_token_with_string_roles",
+			token: sign(jwt.SigningMethodES256, "ec-1", ecKey, func() jwt.MapClaims {
+				claims := valid()
+				claims["roles"] = "viewer admin"
+				return claims
+			}()),
+			valid:   true,
+			subject: "alice",
+			roles:   []data.Role{data.RoleViewer, data.RoleAdmin},
+			tenant:  request.DefaultTenant,
+		},
+		{
+			name: "tenant_claim",
+			token: sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, func() jwt.MapClaims {
+				claims := valid()
+				claims["tenant"] = "acme"
+				return claims
+			}()),
+			valid:   true,
+			subject: "alice",
+			roles:   []data.Role{data.RoleClerk},
+			tenant:  "acme",
+		},
+		{
+			name: "invalid_tenant",
+			token: sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, func() jwt.MapClaims {
+				claims := valid()
+				claims["tenant"] = "Acme Inc"
+				return claims
+			}()),
+		},
+		{
+			name: "expired",
+			token: sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, func() jwt.MapClaims {
+				claims := valid()
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+				return claims
+			}()),
+		},
+		{
+			name: "no_expiry",
+			token: sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, func() jwt.MapClaims {
+				claims := valid()
+				delete(claims, "exp")
+				return claims
+			}()),
+		},
+		{
+			name: "other_issuer",
+			token: sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, func() jwt.MapClaims {
+				claims := valid()
+				claims["iss"] = "https://other.example.test"
+				return claims
+			}()),
+		},
+		{
+			name: "other_audience",
+			token: sign(jwt.SigningMethodRS256, "rsa-1", rsaKey, func() jwt.MapClaims {
+				claims := valid()
+				claims["aud"] = "billing"
+				return claims
+			}()),
+		},
+		{
+			name:  "unknown_key_id",
+			token: sign(jwt.SigningMethodRS256, "rsa-2", rsaKey, valid()),
+		},
+		{
+			name:  "encryption_key",
+			token: sign(jwt.SigningMethodRS256, "enc-1", rsaKey, valid()),
+		},
+		{
+			name:  "no_key_id",
+			token: sign(jwt.SigningMethodRS256, "", rsaKey, valid()),
+		},
+		{
+			name:  "symmetric_algorithm",
+			token: sign(jwt.SigningMethodHS256, "rsa-1", []byte("secret"), valid()),
+		},
+		{
+			name:  "not_a_token",
+			token: "not.a.token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := verifier.Verify(tt.token)
+			assert.Equal(t, err == nil, tt.valid)
+			if tt.valid {
+				assert.Equal(t, principal.Subject, tt.subject)
+				assert.Equal(t, principal.Roles, tt.roles)
+				assert.Equal(t, principal.Scopes, data.ScopesOf(tt.roles))
+				assert.Equal(t, principal.Tenant, tt.tenant)
+			}
+		})
+	}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	logger := logrus.NewEntry(logrus.New())
+	_, err := LoadKeySet(filepath.Join(t.TempDir(), "missing.json"), logger)
+	assert.NotEqual(t, err, nil)
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	_ = ioutil.WriteFile(file, []byte(`{"keys":[{"kty":"oct","kid":"hmac-1","k":"c2VjcmV0"}]}`), 0600)
+	_, err = LoadKeySet(file, logger)
+	assert.NotEqual(t, err, nil)
+
+	//the unsupported and the invalid keys of a mixed set are skipped
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	set := map[string][]map[string]string{"keys": {
+		{"kty": "oct", "kid": "hmac-1", "k": "c2VjcmV0"},
+		{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+		{"kty": "EC", "kid": "ec-k", "crv": "secp256k1", "x": "AQ", "y": "AQ"},
+		{"kty": "RSA", "kid": "rsa-broken", "n": "", "e": "AQAB"},
+		{"kty": "RSA", "kid": "rsa-1", "n": encodeBigInt(rsaKey.N), "e": "AQAB"},
+	}}
+	body, _ := json.Marshal(set)
+	_ = ioutil.WriteFile(file, body, 0600)
+	keys, err := LoadKeySet(file, logger)
+	assert.Equal(t, err, nil)
+	_, err = keys.Key("rsa-1")
+	assert.Equal(t, err, nil)
+	_, err = keys.Key("hmac-1")
+	assert.Equal(t, err, ErrKeyNotFound)
+}
+
+func TestKeySet_KeyDuringLoad(t *testing.T) { //The known keys are served while the key set loads again
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	key := func(kid string) map[string]string {
+		return map[string]string{"kty": "RSA", "kid": kid, "n": encodeBigInt(rsaKey.N), "e": "AQAB"}
+	}
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		fetches++
+		set := map[string][]map[string]string{"keys": {key("rsa-1")}}
+		if fetches > 1 {
+			close(fetching)
+			<-release
+			set["keys"] = append(set["keys"], key("rsa-2"))
+		}
+		_ = json.NewEncoder(writer).Encode(set)
+	}))
+	defer server.Close()
+
+	keys, err := LoadKeySet(server.URL, logrus.NewEntry(logrus.New()))
+	assert.Equal(t, err, nil)
+	keys.loadedAt = time.Now().Add(-jwksRefreshInterval)
+
+	rotated := make(chan error)
+	go func() {
+		_, err := keys.Key("rsa-2")
+		rotated <- err
+	}()
+	<-fetching
+	_, err = keys.Key("rsa-1")
+	assert.Equal(t, err, nil)
+	close(release)
+	assert.Equal(t, <-rotated, nil)
+	assert.Equal(t, fetches, 2)
+}
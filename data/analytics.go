@@ -0,0 +1,91 @@
+package data
+
+import "time"
+
+//SalesRanking is what the top products are ranked by
+type SalesRanking string
+
+const (
+	RankByUnits   SalesRanking = "units"
+	RankByRevenue SalesRanking = "revenue"
+)
+
+//IsValid checks if the ranking is one of the known rankings
+func (ranking SalesRanking) IsValid() bool {
+	switch ranking {
+	case RankByUnits, RankByRevenue:
+		return true
+	}
+	return false
+}
+
+//SeriesInterval is the length of a period of the sales time series
+type SeriesInterval string
+
+const (
+	IntervalDay   SeriesInterval = "day"
+	IntervalWeek  SeriesInterval = "week"
+	IntervalMonth SeriesInterval = "month"
+)
+
+//IsValid checks if the interval is one of the known intervals
+func (interval SeriesInterval) IsValid() bool {
+	switch interval {
+	case IntervalDay, IntervalWeek, IntervalMonth:
+		return true
+	}
+	return false
+}
+
+//Start gets the start of the period the time is in, in UTC. The weeks start on Monday
+func (interval SeriesInterval) Start(t time.Time) time.Time {
+	t = t.UTC()
+	switch interval {
+	case IntervalWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+//Next gets the start of the period after the one starting at the given time
+func (interval SeriesInterval) Next(start time.Time) time.Time {
+	switch interval {
+	case IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+//TopProduct is a product ranked by its sales. Revenue is per currency, the sales without a price have no revenue
+type TopProduct struct {
+	Rank        int                `json:"rank"`
+	ProductName string             `json:"product_name"`
+	Quantity    int                `json:"quantity"`
+	Revenue     map[string]float64 `json:"revenue,omitempty"`
+}
+
+//SeriesPoint is the quantity sold and the revenue per currency in the period starting at Period
+type SeriesPoint struct {
+	Period   time.Time          `json:"period"`
+	Quantity int                `json:"quantity"`
+	Revenue  map[string]float64 `json:"revenue,omitempty"`
+}
+
+//ProductSeries is the sales of a product over time, one point per period
+type ProductSeries struct {
+	ProductName string        `json:"product_name"`
+	Points      []SeriesPoint `json:"points"`
+}
+
+//SellThrough is the share of the units sold in a period among the units sold and the units still available
+type SellThrough struct {
+	ProductName string  `json:"product_name"`
+	Sold        int     `json:"sold"`
+	Available   int     `json:"available"`
+	Rate        float64 `json:"rate"`
+}
@@ -0,0 +1,62 @@
+package data
+
+import "time"
+
+//Scope is a permission of an API key
+type Scope string
+
+const (
+	ScopeInventoryRead  Scope = "inventory:read"
+	ScopeInventoryWrite Scope = "inventory:write"
+	ScopeProductSell    Scope = "product:sell"
+	ScopeOrderRead      Scope = "order:read"
+	ScopeOrderWrite     Scope = "order:write"
+	ScopePurchaseRead   Scope = "purchase:read"
+	ScopePurchaseWrite  Scope = "purchase:write"
+	ScopeSalesRead      Scope = "sales:read"
+	ScopePriceWrite     Scope = "price:write"
+	ScopeAdmin          Scope = "admin"
+)
+
+//Scopes are all the scopes an API key can have
+var Scopes = []Scope{ScopeInventoryRead, ScopeInventoryWrite, ScopeProductSell, ScopeOrderRead, ScopeOrderWrite,
+	ScopePurchaseRead, ScopePurchaseWrite, ScopeSalesRead, ScopePriceWrite, ScopeAdmin}
+
+//IsValid checks if the scope is known
+func (scope Scope) IsValid() bool {
+	for _, known := range Scopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+//APIKey is a key of a client. Only the hash of the key is stored, the key is given once when it is created
+type APIKey struct {
+	KeyId     int        `json:"key_id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix,omitempty"`
+	Key       string     `json:"key,omitempty"`
+	KeyHash   string     `json:"-"`
+	Scopes    []Scope    `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	//TenantId is the tenant the key belongs to, its requests see only the data of the tenant
+	TenantId string `json:"tenant_id"`
+}
+
+//HasScope checks if the key has the scope
+func (key APIKey) HasScope(scope Scope) bool {
+	return HasScope(key.Scopes, scope)
+}
+
+//HasScope checks if the scope is granted, the admin scope has all of them
+func HasScope(granted []Scope, scope Scope) bool {
+	for _, g := range granted {
+		if g == scope || g == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package data
+
+//ScanDirection tells if a scanned article is put into or taken out of the stock
+type ScanDirection string
+
+const (
+	ScanIn  ScanDirection = "in"
+	ScanOut ScanDirection = "out"
+)
+
+//Scan is a single scan event of a handheld scanner
+type Scan struct {
+	Direction ScanDirection `json:"direction"`
+}
+
+//ScanResult is the article or the product a barcode belongs to
+type ScanResult struct {
+	Barcode string        `json:"barcode"`
+	Article *Stock        `json:"article,omitempty"`
+	Product *ProductStock `json:"product,omitempty"`
+}
+
+//ValidGTIN checks the length and the check digit of a GTIN-8, GTIN-12 (UPC), GTIN-13 (EAN) or GTIN-14 barcode
+func ValidGTIN(barcode string) bool {
+	switch len(barcode) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < len(barcode); i++ {
+		digit := int(barcode[i] - '0')
+		if digit < 0 || digit > 9 {
+			return false
+		}
+		if i == len(barcode)-1 {
+			break
+		}
+		// weights are 3 and 1 alternately, starting with 3 at the digit next to the check digit
+		if (len(barcode)-1-i)%2 == 1 {
+			sum += digit * 3
+		} else {
+			sum += digit
+		}
+	}
+	checkDigit := (10 - sum%10) % 10
+	return checkDigit == int(barcode[len(barcode)-1]-'0')
+}
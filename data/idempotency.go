@@ -0,0 +1,10 @@
+package data
+
+//IdempotentResponse keeps the stored response of a request executed with an idempotency key
+type IdempotentResponse struct {
+	Key        string
+	Method     string
+	Path       string
+	StatusCode int
+	Body       []byte
+}
@@ -0,0 +1,55 @@
+package data
+
+import "time"
+
+//ImportKind tells what an import job uploads
+type ImportKind string
+
+const (
+	ImportInventory ImportKind = "inventory"
+	ImportProducts  ImportKind = "products"
+)
+
+//JobState is the state of an import job
+type JobState string
+
+const (
+	JobQueued             JobState = "queued"
+	JobRunning            JobState = "running"
+	JobSucceeded          JobState = "succeeded"
+	JobPartiallySucceeded JobState = "partially_succeeded"
+	JobFailed             JobState = "failed"
+)
+
+//IsFinished checks if the job will not be processed anymore
+func (state JobState) IsFinished() bool {
+	return state == JobSucceeded || state == JobPartiallySucceeded || state == JobFailed
+}
+
+//RecordError is the reason a record of an import job is not uploaded
+type RecordError struct {
+	Record  int    `json:"record"`
+	Message string `json:"message"`
+}
+
+//ImportJob is an upload processed in the background
+type ImportJob struct {
+	JobId int        `json:"job_id"`
+	Kind  ImportKind `json:"kind"`
+	State JobState   `json:"state"`
+	//Total is the number of records in the upload, Processed the ones committed or failed so far
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	//Records and Batches are the committed ones
+	Records   int           `json:"records"`
+	Batches   int           `json:"batches"`
+	Errors    []RecordError `json:"errors,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	//Payload is the upload with one json record per line, it is dropped when the job is finished
+	Payload []byte `json:"-"`
+	//Worker is the lease of the process running the job
+	Worker string `json:"-"`
+	//TenantId is the tenant the job imports into
+	TenantId string `json:"-"`
+}
@@ -0,0 +1,64 @@
+package data
+
+import "time"
+
+//OrderStatus is the state of an order in its lifecycle
+type OrderStatus string
+
+const (
+	OrderCreated   OrderStatus = "created"
+	OrderAllocated OrderStatus = "allocated"
+	OrderPicked    OrderStatus = "picked"
+	OrderShipped   OrderStatus = "shipped"
+	OrderCancelled OrderStatus = "cancelled"
+)
+
+//orderTransitions keeps the states an order can move to from each state
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderCreated:   {OrderAllocated, OrderCancelled},
+	OrderAllocated: {OrderPicked, OrderCancelled},
+	OrderPicked:    {OrderShipped, OrderCancelled},
+	OrderShipped:   {},
+	OrderCancelled: {},
+}
+
+//IsValid checks if the status is one of the known order states
+func (status OrderStatus) IsValid() bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+//CanTransitionTo checks if an order in this status can move to the next status
+func (status OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[status] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+//HoldsStock checks if the articles of an order in this status are allocated in the inventory
+func (status OrderStatus) HoldsStock() bool {
+	return status == OrderAllocated || status == OrderPicked
+}
+
+//OrderLine is the ordered quantity of a product
+type OrderLine struct {
+	ProductName string `json:"product_name,omitempty"`
+	Quantity    int    `json:"quantity,omitempty"`
+}
+
+//Order represents a customer order
+type Order struct {
+	OrderId   int         `json:"order_id,omitempty"`
+	Status    OrderStatus `json:"status,omitempty"`
+	Lines     []OrderLine `json:"lines,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+//OrderStatusChange is the request to move an order to a new status
+type OrderStatusChange struct {
+	Status OrderStatus `json:"status"`
+}
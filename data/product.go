@@ -10,6 +10,7 @@ type ArticleContain struct {
 type Product struct {
 	Name            string           `json:"name,omitempty"`
 	ContainArticles []ArticleContain `json:"contain_articles,omitempty"`
+	Barcodes        []string         `json:"barcodes,omitempty"`
 }
 
 // Products represents all products
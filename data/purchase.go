@@ -0,0 +1,55 @@
+package data
+
+import "time"
+
+//PurchaseOrderStatus is the state of a purchase order
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderOpen              PurchaseOrderStatus = "open"
+	PurchaseOrderPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderReceived          PurchaseOrderStatus = "received"
+	PurchaseOrderClosed            PurchaseOrderStatus = "closed"
+)
+
+//IsValid checks if the status is one of the known purchase order states
+func (status PurchaseOrderStatus) IsValid() bool {
+	switch status {
+	case PurchaseOrderOpen, PurchaseOrderPartiallyReceived, PurchaseOrderReceived, PurchaseOrderClosed:
+		return true
+	}
+	return false
+}
+
+//PurchaseOrderLine is the expected and received quantity of an article. Outstanding is the quantity that is
+//not delivered yet and OverDelivered is the quantity received more than expected
+type PurchaseOrderLine struct {
+	ArtId         string `json:"art_id,omitempty"`
+	Expected      int    `json:"expected,omitempty"`
+	Received      int    `json:"received"`
+	Outstanding   int    `json:"outstanding"`
+	OverDelivered int    `json:"over_delivered"`
+}
+
+//PurchaseOrder represents the articles ordered from a supplier
+type PurchaseOrder struct {
+	PurchaseOrderId int                 `json:"purchase_order_id,omitempty"`
+	Status          PurchaseOrderStatus `json:"status,omitempty"`
+	Lines           []PurchaseOrderLine `json:"lines,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+//ReceiptLine is the delivered quantity of an article and its unit cost
+type ReceiptLine struct {
+	ArtId    string  `json:"art_id,omitempty"`
+	Quantity int     `json:"quantity,omitempty"`
+	UnitCost float64 `json:"unit_cost,omitempty"`
+}
+
+//GoodsReceipt represents a delivery against a purchase order
+type GoodsReceipt struct {
+	ReceiptId       int           `json:"receipt_id,omitempty"`
+	PurchaseOrderId int           `json:"purchase_order_id,omitempty"`
+	Lines           []ReceiptLine `json:"lines,omitempty"`
+}
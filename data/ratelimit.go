@@ -0,0 +1,31 @@
+package data
+
+import (
+	"math"
+	"time"
+)
+
+//RateLimit is a token bucket, Burst requests can be made at once and Rate requests per second refill it
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+//RateDecision tells if a request is allowed by its rate limit, RetryAfter is the wait for the next token otherwise
+type RateDecision struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+//Take refills the tokens left in the bucket for the time elapsed since the last take and takes one if there is one.
+//The tokens left after it are returned with the decision
+func (limit RateLimit) Take(tokens float64, elapsed time.Duration) (float64, RateDecision) {
+	tokens = math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+	if tokens >= 1 {
+		tokens--
+		return tokens, RateDecision{Allowed: true, Remaining: int(tokens)}
+	}
+	wait := (1 - tokens) / limit.Rate
+	return tokens, RateDecision{RetryAfter: time.Duration(wait * float64(time.Second))}
+}
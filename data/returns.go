@@ -0,0 +1,25 @@
+package data
+
+//DamagedArticle is the amount of an article that comes back damaged and is not restocked
+type DamagedArticle struct {
+	ArtId  string `json:"art_id,omitempty"`
+	Amount int    `json:"amount,omitempty"`
+}
+
+//ReturnArticle is the result of a return per article of the product
+type ReturnArticle struct {
+	ArtId     string `json:"art_id"`
+	Restocked int    `json:"restocked"`
+	Damaged   int    `json:"damaged"`
+}
+
+//ProductReturn represents the return of sold products
+type ProductReturn struct {
+	ReturnId        int              `json:"return_id,omitempty"`
+	ProductName     string           `json:"product_name,omitempty"`
+	Quantity        int              `json:"quantity,omitempty"`
+	SaleId          int              `json:"sale_id,omitempty"`
+	Reason          string           `json:"reason,omitempty"`
+	DamagedArticles []DamagedArticle `json:"damaged_articles,omitempty"`
+	Articles        []ReturnArticle  `json:"articles,omitempty"`
+}
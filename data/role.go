@@ -0,0 +1,27 @@
+package data
+
+//Role is a role of a user authenticated with a token, it grants the scopes of the role
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleClerk  Role = "clerk"
+	RoleAdmin  Role = "admin"
+)
+
+//RoleScopes are the scopes each role grants
+var RoleScopes = map[Role][]Scope{
+	RoleViewer: {ScopeInventoryRead, ScopeOrderRead, ScopePurchaseRead, ScopeSalesRead},
+	RoleClerk: {ScopeInventoryRead, ScopeOrderRead, ScopePurchaseRead, ScopeSalesRead,
+		ScopeInventoryWrite, ScopeProductSell, ScopeOrderWrite, ScopePurchaseWrite},
+	RoleAdmin: {ScopeAdmin},
+}
+
+//ScopesOf gets the scopes granted by the roles, unknown roles grant nothing
+func ScopesOf(roles []Role) []Scope {
+	var scopes []Scope
+	for _, role := range roles {
+		scopes = append(scopes, RoleScopes[role]...)
+	}
+	return scopes
+}
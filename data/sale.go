@@ -0,0 +1,45 @@
+package data
+
+import "time"
+
+//Price is the selling price of one product
+type Price struct {
+	ProductName string    `json:"product_name,omitempty"`
+	Price       float64   `json:"price"`
+	Currency    string    `json:"currency,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+//Sale is a sale of a product at the price it had at that moment. The unit price and the currency are empty when the
+//product had no price
+type Sale struct {
+	SaleId      int       `json:"sale_id"`
+	ProductName string    `json:"product_name"`
+	Quantity    int       `json:"quantity"`
+	UnitPrice   float64   `json:"unit_price,omitempty"`
+	Currency    string    `json:"currency,omitempty"`
+	SoldAt      time.Time `json:"sold_at"`
+}
+
+//SalesFilter narrows the sales down to a product, a currency and a period, the empty fields are not filtered
+type SalesFilter struct {
+	ProductName string
+	Currency    string
+	From        *time.Time
+	To          *time.Time
+}
+
+//ProductRevenue is the quantity sold and the revenue of a product in one currency
+type ProductRevenue struct {
+	ProductName string  `json:"product_name"`
+	Currency    string  `json:"currency,omitempty"`
+	Quantity    int     `json:"quantity"`
+	Revenue     float64 `json:"revenue"`
+}
+
+//SalesRevenue is the revenue per product in a period
+type SalesRevenue struct {
+	From     *time.Time       `json:"from,omitempty"`
+	To       *time.Time       `json:"to,omitempty"`
+	Products []ProductRevenue `json:"products"`
+}
@@ -0,0 +1,14 @@
+package data
+
+//SchemaStatus is the version of the database schema and the latest version of the migrations. Dirty is set when a
+//migration failed halfway, it has to be fixed by hand
+type SchemaStatus struct {
+	Version uint `json:"version"`
+	Latest  uint `json:"latest"`
+	Dirty   bool `json:"dirty"`
+}
+
+//Behind checks if the schema misses migrations or is left dirty by a failed one
+func (status SchemaStatus) Behind() bool {
+	return status.Dirty || status.Version < status.Latest
+}
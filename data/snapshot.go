@@ -0,0 +1,14 @@
+package data
+
+import "time"
+
+//SnapshotVersion is the format version of the snapshots written by this release
+const SnapshotVersion = 1
+
+//Snapshot is a consistent export of all articles with their stock and all products
+type Snapshot struct {
+	Version  int       `json:"version"`
+	TakenAt  time.Time `json:"taken_at"`
+	Articles []Stock   `json:"articles"`
+	Products []Product `json:"products"`
+}
@@ -1,10 +1,30 @@
 package data
 
+//Dimensions is the size of an article in centimeters
+type Dimensions struct {
+	LengthCm float64 `json:"length_cm,omitempty"`
+	WidthCm  float64 `json:"width_cm,omitempty"`
+	HeightCm float64 `json:"height_cm,omitempty"`
+}
+
 //Stock the inventory info per item
 type Stock struct {
 	ArtId string `json:"art_id,omitempty"`
 	Name  string `json:"name,omitempty"`
 	Stock string `json:"stock,omitempty"`
+	//OnOrder is the quantity expected from the open purchase orders
+	OnOrder int `json:"on_order,omitempty"`
+	//master data of the article, all optional
+	Unit       string            `json:"unit,omitempty"`
+	WeightKg   float64           `json:"weight_kg,omitempty"`
+	Dimensions *Dimensions       `json:"dimensions,omitempty"`
+	Barcodes   []string          `json:"barcodes,omitempty"`
+	Category   string            `json:"category,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	//UnitCost is the cost of the uploaded stock, when the article is read it is the weighted average cost
+	UnitCost float64 `json:"unit_cost,omitempty"`
+	//Suppliers is only filled in the article detail
+	Suppliers []SupplierArticle `json:"suppliers,omitempty"`
 }
 
 //type StockList []Stock
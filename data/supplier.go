@@ -0,0 +1,21 @@
+package data
+
+//SupplierArticle is the purchasing info of an article from a supplier
+type SupplierArticle struct {
+	ArtId            string  `json:"art_id,omitempty"`
+	SupplierId       int     `json:"supplier_id,omitempty"`
+	SupplierName     string  `json:"supplier_name,omitempty"`
+	PartNumber       string  `json:"part_number,omitempty"`
+	UnitCost         float64 `json:"unit_cost,omitempty"`
+	LeadTimeDays     int     `json:"lead_time_days,omitempty"`
+	MinOrderQuantity int     `json:"min_order_quantity,omitempty"`
+}
+
+//Supplier represents a supplier and the articles that can be purchased from it
+type Supplier struct {
+	SupplierId int               `json:"supplier_id,omitempty"`
+	Name       string            `json:"name,omitempty"`
+	Email      string            `json:"email,omitempty"`
+	Phone      string            `json:"phone,omitempty"`
+	Articles   []SupplierArticle `json:"articles,omitempty"`
+}
@@ -0,0 +1,7 @@
+package data
+
+//UploadProgress is the number of records and batches committed by a streaming upload
+type UploadProgress struct {
+	Records int `json:"records"`
+	Batches int `json:"batches"`
+}
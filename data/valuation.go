@@ -0,0 +1,37 @@
+package data
+
+//CostMethod is how the cost of the stock and of the goods sold is calculated
+type CostMethod string
+
+const (
+	//CostFIFO takes the stock out of the oldest cost layers first
+	CostFIFO CostMethod = "fifo"
+	//CostWeightedAverage values all stock of an article at its moving average cost
+	CostWeightedAverage CostMethod = "weighted_average"
+)
+
+//IsValid checks if the method is one of the known cost methods
+func (method CostMethod) IsValid() bool {
+	switch method {
+	case CostFIFO, CostWeightedAverage:
+		return true
+	}
+	return false
+}
+
+//ArticleValuation is the value of the stock of an article
+type ArticleValuation struct {
+	ArtId    string  `json:"art_id"`
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	UnitCost float64 `json:"unit_cost"`
+	Value    float64 `json:"value"`
+}
+
+//Valuation is the value of the stock and the cost of the goods sold by the cost method
+type Valuation struct {
+	Method          CostMethod         `json:"method"`
+	Articles        []ArticleValuation `json:"articles"`
+	TotalValue      float64            `json:"total_value"`
+	CostOfGoodsSold float64            `json:"cost_of_goods_sold"`
+}
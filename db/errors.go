@@ -0,0 +1,86 @@
+package db
+
+import "errors"
+
+var (
+	//ErrIdempotencyInProgress is returned when a request with the same idempotency key is still being processed
+	ErrIdempotencyInProgress = errors.New("a request with this idempotency key is already in progress")
+	//ErrIdempotencyKeyReused is returned when an idempotency key is sent again for a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key is already used for a different request")
+)
+
+var (
+	//ErrOrderNotFound is returned when the requested order does not exist
+	ErrOrderNotFound = errors.New("order is not found")
+	//ErrInvalidOrderTransition is returned when the order cannot move to the requested status
+	ErrInvalidOrderTransition = errors.New("order cannot move to the requested status")
+	//ErrInsufficientStock is returned when there are not enough articles in stock to allocate an order
+	ErrInsufficientStock = errors.New("not enough articles in stock")
+	//ErrOrderedProductNotInSystem is returned when an order line is of a product that does not exist
+	ErrOrderedProductNotInSystem = errors.New("ordered product is not in system")
+)
+
+var (
+	//ErrPurchaseOrderNotFound is returned when the requested purchase order does not exist
+	ErrPurchaseOrderNotFound = errors.New("purchase order is not found")
+	//ErrPurchaseOrderClosed is returned when goods are received for a closed purchase order
+	ErrPurchaseOrderClosed = errors.New("purchase order is closed")
+	//ErrReceivedArticleNotOrdered is returned when goods are received for an article the purchase order does not expect
+	ErrReceivedArticleNotOrdered = errors.New("received article is not ordered")
+)
+
+var (
+	//ErrArticleNotFound is returned when the requested article does not exist
+	ErrArticleNotFound = errors.New("article is not found")
+	//ErrSupplierNotFound is returned when the requested supplier does not exist
+	ErrSupplierNotFound = errors.New("supplier is not found")
+	//ErrSupplierExists is returned when a supplier with the same name already exists
+	ErrSupplierExists = errors.New("supplier with this name already exists")
+)
+
+var (
+	//ErrBarcodeNotFound is returned when no article or product has the barcode
+	ErrBarcodeNotFound = errors.New("barcode is not found")
+	//ErrBarcodeNotAdjustable is returned when a product barcode is scanned to adjust the stock
+	ErrBarcodeNotAdjustable = errors.New("barcode belongs to a product, only article stock can be adjusted by scan")
+)
+
+var (
+	//ErrImportJobNotFound is returned when the import job does not exist
+	ErrImportJobNotFound = errors.New("import job is not found")
+	//ErrImportJobLost is returned when another worker took over the import job
+	ErrImportJobLost = errors.New("import job is taken over by another worker")
+)
+
+var (
+	//ErrProductNotFound is returned when the product does not exist
+	ErrProductNotFound = errors.New("product is not found")
+	//ErrPriceNotFound is returned when the product has no price
+	ErrPriceNotFound = errors.New("product has no price")
+)
+
+var (
+	//ErrProductNotInSystem is returned when the product to sell does not exist
+	ErrProductNotInSystem = errors.New("this product is not in system, cannot be sold")
+	//ErrProductOutOfStock is returned when there are not enough articles to sell the product
+	ErrProductOutOfStock = errors.New("this product is not in stock, cannot be sold")
+)
+
+var (
+	//ErrSaleNotFound is returned when the sale of a return does not exist
+	ErrSaleNotFound = errors.New("sale is not found")
+	//ErrSaleOfOtherProduct is returned when the sale of a return is of another product
+	ErrSaleOfOtherProduct = errors.New("sale is of another product")
+	//ErrReturnExceedsSale is returned when more products are returned than the sale has left to return
+	ErrReturnExceedsSale = errors.New("returned quantity exceeds the quantity sold")
+	//ErrProductNotReturnable is returned when the returned product does not exist
+	ErrProductNotReturnable = errors.New("this product is not in system, cannot be returned")
+	//ErrInvalidDamagedArticle is returned when a damaged article is not part of the returned product or its amount
+	//is not within the returned amount
+	ErrInvalidDamagedArticle = errors.New("damaged article is not valid")
+)
+
+var (
+	//ErrAPIKeyNotFound is returned when no active API key has the hash or the id
+	ErrAPIKeyNotFound = errors.New("API key is not found")
+)
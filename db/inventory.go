@@ -3,14 +3,70 @@ package db
 import (
 	"context"
 	"github.com/auknl/warehouse/data"
+	"time"
 )
 
 type Inventory interface {
 	Ping() error
 	Open() error
+	Close() error
 	GetInventory(ctx context.Context) (error, []data.Stock)
+	CountLowStockArticles(ctx context.Context, threshold int) (error, map[string]int)
 	GetProductStock(ctx context.Context) (error, data.ProductStocks)
+	GetInventoryAsOf(ctx context.Context, asOf time.Time) (error, []data.Stock)
+	GetProductStockAsOf(ctx context.Context, asOf time.Time) (error, data.ProductStocks)
+	GetValuation(ctx context.Context, method data.CostMethod) (error, data.Valuation)
+	SetProductPrice(ctx context.Context, price data.Price) (error, data.Price)
+	GetProductPrice(ctx context.Context, productName string) (error, data.Price)
+	GetSales(ctx context.Context, filter data.SalesFilter) (error, []data.Sale)
+	GetSalesRevenue(ctx context.Context, filter data.SalesFilter) (error, data.SalesRevenue)
+	GetTopProducts(ctx context.Context, filter data.SalesFilter, by data.SalesRanking, limit int) (error, []data.TopProduct)
+	GetSalesSeries(ctx context.Context, filter data.SalesFilter, interval data.SeriesInterval) (error, []data.ProductSeries)
 	UploadProducts(ctx context.Context, product data.Products) (error, int)
 	UploadInventory(ctx context.Context, inventory data.Inventory) (error, int)
 	SellProduct(ctx context.Context, productName string) error
+	ReturnProduct(ctx context.Context, productReturn data.ProductReturn) (error, data.ProductReturn)
+
+	CreateOrder(ctx context.Context, order data.Order) (error, data.Order)
+	GetOrders(ctx context.Context, status data.OrderStatus) (error, []data.Order)
+	GetOrder(ctx context.Context, orderId int) (error, data.Order)
+	UpdateOrderStatus(ctx context.Context, orderId int, status data.OrderStatus) (error, data.Order)
+
+	CreatePurchaseOrder(ctx context.Context, purchaseOrder data.PurchaseOrder) (error, data.PurchaseOrder)
+	GetPurchaseOrders(ctx context.Context, status data.PurchaseOrderStatus) (error, []data.PurchaseOrder)
+	GetPurchaseOrder(ctx context.Context, purchaseOrderId int) (error, data.PurchaseOrder)
+	ReceiveGoods(ctx context.Context, receipt data.GoodsReceipt) (error, data.PurchaseOrder)
+	ClosePurchaseOrder(ctx context.Context, purchaseOrderId int) (error, data.PurchaseOrder)
+
+	GetArticle(ctx context.Context, artId string) (error, data.Stock)
+	GetSuppliers(ctx context.Context) (error, []data.Supplier)
+	GetSupplier(ctx context.Context, supplierId int) (error, data.Supplier)
+	CreateSupplier(ctx context.Context, supplier data.Supplier) (error, data.Supplier)
+	UpdateSupplier(ctx context.Context, supplier data.Supplier) (error, data.Supplier)
+	DeleteSupplier(ctx context.Context, supplierId int) error
+	SaveSupplierArticle(ctx context.Context, article data.SupplierArticle) (error, data.SupplierArticle)
+	DeleteSupplierArticle(ctx context.Context, supplierId int, artId string) error
+
+	LookupBarcode(ctx context.Context, barcode string) (error, data.ScanResult)
+	AdjustStockByScan(ctx context.Context, barcode string, delta int) (error, data.Stock)
+
+	CreateImportJob(ctx context.Context, job data.ImportJob) (error, data.ImportJob)
+	GetImportJob(ctx context.Context, jobId int) (error, data.ImportJob)
+	ClaimImportJob(ctx context.Context, worker string, staleAfter time.Duration) (error, *data.ImportJob)
+	UpdateImportJob(ctx context.Context, job data.ImportJob) error
+	RenewImportJob(ctx context.Context, jobId int, worker string) error
+
+	GetSnapshot(ctx context.Context) (error, data.Snapshot)
+	RestoreSnapshot(ctx context.Context, snapshot data.Snapshot) error
+
+	ReserveIdempotencyKey(ctx context.Context, key, method, path string, retention, staleAfter time.Duration) (error, *data.IdempotentResponse)
+	SaveIdempotentResponse(ctx context.Context, key string, statusCode int, body []byte) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+
+	CreateAPIKey(ctx context.Context, key data.APIKey) (error, data.APIKey)
+	GetAPIKeys(ctx context.Context) (error, []data.APIKey)
+	GetActiveAPIKey(ctx context.Context, keyHash string) (error, data.APIKey)
+	RevokeAPIKey(ctx context.Context, keyId int) error
+
+	TakeRateLimitToken(ctx context.Context, key string, limit data.RateLimit) (error, data.RateDecision)
 }
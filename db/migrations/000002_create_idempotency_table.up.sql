@@ -0,0 +1,13 @@
+CREATE TABLE idempotency_key
+(
+    idempotency_key VARCHAR(255) NOT NULL,
+    method          VARCHAR(16)  NOT NULL,
+    path            VARCHAR(512) NOT NULL,
+    status_code     INT,
+    response_body   BYTEA,
+    completed       BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    PRIMARY KEY (idempotency_key)
+);
+
+CREATE INDEX idempotency_key_created_at_idx ON idempotency_key (created_at);
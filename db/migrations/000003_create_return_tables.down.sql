@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS product_return_article;
+DROP TABLE IF EXISTS product_return;
@@ -0,0 +1,19 @@
+CREATE TABLE product_return
+(
+    return_id    SERIAL       NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    quantity     INT          NOT NULL CHECK (quantity > 0),
+    sale_id      INT,
+    reason       TEXT         NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    PRIMARY KEY (return_id)
+);
+
+CREATE TABLE product_return_article
+(
+    return_id INT          NOT NULL REFERENCES product_return (return_id) ON DELETE CASCADE,
+    art_id    VARCHAR(255) NOT NULL REFERENCES inventory (art_id),
+    restocked INT          NOT NULL CHECK (restocked >= 0),
+    damaged   INT          NOT NULL CHECK (damaged >= 0),
+    PRIMARY KEY (return_id, art_id)
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS order_allocation;
+DROP TABLE IF EXISTS order_line;
+DROP TABLE IF EXISTS orders;
+
+ALTER TABLE inventory
+    DROP CONSTRAINT IF EXISTS inventory_allocated_stock_check,
+    DROP COLUMN IF EXISTS allocated;
@@ -0,0 +1,30 @@
+ALTER TABLE inventory
+    ADD COLUMN allocated INT NOT NULL DEFAULT 0 CHECK (allocated >= 0),
+    ADD CONSTRAINT inventory_allocated_stock_check CHECK (allocated <= stock);
+
+CREATE TABLE orders
+(
+    order_id   SERIAL      NOT NULL,
+    status     VARCHAR(16) NOT NULL CHECK (status IN ('created', 'allocated', 'picked', 'shipped', 'cancelled')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (order_id)
+);
+
+CREATE INDEX orders_status_idx ON orders (status);
+
+CREATE TABLE order_line
+(
+    order_id     INT          NOT NULL REFERENCES orders (order_id) ON DELETE CASCADE,
+    product_name VARCHAR(255) NOT NULL,
+    quantity     INT          NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (order_id, product_name)
+);
+
+CREATE TABLE order_allocation
+(
+    order_id INT          NOT NULL REFERENCES orders (order_id) ON DELETE CASCADE,
+    art_id   VARCHAR(255) NOT NULL REFERENCES inventory (art_id),
+    amount   INT          NOT NULL CHECK (amount > 0),
+    PRIMARY KEY (order_id, art_id)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS goods_receipt_line;
+DROP TABLE IF EXISTS goods_receipt;
+DROP TABLE IF EXISTS purchase_order_line;
+DROP TABLE IF EXISTS purchase_order;
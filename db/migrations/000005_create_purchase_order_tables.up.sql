@@ -0,0 +1,37 @@
+CREATE TABLE purchase_order
+(
+    purchase_order_id SERIAL      NOT NULL,
+    status            VARCHAR(32) NOT NULL CHECK (status IN ('open', 'partially_received', 'received', 'closed')),
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (purchase_order_id)
+);
+
+CREATE INDEX purchase_order_status_idx ON purchase_order (status);
+
+CREATE TABLE purchase_order_line
+(
+    purchase_order_id INT          NOT NULL REFERENCES purchase_order (purchase_order_id) ON DELETE CASCADE,
+    art_id            VARCHAR(255) NOT NULL REFERENCES inventory (art_id),
+    expected          INT          NOT NULL CHECK (expected > 0),
+    received          INT          NOT NULL DEFAULT 0 CHECK (received >= 0),
+    PRIMARY KEY (purchase_order_id, art_id)
+);
+
+CREATE INDEX purchase_order_line_art_id_idx ON purchase_order_line (art_id);
+
+CREATE TABLE goods_receipt
+(
+    receipt_id        SERIAL      NOT NULL,
+    purchase_order_id INT         NOT NULL REFERENCES purchase_order (purchase_order_id) ON DELETE CASCADE,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (receipt_id)
+);
+
+CREATE TABLE goods_receipt_line
+(
+    receipt_id INT          NOT NULL REFERENCES goods_receipt (receipt_id) ON DELETE CASCADE,
+    art_id     VARCHAR(255) NOT NULL REFERENCES inventory (art_id),
+    quantity   INT          NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (receipt_id, art_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS article_supplier;
+DROP TABLE IF EXISTS supplier;
@@ -0,0 +1,21 @@
+CREATE TABLE supplier
+(
+    supplier_id SERIAL       NOT NULL,
+    name        VARCHAR(255) NOT NULL UNIQUE,
+    email       VARCHAR(255) NOT NULL DEFAULT '',
+    phone       VARCHAR(64)  NOT NULL DEFAULT '',
+    PRIMARY KEY (supplier_id)
+);
+
+CREATE TABLE article_supplier
+(
+    art_id             VARCHAR(255)   NOT NULL REFERENCES inventory (art_id) ON DELETE CASCADE,
+    supplier_id        INT            NOT NULL REFERENCES supplier (supplier_id) ON DELETE CASCADE,
+    part_number        VARCHAR(255)   NOT NULL DEFAULT '',
+    unit_cost          NUMERIC(12, 4) NOT NULL DEFAULT 0 CHECK (unit_cost >= 0),
+    lead_time_days     INT            NOT NULL DEFAULT 0 CHECK (lead_time_days >= 0),
+    min_order_quantity INT            NOT NULL DEFAULT 1 CHECK (min_order_quantity > 0),
+    PRIMARY KEY (art_id, supplier_id)
+);
+
+CREATE INDEX article_supplier_supplier_id_idx ON article_supplier (supplier_id);
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS article_barcode;
+
+DROP INDEX IF EXISTS inventory_category_idx;
+
+ALTER TABLE inventory
+    DROP COLUMN IF EXISTS unit,
+    DROP COLUMN IF EXISTS weight_kg,
+    DROP COLUMN IF EXISTS length_cm,
+    DROP COLUMN IF EXISTS width_cm,
+    DROP COLUMN IF EXISTS height_cm,
+    DROP COLUMN IF EXISTS category,
+    DROP COLUMN IF EXISTS attributes;
@@ -0,0 +1,19 @@
+ALTER TABLE inventory
+    ADD COLUMN unit       VARCHAR(16)    NOT NULL DEFAULT '',
+    ADD COLUMN weight_kg  NUMERIC(12, 3) CHECK (weight_kg >= 0),
+    ADD COLUMN length_cm  NUMERIC(10, 2) CHECK (length_cm >= 0),
+    ADD COLUMN width_cm   NUMERIC(10, 2) CHECK (width_cm >= 0),
+    ADD COLUMN height_cm  NUMERIC(10, 2) CHECK (height_cm >= 0),
+    ADD COLUMN category   VARCHAR(255)   NOT NULL DEFAULT '',
+    ADD COLUMN attributes JSONB          NOT NULL DEFAULT '{}';
+
+CREATE INDEX inventory_category_idx ON inventory (category);
+
+CREATE TABLE article_barcode
+(
+    barcode VARCHAR(14)  NOT NULL,
+    art_id  VARCHAR(255) NOT NULL REFERENCES inventory (art_id) ON DELETE CASCADE,
+    PRIMARY KEY (barcode)
+);
+
+CREATE INDEX article_barcode_art_id_idx ON article_barcode (art_id);
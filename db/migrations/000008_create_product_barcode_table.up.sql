@@ -0,0 +1,8 @@
+CREATE TABLE product_barcode
+(
+    barcode      VARCHAR(14)  NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (barcode)
+);
+
+CREATE INDEX product_barcode_product_name_idx ON product_barcode (product_name);
@@ -0,0 +1,18 @@
+CREATE TABLE import_job
+(
+    job_id     SERIAL      NOT NULL,
+    kind       VARCHAR(32) NOT NULL CHECK (kind IN ('inventory', 'products')),
+    state      VARCHAR(32) NOT NULL CHECK (state IN ('queued', 'running', 'succeeded', 'partially_succeeded', 'failed')),
+    payload    BYTEA       NOT NULL,
+    total      INT         NOT NULL,
+    processed  INT         NOT NULL DEFAULT 0,
+    records    INT         NOT NULL DEFAULT 0,
+    batches    INT         NOT NULL DEFAULT 0,
+    errors     JSONB       NOT NULL DEFAULT '[]',
+    worker     VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (job_id)
+);
+
+CREATE INDEX import_job_state_idx ON import_job (state, updated_at);
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS product_history_update ON product;
+DROP TRIGGER IF EXISTS product_history_insert_delete ON product;
+DROP FUNCTION IF EXISTS record_product_history();
+DROP TRIGGER IF EXISTS inventory_history_update ON inventory;
+DROP TRIGGER IF EXISTS inventory_history_insert_delete ON inventory;
+DROP FUNCTION IF EXISTS record_inventory_history();
+DROP TABLE IF EXISTS product_history;
+DROP TABLE IF EXISTS inventory_history;
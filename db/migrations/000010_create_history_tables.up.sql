@@ -0,0 +1,103 @@
+CREATE TABLE inventory_history
+(
+    history_id BIGSERIAL      NOT NULL,
+    art_id     VARCHAR(255)   NOT NULL,
+    art_name   VARCHAR(255)   NOT NULL,
+    stock      INT            NOT NULL,
+    allocated  INT            NOT NULL,
+    unit       VARCHAR(16)    NOT NULL,
+    weight_kg  NUMERIC(12, 3),
+    length_cm  NUMERIC(10, 2),
+    width_cm   NUMERIC(10, 2),
+    height_cm  NUMERIC(10, 2),
+    category   VARCHAR(255)   NOT NULL,
+    attributes JSONB          NOT NULL,
+    deleted    BOOLEAN        NOT NULL DEFAULT FALSE,
+    changed_at TIMESTAMPTZ    NOT NULL DEFAULT now(),
+    PRIMARY KEY (history_id)
+);
+
+CREATE INDEX inventory_history_art_id_idx ON inventory_history (art_id, changed_at);
+
+CREATE TABLE product_history
+(
+    history_id   BIGSERIAL    NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    art_id       VARCHAR(255) NOT NULL,
+    amount       INT          NOT NULL,
+    deleted      BOOLEAN      NOT NULL DEFAULT FALSE,
+    changed_at   TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    PRIMARY KEY (history_id)
+);
+
+CREATE INDEX product_history_product_idx ON product_history (product_name, art_id, changed_at);
+
+-- every version of an inventory row is kept, a deleted row is kept with the deleted flag
+CREATE FUNCTION record_inventory_history() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO inventory_history (art_id, art_name, stock, allocated, unit, weight_kg, length_cm, width_cm, height_cm,
+                                       category, attributes, deleted)
+        VALUES (OLD.art_id, OLD.art_name, OLD.stock, OLD.allocated, OLD.unit, OLD.weight_kg, OLD.length_cm, OLD.width_cm,
+                OLD.height_cm, OLD.category, OLD.attributes, TRUE);
+        RETURN OLD;
+    END IF;
+    INSERT INTO inventory_history (art_id, art_name, stock, allocated, unit, weight_kg, length_cm, width_cm, height_cm,
+                                   category, attributes)
+    VALUES (NEW.art_id, NEW.art_name, NEW.stock, NEW.allocated, NEW.unit, NEW.weight_kg, NEW.length_cm, NEW.width_cm,
+            NEW.height_cm, NEW.category, NEW.attributes);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER inventory_history_insert_delete
+    AFTER INSERT OR DELETE
+    ON inventory
+    FOR EACH ROW
+EXECUTE PROCEDURE record_inventory_history();
+
+CREATE TRIGGER inventory_history_update
+    AFTER UPDATE
+    ON inventory
+    FOR EACH ROW
+    WHEN (OLD.* IS DISTINCT FROM NEW.*)
+EXECUTE PROCEDURE record_inventory_history();
+
+-- every version of a product article (BOM) row is kept, a deleted row is kept with the deleted flag
+CREATE FUNCTION record_product_history() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO product_history (product_name, art_id, amount, deleted)
+        VALUES (OLD.product_name, OLD.art_id, OLD.amount, TRUE);
+        RETURN OLD;
+    END IF;
+    INSERT INTO product_history (product_name, art_id, amount)
+    VALUES (NEW.product_name, NEW.art_id, NEW.amount);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_history_insert_delete
+    AFTER INSERT OR DELETE
+    ON product
+    FOR EACH ROW
+EXECUTE PROCEDURE record_product_history();
+
+CREATE TRIGGER product_history_update
+    AFTER UPDATE
+    ON product
+    FOR EACH ROW
+    WHEN (OLD.* IS DISTINCT FROM NEW.*)
+EXECUTE PROCEDURE record_product_history();
+
+-- the history starts with the current rows
+INSERT INTO inventory_history (art_id, art_name, stock, allocated, unit, weight_kg, length_cm, width_cm, height_cm,
+                               category, attributes)
+SELECT art_id, art_name, stock, allocated, unit, weight_kg, length_cm, width_cm, height_cm, category, attributes
+FROM inventory;
+
+INSERT INTO product_history (product_name, art_id, amount)
+SELECT product_name, art_id, amount
+FROM product;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sale;
+DROP TABLE IF EXISTS article_cost;
+DROP TABLE IF EXISTS cost_layer;
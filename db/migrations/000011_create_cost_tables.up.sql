@@ -0,0 +1,43 @@
+CREATE TABLE cost_layer
+(
+    layer_id   SERIAL         NOT NULL,
+    art_id     VARCHAR(255)   NOT NULL REFERENCES inventory (art_id) ON DELETE CASCADE,
+    source     VARCHAR(16)    NOT NULL CHECK (source IN ('opening', 'upload', 'receipt', 'return', 'adjustment', 'restore')),
+    quantity   INT            NOT NULL CHECK (quantity > 0),
+    remaining  INT            NOT NULL CHECK (remaining >= 0 AND remaining <= quantity),
+    unit_cost  NUMERIC(14, 4) NOT NULL CHECK (unit_cost >= 0),
+    created_at TIMESTAMPTZ    NOT NULL DEFAULT now(),
+    PRIMARY KEY (layer_id)
+);
+
+CREATE INDEX cost_layer_open_idx ON cost_layer (art_id, layer_id) WHERE remaining > 0;
+
+CREATE TABLE article_cost
+(
+    art_id       VARCHAR(255)   NOT NULL REFERENCES inventory (art_id) ON DELETE CASCADE,
+    average_cost NUMERIC(14, 4) NOT NULL DEFAULT 0 CHECK (average_cost >= 0),
+    PRIMARY KEY (art_id)
+);
+
+CREATE TABLE sale
+(
+    sale_id      SERIAL         NOT NULL,
+    product_name VARCHAR(255)   NOT NULL,
+    quantity     INT            NOT NULL CHECK (quantity > 0),
+    cost_fifo    NUMERIC(14, 4) NOT NULL,
+    cost_average NUMERIC(14, 4) NOT NULL,
+    sold_at      TIMESTAMPTZ    NOT NULL DEFAULT now(),
+    PRIMARY KEY (sale_id)
+);
+
+CREATE INDEX sale_sold_at_idx ON sale (sold_at);
+
+-- the cost of the stock on hand before costing is unknown, it is kept as an opening layer without cost
+INSERT INTO cost_layer (art_id, source, quantity, remaining, unit_cost)
+SELECT art_id, 'opening', stock, stock, 0
+FROM inventory
+WHERE stock > 0;
+
+INSERT INTO article_cost (art_id, average_cost)
+SELECT art_id, 0
+FROM inventory;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS sale_product_name_idx;
+
+ALTER TABLE sale
+    DROP COLUMN IF EXISTS unit_price,
+    DROP COLUMN IF EXISTS currency;
+
+DROP TABLE IF EXISTS product_price;
@@ -0,0 +1,15 @@
+CREATE TABLE product_price
+(
+    product_name VARCHAR(255)   NOT NULL,
+    price        NUMERIC(14, 4) NOT NULL CHECK (price >= 0),
+    currency     CHAR(3)        NOT NULL,
+    updated_at   TIMESTAMPTZ    NOT NULL DEFAULT now(),
+    PRIMARY KEY (product_name)
+);
+
+-- the sales before pricing and the sales of products without a price have no unit price
+ALTER TABLE sale
+    ADD COLUMN unit_price NUMERIC(14, 4) CHECK (unit_price >= 0),
+    ADD COLUMN currency   CHAR(3);
+
+CREATE INDEX sale_product_name_idx ON sale (product_name, sold_at);
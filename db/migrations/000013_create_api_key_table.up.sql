@@ -0,0 +1,12 @@
+-- only the sha256 hash of a key is kept, the key itself is shown once when it is created
+CREATE TABLE api_key
+(
+    key_id     SERIAL,
+    name       VARCHAR(255) NOT NULL,
+    prefix     VARCHAR(16)  NOT NULL,
+    key_hash   CHAR(64)     NOT NULL UNIQUE,
+    scopes     TEXT[]       NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT now(),
+    revoked_at TIMESTAMPTZ,
+    PRIMARY KEY (key_id)
+);
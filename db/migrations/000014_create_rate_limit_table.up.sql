@@ -0,0 +1,8 @@
+-- token buckets of the rate limits shared by the instances, a bucket is per client and route group
+CREATE TABLE rate_limit_bucket
+(
+    bucket_key VARCHAR(255)     NOT NULL,
+    tokens     DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT now(),
+    PRIMARY KEY (bucket_key)
+);
@@ -0,0 +1,114 @@
+-- the rows of the tenants other than the default one have to be removed before, the keys are not unique without
+-- the tenant
+
+CREATE OR REPLACE FUNCTION record_inventory_history() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO inventory_history (art_id, art_name, stock, allocated, unit, weight_kg, length_cm, width_cm, height_cm,
+                                       category, attributes, deleted)
+        VALUES (OLD.art_id, OLD.art_name, OLD.stock, OLD.allocated, OLD.unit, OLD.weight_kg, OLD.length_cm, OLD.width_cm,
+                OLD.height_cm, OLD.category, OLD.attributes, TRUE);
+        RETURN OLD;
+    END IF;
+    INSERT INTO inventory_history (art_id, art_name, stock, allocated, unit, weight_kg, length_cm, width_cm, height_cm,
+                                   category, attributes)
+    VALUES (NEW.art_id, NEW.art_name, NEW.stock, NEW.allocated, NEW.unit, NEW.weight_kg, NEW.length_cm, NEW.width_cm,
+            NEW.height_cm, NEW.category, NEW.attributes);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION record_product_history() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO product_history (product_name, art_id, amount, deleted)
+        VALUES (OLD.product_name, OLD.art_id, OLD.amount, TRUE);
+        RETURN OLD;
+    END IF;
+    INSERT INTO product_history (product_name, art_id, amount)
+    VALUES (NEW.product_name, NEW.art_id, NEW.amount);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX api_key_tenant_idx;
+DROP INDEX import_job_tenant_idx;
+DROP INDEX product_return_tenant_idx;
+DROP INDEX sale_product_name_idx;
+CREATE INDEX sale_product_name_idx ON sale (product_name, sold_at);
+DROP INDEX sale_sold_at_idx;
+CREATE INDEX sale_sold_at_idx ON sale (sold_at);
+DROP INDEX cost_layer_open_idx;
+CREATE INDEX cost_layer_open_idx ON cost_layer (art_id, layer_id) WHERE remaining > 0;
+DROP INDEX product_history_product_idx;
+CREATE INDEX product_history_product_idx ON product_history (product_name, art_id, changed_at);
+DROP INDEX inventory_history_art_id_idx;
+CREATE INDEX inventory_history_art_id_idx ON inventory_history (art_id, changed_at);
+DROP INDEX product_barcode_product_name_idx;
+CREATE INDEX product_barcode_product_name_idx ON product_barcode (product_name);
+DROP INDEX article_barcode_art_id_idx;
+CREATE INDEX article_barcode_art_id_idx ON article_barcode (art_id);
+DROP INDEX article_supplier_supplier_id_idx;
+CREATE INDEX article_supplier_supplier_id_idx ON article_supplier (supplier_id);
+DROP INDEX purchase_order_line_art_id_idx;
+CREATE INDEX purchase_order_line_art_id_idx ON purchase_order_line (art_id);
+DROP INDEX purchase_order_status_idx;
+CREATE INDEX purchase_order_status_idx ON purchase_order (status);
+DROP INDEX orders_status_idx;
+CREATE INDEX orders_status_idx ON orders (status);
+DROP INDEX inventory_category_idx;
+CREATE INDEX inventory_category_idx ON inventory (category);
+
+-- dropping the tenant columns drops the keys and the references with them
+ALTER TABLE rate_limit_bucket DROP COLUMN tenant_id, ADD PRIMARY KEY (bucket_key);
+ALTER TABLE api_key DROP COLUMN tenant_id;
+ALTER TABLE product_price DROP COLUMN tenant_id, ADD PRIMARY KEY (product_name);
+ALTER TABLE sale DROP COLUMN tenant_id;
+ALTER TABLE article_cost DROP COLUMN tenant_id CASCADE;
+ALTER TABLE cost_layer DROP COLUMN tenant_id CASCADE;
+ALTER TABLE product_history DROP COLUMN tenant_id;
+ALTER TABLE inventory_history DROP COLUMN tenant_id;
+ALTER TABLE import_job DROP COLUMN tenant_id;
+ALTER TABLE product_barcode DROP COLUMN tenant_id, ADD PRIMARY KEY (barcode);
+ALTER TABLE article_barcode DROP COLUMN tenant_id CASCADE;
+ALTER TABLE article_supplier DROP COLUMN tenant_id CASCADE;
+ALTER TABLE supplier DROP COLUMN tenant_id CASCADE;
+ALTER TABLE goods_receipt_line DROP COLUMN tenant_id CASCADE;
+ALTER TABLE goods_receipt DROP COLUMN tenant_id CASCADE;
+ALTER TABLE purchase_order_line DROP COLUMN tenant_id CASCADE;
+ALTER TABLE purchase_order DROP COLUMN tenant_id CASCADE;
+ALTER TABLE order_allocation DROP COLUMN tenant_id CASCADE;
+ALTER TABLE order_line DROP COLUMN tenant_id CASCADE;
+ALTER TABLE orders DROP COLUMN tenant_id CASCADE;
+ALTER TABLE product_return_article DROP COLUMN tenant_id CASCADE;
+ALTER TABLE product_return DROP COLUMN tenant_id CASCADE;
+ALTER TABLE idempotency_key DROP COLUMN tenant_id, ADD PRIMARY KEY (idempotency_key);
+ALTER TABLE product DROP COLUMN tenant_id CASCADE;
+ALTER TABLE inventory DROP COLUMN tenant_id CASCADE;
+
+ALTER TABLE inventory ADD PRIMARY KEY (art_id);
+ALTER TABLE product ADD PRIMARY KEY (product_name, art_id);
+ALTER TABLE supplier ADD UNIQUE (name);
+ALTER TABLE article_supplier ADD PRIMARY KEY (art_id, supplier_id);
+ALTER TABLE article_barcode ADD PRIMARY KEY (barcode);
+ALTER TABLE article_cost ADD PRIMARY KEY (art_id);
+
+ALTER TABLE product ADD FOREIGN KEY (art_id) REFERENCES inventory (art_id);
+ALTER TABLE product_return_article ADD FOREIGN KEY (art_id) REFERENCES inventory (art_id);
+ALTER TABLE order_allocation ADD FOREIGN KEY (art_id) REFERENCES inventory (art_id);
+ALTER TABLE purchase_order_line ADD FOREIGN KEY (art_id) REFERENCES inventory (art_id);
+ALTER TABLE goods_receipt_line ADD FOREIGN KEY (art_id) REFERENCES inventory (art_id);
+ALTER TABLE article_supplier ADD FOREIGN KEY (art_id) REFERENCES inventory (art_id) ON DELETE CASCADE;
+ALTER TABLE article_barcode ADD FOREIGN KEY (art_id) REFERENCES inventory (art_id) ON DELETE CASCADE;
+ALTER TABLE cost_layer ADD FOREIGN KEY (art_id) REFERENCES inventory (art_id) ON DELETE CASCADE;
+ALTER TABLE article_cost ADD FOREIGN KEY (art_id) REFERENCES inventory (art_id) ON DELETE CASCADE;
+
+ALTER TABLE product_return_article ADD FOREIGN KEY (return_id) REFERENCES product_return (return_id) ON DELETE CASCADE;
+ALTER TABLE order_line ADD FOREIGN KEY (order_id) REFERENCES orders (order_id) ON DELETE CASCADE;
+ALTER TABLE order_allocation ADD FOREIGN KEY (order_id) REFERENCES orders (order_id) ON DELETE CASCADE;
+ALTER TABLE purchase_order_line ADD FOREIGN KEY (purchase_order_id) REFERENCES purchase_order (purchase_order_id) ON DELETE CASCADE;
+ALTER TABLE goods_receipt ADD FOREIGN KEY (purchase_order_id) REFERENCES purchase_order (purchase_order_id) ON DELETE CASCADE;
+ALTER TABLE goods_receipt_line ADD FOREIGN KEY (receipt_id) REFERENCES goods_receipt (receipt_id) ON DELETE CASCADE;
+ALTER TABLE article_supplier ADD FOREIGN KEY (supplier_id) REFERENCES supplier (supplier_id) ON DELETE CASCADE;
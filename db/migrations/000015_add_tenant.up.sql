@@ -0,0 +1,171 @@
+-- every row belongs to a tenant, the rows from before the tenants belong to the default tenant
+ALTER TABLE inventory ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE product ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE idempotency_key ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE product_return ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE product_return_article ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE orders ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE order_line ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE order_allocation ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE purchase_order ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE purchase_order_line ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE goods_receipt ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE goods_receipt_line ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE supplier ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE article_supplier ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE article_barcode ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE product_barcode ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE import_job ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE inventory_history ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE product_history ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE cost_layer ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE article_cost ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE sale ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE product_price ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE api_key ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE rate_limit_bucket ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- the references to the articles are to the articles of the same tenant
+ALTER TABLE product DROP CONSTRAINT product_art_id_fkey;
+ALTER TABLE product_return_article DROP CONSTRAINT product_return_article_art_id_fkey;
+ALTER TABLE order_allocation DROP CONSTRAINT order_allocation_art_id_fkey;
+ALTER TABLE purchase_order_line DROP CONSTRAINT purchase_order_line_art_id_fkey;
+ALTER TABLE goods_receipt_line DROP CONSTRAINT goods_receipt_line_art_id_fkey;
+ALTER TABLE article_supplier DROP CONSTRAINT article_supplier_art_id_fkey;
+ALTER TABLE article_barcode DROP CONSTRAINT article_barcode_art_id_fkey;
+ALTER TABLE cost_layer DROP CONSTRAINT cost_layer_art_id_fkey;
+ALTER TABLE article_cost DROP CONSTRAINT article_cost_art_id_fkey;
+
+-- the references to the rows with serial ids are to the rows of the same tenant
+ALTER TABLE product_return_article DROP CONSTRAINT product_return_article_return_id_fkey;
+ALTER TABLE order_line DROP CONSTRAINT order_line_order_id_fkey;
+ALTER TABLE order_allocation DROP CONSTRAINT order_allocation_order_id_fkey;
+ALTER TABLE purchase_order_line DROP CONSTRAINT purchase_order_line_purchase_order_id_fkey;
+ALTER TABLE goods_receipt DROP CONSTRAINT goods_receipt_purchase_order_id_fkey;
+ALTER TABLE goods_receipt_line DROP CONSTRAINT goods_receipt_line_receipt_id_fkey;
+ALTER TABLE article_supplier DROP CONSTRAINT article_supplier_supplier_id_fkey;
+
+-- the natural keys are unique per tenant
+ALTER TABLE inventory DROP CONSTRAINT inventory_pkey, ADD PRIMARY KEY (tenant_id, art_id);
+ALTER TABLE product DROP CONSTRAINT product_pkey, ADD PRIMARY KEY (tenant_id, product_name, art_id);
+ALTER TABLE idempotency_key DROP CONSTRAINT idempotency_key_pkey, ADD PRIMARY KEY (tenant_id, idempotency_key);
+ALTER TABLE supplier DROP CONSTRAINT supplier_name_key, ADD UNIQUE (tenant_id, name);
+ALTER TABLE article_supplier DROP CONSTRAINT article_supplier_pkey, ADD PRIMARY KEY (tenant_id, art_id, supplier_id);
+ALTER TABLE article_barcode DROP CONSTRAINT article_barcode_pkey, ADD PRIMARY KEY (tenant_id, barcode);
+ALTER TABLE product_barcode DROP CONSTRAINT product_barcode_pkey, ADD PRIMARY KEY (tenant_id, barcode);
+ALTER TABLE article_cost DROP CONSTRAINT article_cost_pkey, ADD PRIMARY KEY (tenant_id, art_id);
+ALTER TABLE product_price DROP CONSTRAINT product_price_pkey, ADD PRIMARY KEY (tenant_id, product_name);
+ALTER TABLE rate_limit_bucket DROP CONSTRAINT rate_limit_bucket_pkey, ADD PRIMARY KEY (tenant_id, bucket_key);
+
+ALTER TABLE product_return ADD UNIQUE (tenant_id, return_id);
+ALTER TABLE orders ADD UNIQUE (tenant_id, order_id);
+ALTER TABLE purchase_order ADD UNIQUE (tenant_id, purchase_order_id);
+ALTER TABLE goods_receipt ADD UNIQUE (tenant_id, receipt_id);
+ALTER TABLE supplier ADD UNIQUE (tenant_id, supplier_id);
+
+ALTER TABLE product ADD FOREIGN KEY (tenant_id, art_id) REFERENCES inventory (tenant_id, art_id);
+ALTER TABLE product_return_article ADD FOREIGN KEY (tenant_id, art_id) REFERENCES inventory (tenant_id, art_id);
+ALTER TABLE order_allocation ADD FOREIGN KEY (tenant_id, art_id) REFERENCES inventory (tenant_id, art_id);
+ALTER TABLE purchase_order_line ADD FOREIGN KEY (tenant_id, art_id) REFERENCES inventory (tenant_id, art_id);
+ALTER TABLE goods_receipt_line ADD FOREIGN KEY (tenant_id, art_id) REFERENCES inventory (tenant_id, art_id);
+ALTER TABLE article_supplier ADD FOREIGN KEY (tenant_id, art_id) REFERENCES inventory (tenant_id, art_id) ON DELETE CASCADE;
+ALTER TABLE article_barcode ADD FOREIGN KEY (tenant_id, art_id) REFERENCES inventory (tenant_id, art_id) ON DELETE CASCADE;
+ALTER TABLE cost_layer ADD FOREIGN KEY (tenant_id, art_id) REFERENCES inventory (tenant_id, art_id) ON DELETE CASCADE;
+ALTER TABLE article_cost ADD FOREIGN KEY (tenant_id, art_id) REFERENCES inventory (tenant_id, art_id) ON DELETE CASCADE;
+
+ALTER TABLE product_return_article ADD FOREIGN KEY (tenant_id, return_id) REFERENCES product_return (tenant_id, return_id) ON DELETE CASCADE;
+ALTER TABLE order_line ADD FOREIGN KEY (tenant_id, order_id) REFERENCES orders (tenant_id, order_id) ON DELETE CASCADE;
+ALTER TABLE order_allocation ADD FOREIGN KEY (tenant_id, order_id) REFERENCES orders (tenant_id, order_id) ON DELETE CASCADE;
+ALTER TABLE purchase_order_line ADD FOREIGN KEY (tenant_id, purchase_order_id) REFERENCES purchase_order (tenant_id, purchase_order_id) ON DELETE CASCADE;
+ALTER TABLE goods_receipt ADD FOREIGN KEY (tenant_id, purchase_order_id) REFERENCES purchase_order (tenant_id, purchase_order_id) ON DELETE CASCADE;
+ALTER TABLE goods_receipt_line ADD FOREIGN KEY (tenant_id, receipt_id) REFERENCES goods_receipt (tenant_id, receipt_id) ON DELETE CASCADE;
+ALTER TABLE article_supplier ADD FOREIGN KEY (tenant_id, supplier_id) REFERENCES supplier (tenant_id, supplier_id) ON DELETE CASCADE;
+
+-- the lookups are per tenant
+DROP INDEX inventory_category_idx;
+CREATE INDEX inventory_category_idx ON inventory (tenant_id, category);
+DROP INDEX orders_status_idx;
+CREATE INDEX orders_status_idx ON orders (tenant_id, status);
+DROP INDEX purchase_order_status_idx;
+CREATE INDEX purchase_order_status_idx ON purchase_order (tenant_id, status);
+DROP INDEX purchase_order_line_art_id_idx;
+CREATE INDEX purchase_order_line_art_id_idx ON purchase_order_line (tenant_id, art_id);
+DROP INDEX article_supplier_supplier_id_idx;
+CREATE INDEX article_supplier_supplier_id_idx ON article_supplier (tenant_id, supplier_id);
+DROP INDEX article_barcode_art_id_idx;
+CREATE INDEX article_barcode_art_id_idx ON article_barcode (tenant_id, art_id);
+DROP INDEX product_barcode_product_name_idx;
+CREATE INDEX product_barcode_product_name_idx ON product_barcode (tenant_id, product_name);
+DROP INDEX inventory_history_art_id_idx;
+CREATE INDEX inventory_history_art_id_idx ON inventory_history (tenant_id, art_id, changed_at);
+DROP INDEX product_history_product_idx;
+CREATE INDEX product_history_product_idx ON product_history (tenant_id, product_name, art_id, changed_at);
+DROP INDEX cost_layer_open_idx;
+CREATE INDEX cost_layer_open_idx ON cost_layer (tenant_id, art_id, layer_id) WHERE remaining > 0;
+DROP INDEX sale_sold_at_idx;
+CREATE INDEX sale_sold_at_idx ON sale (tenant_id, sold_at);
+DROP INDEX sale_product_name_idx;
+CREATE INDEX sale_product_name_idx ON sale (tenant_id, product_name, sold_at);
+CREATE INDEX product_return_tenant_idx ON product_return (tenant_id);
+CREATE INDEX import_job_tenant_idx ON import_job (tenant_id, job_id);
+CREATE INDEX api_key_tenant_idx ON api_key (tenant_id);
+
+-- every insert names its tenant
+ALTER TABLE inventory ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE product ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE idempotency_key ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE product_return ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE product_return_article ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE orders ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE order_line ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE order_allocation ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE purchase_order ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE purchase_order_line ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE goods_receipt ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE goods_receipt_line ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE supplier ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE article_supplier ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE article_barcode ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE product_barcode ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE import_job ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE inventory_history ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE product_history ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE cost_layer ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE article_cost ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE sale ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE product_price ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE api_key ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE rate_limit_bucket ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- the history keeps the tenant of the row
+CREATE OR REPLACE FUNCTION record_inventory_history() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO inventory_history (tenant_id, art_id, art_name, stock, allocated, unit, weight_kg, length_cm, width_cm,
+                                       height_cm, category, attributes, deleted)
+        VALUES (OLD.tenant_id, OLD.art_id, OLD.art_name, OLD.stock, OLD.allocated, OLD.unit, OLD.weight_kg, OLD.length_cm,
+                OLD.width_cm, OLD.height_cm, OLD.category, OLD.attributes, TRUE);
+        RETURN OLD;
+    END IF;
+    INSERT INTO inventory_history (tenant_id, art_id, art_name, stock, allocated, unit, weight_kg, length_cm, width_cm,
+                                   height_cm, category, attributes)
+    VALUES (NEW.tenant_id, NEW.art_id, NEW.art_name, NEW.stock, NEW.allocated, NEW.unit, NEW.weight_kg, NEW.length_cm,
+            NEW.width_cm, NEW.height_cm, NEW.category, NEW.attributes);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION record_product_history() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO product_history (tenant_id, product_name, art_id, amount, deleted)
+        VALUES (OLD.tenant_id, OLD.product_name, OLD.art_id, OLD.amount, TRUE);
+        RETURN OLD;
+    END IF;
+    INSERT INTO product_history (tenant_id, product_name, art_id, amount)
+    VALUES (NEW.tenant_id, NEW.product_name, NEW.art_id, NEW.amount);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+-- time of the last reservation of the key, a reservation left in progress longer than the configured bound is taken
+-- over by the next request with the key
+ALTER TABLE idempotency_key ADD COLUMN reserved_at TIMESTAMPTZ NOT NULL DEFAULT now();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS product_return_sale_idx;
+ALTER TABLE product_return DROP CONSTRAINT IF EXISTS product_return_tenant_id_sale_id_fkey;
+ALTER TABLE sale DROP CONSTRAINT IF EXISTS sale_tenant_id_sale_id_key;
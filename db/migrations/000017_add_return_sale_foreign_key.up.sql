@@ -0,0 +1,4 @@
+ALTER TABLE sale ADD UNIQUE (tenant_id, sale_id);
+-- the returns recorded before keep their sale_id, the key is checked for the returns that are recorded from now on
+ALTER TABLE product_return ADD FOREIGN KEY (tenant_id, sale_id) REFERENCES sale (tenant_id, sale_id) NOT VALID;
+CREATE INDEX product_return_sale_idx ON product_return (tenant_id, sale_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS rate_limit_bucket_expires_at_idx;
+ALTER TABLE rate_limit_bucket DROP COLUMN IF EXISTS expires_at;
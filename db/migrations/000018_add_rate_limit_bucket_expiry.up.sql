@@ -0,0 +1,3 @@
+-- time when the bucket is full again, a full bucket is the same as a new one and is removed after it
+ALTER TABLE rate_limit_bucket ADD COLUMN expires_at TIMESTAMPTZ NOT NULL DEFAULT now();
+CREATE INDEX rate_limit_bucket_expires_at_idx ON rate_limit_bucket (expires_at);
@@ -0,0 +1,7 @@
+package migrations
+
+import "embed"
+
+//FS keeps the up and down migrations of the schema, they are embedded so the binary can migrate the database itself
+//go:embed *.sql
+var FS embed.FS
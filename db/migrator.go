@@ -0,0 +1,10 @@
+package db
+
+import "github.com/auknl/warehouse/data"
+
+//Migrator migrates the schema of the database with the migrations embedded in the binary
+type Migrator interface {
+	MigrateUp() error
+	MigrateDown(steps int) error
+	SchemaStatus() (error, data.SchemaStatus)
+}
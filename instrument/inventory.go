@@ -0,0 +1,459 @@
+package instrument
+
+import (
+	"context"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"time"
+)
+
+//Probe is called before every inventory call, the returned func is called with the result of the call
+type Probe func(ctx context.Context, method string) func(err error)
+
+//Inventory wraps a db.Inventory and reports every call to the probes
+type Inventory struct {
+	inventory db.Inventory
+	probes    []Probe
+}
+
+//NewInventory wraps the inventory so that every call of it is reported to the probes
+func NewInventory(inventory db.Inventory, probes ...Probe) db.Inventory {
+	return &Inventory{inventory: inventory, probes: probes}
+}
+
+//start reports the call to the probes and returns the func that reports its result
+func (instrumented *Inventory) start(ctx context.Context, method string) func(err error) {
+	dones := make([]func(err error), 0, len(instrumented.probes))
+	for _, probe := range instrumented.probes {
+		dones = append(dones, probe(ctx, method))
+	}
+	return func(err error) {
+		for _, done := range dones {
+			done(err)
+		}
+	}
+}
+
+//Ping calls Ping of the wrapped inventory
+func (instrumented *Inventory) Ping() error {
+	done := instrumented.start(context.Background(), "Ping")
+	err := instrumented.inventory.Ping()
+	done(err)
+	return err
+}
+
+//Open calls Open of the wrapped inventory
+func (instrumented *Inventory) Open() error {
+	done := instrumented.start(context.Background(), "Open")
+	err := instrumented.inventory.Open()
+	done(err)
+	return err
+}
+
+//Close calls Close of the wrapped inventory
+func (instrumented *Inventory) Close() error {
+	done := instrumented.start(context.Background(), "Close")
+	err := instrumented.inventory.Close()
+	done(err)
+	return err
+}
+
+//GetInventory calls GetInventory of the wrapped inventory
+func (instrumented *Inventory) GetInventory(ctx context.Context) (error, []data.Stock) {
+	done := instrumented.start(ctx, "GetInventory")
+	err, result := instrumented.inventory.GetInventory(ctx)
+	done(err)
+	return err, result
+}
+
+//CountLowStockArticles calls CountLowStockArticles of the wrapped inventory
+func (instrumented *Inventory) CountLowStockArticles(ctx context.Context, threshold int) (error, map[string]int) {
+	done := instrumented.start(ctx, "CountLowStockArticles")
+	err, result := instrumented.inventory.CountLowStockArticles(ctx, threshold)
+	done(err)
+	return err, result
+}
+
+//GetProductStock calls GetProductStock of the wrapped inventory
+func (instrumented *Inventory) GetProductStock(ctx context.Context) (error, data.ProductStocks) {
+	done := instrumented.start(ctx, "GetProductStock")
+	err, result := instrumented.inventory.GetProductStock(ctx)
+	done(err)
+	return err, result
+}
+
+//GetInventoryAsOf calls GetInventoryAsOf of the wrapped inventory
+func (instrumented *Inventory) GetInventoryAsOf(ctx context.Context, asOf time.Time) (error, []data.Stock) {
+	done := instrumented.start(ctx, "GetInventoryAsOf")
+	err, result := instrumented.inventory.GetInventoryAsOf(ctx, asOf)
+	done(err)
+	return err, result
+}
+
+//GetProductStockAsOf calls GetProductStockAsOf of the wrapped inventory
+func (instrumented *Inventory) GetProductStockAsOf(ctx context.Context, asOf time.Time) (error, data.ProductStocks) {
+	done := instrumented.start(ctx, "GetProductStockAsOf")
+	err, result := instrumented.inventory.GetProductStockAsOf(ctx, asOf)
+	done(err)
+	return err, result
+}
+
+//GetValuation calls GetValuation of the wrapped inventory
+func (instrumented *Inventory) GetValuation(ctx context.Context, method data.CostMethod) (error, data.Valuation) {
+	done := instrumented.start(ctx, "GetValuation")
+	err, result := instrumented.inventory.GetValuation(ctx, method)
+	done(err)
+	return err, result
+}
+
+//SetProductPrice calls SetProductPrice of the wrapped inventory
+func (instrumented *Inventory) SetProductPrice(ctx context.Context, price data.Price) (error, data.Price) {
+	done := instrumented.start(ctx, "SetProductPrice")
+	err, result := instrumented.inventory.SetProductPrice(ctx, price)
+	done(err)
+	return err, result
+}
+
+//GetProductPrice calls GetProductPrice of the wrapped inventory
+func (instrumented *Inventory) GetProductPrice(ctx context.Context, productName string) (error, data.Price) {
+	done := instrumented.start(ctx, "GetProductPrice")
+	err, result := instrumented.inventory.GetProductPrice(ctx, productName)
+	done(err)
+	return err, result
+}
+
+//GetSales calls GetSales of the wrapped inventory
+func (instrumented *Inventory) GetSales(ctx context.Context, filter data.SalesFilter) (error, []data.Sale) {
+	done := instrumented.start(ctx, "GetSales")
+	err, result := instrumented.inventory.GetSales(ctx, filter)
+	done(err)
+	return err, result
+}
+
+//GetSalesRevenue calls GetSalesRevenue of the wrapped inventory
+func (instrumented *Inventory) GetSalesRevenue(ctx context.Context, filter data.SalesFilter) (error, data.SalesRevenue) {
+	done := instrumented.start(ctx, "GetSalesRevenue")
+	err, result := instrumented.inventory.GetSalesRevenue(ctx, filter)
+	done(err)
+	return err, result
+}
+
+//GetTopProducts calls GetTopProducts of the wrapped inventory
+func (instrumented *Inventory) GetTopProducts(ctx context.Context, filter data.SalesFilter, by data.SalesRanking, limit int) (error, []data.TopProduct) {
+	done := instrumented.start(ctx, "GetTopProducts")
+	err, result := instrumented.inventory.GetTopProducts(ctx, filter, by, limit)
+	done(err)
+	return err, result
+}
+
+//GetSalesSeries calls GetSalesSeries of the wrapped inventory
+func (instrumented *Inventory) GetSalesSeries(ctx context.Context, filter data.SalesFilter, interval data.SeriesInterval) (error, []data.ProductSeries) {
+	done := instrumented.start(ctx, "GetSalesSeries")
+	err, result := instrumented.inventory.GetSalesSeries(ctx, filter, interval)
+	done(err)
+	return err, result
+}
+
+//UploadProducts calls UploadProducts of the wrapped inventory
+func (instrumented *Inventory) UploadProducts(ctx context.Context, product data.Products) (error, int) {
+	done := instrumented.start(ctx, "UploadProducts")
+	err, result := instrumented.inventory.UploadProducts(ctx, product)
+	done(err)
+	return err, result
+}
+
+//UploadInventory calls UploadInventory of the wrapped inventory
+func (instrumented *Inventory) UploadInventory(ctx context.Context, inventory data.Inventory) (error, int) {
+	done := instrumented.start(ctx, "UploadInventory")
+	err, result := instrumented.inventory.UploadInventory(ctx, inventory)
+	done(err)
+	return err, result
+}
+
+//SellProduct calls SellProduct of the wrapped inventory
+func (instrumented *Inventory) SellProduct(ctx context.Context, productName string) error {
+	done := instrumented.start(ctx, "SellProduct")
+	err := instrumented.inventory.SellProduct(ctx, productName)
+	done(err)
+	return err
+}
+
+//ReturnProduct calls ReturnProduct of the wrapped inventory
+func (instrumented *Inventory) ReturnProduct(ctx context.Context, productReturn data.ProductReturn) (error, data.ProductReturn) {
+	done := instrumented.start(ctx, "ReturnProduct")
+	err, result := instrumented.inventory.ReturnProduct(ctx, productReturn)
+	done(err)
+	return err, result
+}
+
+//CreateOrder calls CreateOrder of the wrapped inventory
+func (instrumented *Inventory) CreateOrder(ctx context.Context, order data.Order) (error, data.Order) {
+	done := instrumented.start(ctx, "CreateOrder")
+	err, result := instrumented.inventory.CreateOrder(ctx, order)
+	done(err)
+	return err, result
+}
+
+//GetOrders calls GetOrders of the wrapped inventory
+func (instrumented *Inventory) GetOrders(ctx context.Context, status data.OrderStatus) (error, []data.Order) {
+	done := instrumented.start(ctx, "GetOrders")
+	err, result := instrumented.inventory.GetOrders(ctx, status)
+	done(err)
+	return err, result
+}
+
+//GetOrder calls GetOrder of the wrapped inventory
+func (instrumented *Inventory) GetOrder(ctx context.Context, orderId int) (error, data.Order) {
+	done := instrumented.start(ctx, "GetOrder")
+	err, result := instrumented.inventory.GetOrder(ctx, orderId)
+	done(err)
+	return err, result
+}
+
+//UpdateOrderStatus calls UpdateOrderStatus of the wrapped inventory
+func (instrumented *Inventory) UpdateOrderStatus(ctx context.Context, orderId int, status data.OrderStatus) (error, data.Order) {
+	done := instrumented.start(ctx, "UpdateOrderStatus")
+	err, result := instrumented.inventory.UpdateOrderStatus(ctx, orderId, status)
+	done(err)
+	return err, result
+}
+
+//CreatePurchaseOrder calls CreatePurchaseOrder of the wrapped inventory
+func (instrumented *Inventory) CreatePurchaseOrder(ctx context.Context, purchaseOrder data.PurchaseOrder) (error, data.PurchaseOrder) {
+	done := instrumented.start(ctx, "CreatePurchaseOrder")
+	err, result := instrumented.inventory.CreatePurchaseOrder(ctx, purchaseOrder)
+	done(err)
+	return err, result
+}
+
+//GetPurchaseOrders calls GetPurchaseOrders of the wrapped inventory
+func (instrumented *Inventory) GetPurchaseOrders(ctx context.Context, status data.PurchaseOrderStatus) (error, []data.PurchaseOrder) {
+	done := instrumented.start(ctx, "GetPurchaseOrders")
+	err, result := instrumented.inventory.GetPurchaseOrders(ctx, status)
+	done(err)
+	return err, result
+}
+
+//GetPurchaseOrder calls GetPurchaseOrder of the wrapped inventory
+func (instrumented *Inventory) GetPurchaseOrder(ctx context.Context, purchaseOrderId int) (error, data.PurchaseOrder) {
+	done := instrumented.start(ctx, "GetPurchaseOrder")
+	err, result := instrumented.inventory.GetPurchaseOrder(ctx, purchaseOrderId)
+	done(err)
+	return err, result
+}
+
+//ReceiveGoods calls ReceiveGoods of the wrapped inventory
+func (instrumented *Inventory) ReceiveGoods(ctx context.Context, receipt data.GoodsReceipt) (error, data.PurchaseOrder) {
+	done := instrumented.start(ctx, "ReceiveGoods")
+	err, result := instrumented.inventory.ReceiveGoods(ctx, receipt)
+	done(err)
+	return err, result
+}
+
+//ClosePurchaseOrder calls ClosePurchaseOrder of the wrapped inventory
+func (instrumented *Inventory) ClosePurchaseOrder(ctx context.Context, purchaseOrderId int) (error, data.PurchaseOrder) {
+	done := instrumented.start(ctx, "ClosePurchaseOrder")
+	err, result := instrumented.inventory.ClosePurchaseOrder(ctx, purchaseOrderId)
+	done(err)
+	return err, result
+}
+
+//GetArticle calls GetArticle of the wrapped inventory
+func (instrumented *Inventory) GetArticle(ctx context.Context, artId string) (error, data.Stock) {
+	done := instrumented.start(ctx, "GetArticle")
+	err, result := instrumented.inventory.GetArticle(ctx, artId)
+	done(err)
+	return err, result
+}
+
+//GetSuppliers calls GetSuppliers of the wrapped inventory
+func (instrumented *Inventory) GetSuppliers(ctx context.Context) (error, []data.Supplier) {
+	done := instrumented.start(ctx, "GetSuppliers")
+	err, result := instrumented.inventory.GetSuppliers(ctx)
+	done(err)
+	return err, result
+}
+
+//GetSupplier calls GetSupplier of the wrapped inventory
+func (instrumented *Inventory) GetSupplier(ctx context.Context, supplierId int) (error, data.Supplier) {
+	done := instrumented.start(ctx, "GetSupplier")
+	err, result := instrumented.inventory.GetSupplier(ctx, supplierId)
+	done(err)
+	return err, result
+}
+
+//CreateSupplier calls CreateSupplier of the wrapped inventory
+func (instrumented *Inventory) CreateSupplier(ctx context.Context, supplier data.Supplier) (error, data.Supplier) {
+	done := instrumented.start(ctx, "CreateSupplier")
+	err, result := instrumented.inventory.CreateSupplier(ctx, supplier)
+	done(err)
+	return err, result
+}
+
+//UpdateSupplier calls UpdateSupplier of the wrapped inventory
+func (instrumented *Inventory) UpdateSupplier(ctx context.Context, supplier data.Supplier) (error, data.Supplier) {
+	done := instrumented.start(ctx, "UpdateSupplier")
+	err, result := instrumented.inventory.UpdateSupplier(ctx, supplier)
+	done(err)
+	return err, result
+}
+
+//DeleteSupplier calls DeleteSupplier of the wrapped inventory
+func (instrumented *Inventory) DeleteSupplier(ctx context.Context, supplierId int) error {
+	done := instrumented.start(ctx, "DeleteSupplier")
+	err := instrumented.inventory.DeleteSupplier(ctx, supplierId)
+	done(err)
+	return err
+}
+
+//SaveSupplierArticle calls SaveSupplierArticle of the wrapped inventory
+func (instrumented *Inventory) SaveSupplierArticle(ctx context.Context, article data.SupplierArticle) (error, data.SupplierArticle) {
+	done := instrumented.start(ctx, "SaveSupplierArticle")
+	err, result := instrumented.inventory.SaveSupplierArticle(ctx, article)
+	done(err)
+	return err, result
+}
+
+//DeleteSupplierArticle calls DeleteSupplierArticle of the wrapped inventory
+func (instrumented *Inventory) DeleteSupplierArticle(ctx context.Context, supplierId int, artId string) error {
+	done := instrumented.start(ctx, "DeleteSupplierArticle")
+	err := instrumented.inventory.DeleteSupplierArticle(ctx, supplierId, artId)
+	done(err)
+	return err
+}
+
+//LookupBarcode calls LookupBarcode of the wrapped inventory
+func (instrumented *Inventory) LookupBarcode(ctx context.Context, barcode string) (error, data.ScanResult) {
+	done := instrumented.start(ctx, "LookupBarcode")
+	err, result := instrumented.inventory.LookupBarcode(ctx, barcode)
+	done(err)
+	return err, result
+}
+
+//AdjustStockByScan calls AdjustStockByScan of the wrapped inventory
+func (instrumented *Inventory) AdjustStockByScan(ctx context.Context, barcode string, delta int) (error, data.Stock) {
+	done := instrumented.start(ctx, "AdjustStockByScan")
+	err, result := instrumented.inventory.AdjustStockByScan(ctx, barcode, delta)
+	done(err)
+	return err, result
+}
+
+//CreateImportJob calls CreateImportJob of the wrapped inventory
+func (instrumented *Inventory) CreateImportJob(ctx context.Context, job data.ImportJob) (error, data.ImportJob) {
+	done := instrumented.start(ctx, "CreateImportJob")
+	err, result := instrumented.inventory.CreateImportJob(ctx, job)
+	done(err)
+	return err, result
+}
+
+//GetImportJob calls GetImportJob of the wrapped inventory
+func (instrumented *Inventory) GetImportJob(ctx context.Context, jobId int) (error, data.ImportJob) {
+	done := instrumented.start(ctx, "GetImportJob")
+	err, result := instrumented.inventory.GetImportJob(ctx, jobId)
+	done(err)
+	return err, result
+}
+
+//ClaimImportJob calls ClaimImportJob of the wrapped inventory
+func (instrumented *Inventory) ClaimImportJob(ctx context.Context, worker string, staleAfter time.Duration) (error, *data.ImportJob) {
+	done := instrumented.start(ctx, "ClaimImportJob")
+	err, result := instrumented.inventory.ClaimImportJob(ctx, worker, staleAfter)
+	done(err)
+	return err, result
+}
+
+//UpdateImportJob calls UpdateImportJob of the wrapped inventory
+func (instrumented *Inventory) UpdateImportJob(ctx context.Context, job data.ImportJob) error {
+	done := instrumented.start(ctx, "UpdateImportJob")
+	err := instrumented.inventory.UpdateImportJob(ctx, job)
+	done(err)
+	return err
+}
+
+//RenewImportJob calls RenewImportJob of the wrapped inventory
+func (instrumented *Inventory) RenewImportJob(ctx context.Context, jobId int, worker string) error {
+	done := instrumented.start(ctx, "RenewImportJob")
+	err := instrumented.inventory.RenewImportJob(ctx, jobId, worker)
+	done(err)
+	return err
+}
+
+//GetSnapshot calls GetSnapshot of the wrapped inventory
+func (instrumented *Inventory) GetSnapshot(ctx context.Context) (error, data.Snapshot) {
+	done := instrumented.start(ctx, "GetSnapshot")
+	err, result := instrumented.inventory.GetSnapshot(ctx)
+	done(err)
+	return err, result
+}
+
+//RestoreSnapshot calls RestoreSnapshot of the wrapped inventory
+func (instrumented *Inventory) RestoreSnapshot(ctx context.Context, snapshot data.Snapshot) error {
+	done := instrumented.start(ctx, "RestoreSnapshot")
+	err := instrumented.inventory.RestoreSnapshot(ctx, snapshot)
+	done(err)
+	return err
+}
+
+//ReserveIdempotencyKey calls ReserveIdempotencyKey of the wrapped inventory
+func (instrumented *Inventory) ReserveIdempotencyKey(ctx context.Context, key, method, path string, retention, staleAfter time.Duration) (error, *data.IdempotentResponse) {
+	done := instrumented.start(ctx, "ReserveIdempotencyKey")
+	err, result := instrumented.inventory.ReserveIdempotencyKey(ctx, key, method, path, retention, staleAfter)
+	done(err)
+	return err, result
+}
+
+//SaveIdempotentResponse calls SaveIdempotentResponse of the wrapped inventory
+func (instrumented *Inventory) SaveIdempotentResponse(ctx context.Context, key string, statusCode int, body []byte) error {
+	done := instrumented.start(ctx, "SaveIdempotentResponse")
+	err := instrumented.inventory.SaveIdempotentResponse(ctx, key, statusCode, body)
+	done(err)
+	return err
+}
+
+//ReleaseIdempotencyKey calls ReleaseIdempotencyKey of the wrapped inventory
+func (instrumented *Inventory) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	done := instrumented.start(ctx, "ReleaseIdempotencyKey")
+	err := instrumented.inventory.ReleaseIdempotencyKey(ctx, key)
+	done(err)
+	return err
+}
+
+//CreateAPIKey calls CreateAPIKey of the wrapped inventory
+func (instrumented *Inventory) CreateAPIKey(ctx context.Context, key data.APIKey) (error, data.APIKey) {
+	done := instrumented.start(ctx, "CreateAPIKey")
+	err, result := instrumented.inventory.CreateAPIKey(ctx, key)
+	done(err)
+	return err, result
+}
+
+//GetAPIKeys calls GetAPIKeys of the wrapped inventory
+func (instrumented *Inventory) GetAPIKeys(ctx context.Context) (error, []data.APIKey) {
+	done := instrumented.start(ctx, "GetAPIKeys")
+	err, result := instrumented.inventory.GetAPIKeys(ctx)
+	done(err)
+	return err, result
+}
+
+//GetActiveAPIKey calls GetActiveAPIKey of the wrapped inventory
+func (instrumented *Inventory) GetActiveAPIKey(ctx context.Context, keyHash string) (error, data.APIKey) {
+	done := instrumented.start(ctx, "GetActiveAPIKey")
+	err, result := instrumented.inventory.GetActiveAPIKey(ctx, keyHash)
+	done(err)
+	return err, result
+}
+
+//RevokeAPIKey calls RevokeAPIKey of the wrapped inventory
+func (instrumented *Inventory) RevokeAPIKey(ctx context.Context, keyId int) error {
+	done := instrumented.start(ctx, "RevokeAPIKey")
+	err := instrumented.inventory.RevokeAPIKey(ctx, keyId)
+	done(err)
+	return err
+}
+
+//TakeRateLimitToken calls TakeRateLimitToken of the wrapped inventory
+func (instrumented *Inventory) TakeRateLimitToken(ctx context.Context, key string, limit data.RateLimit) (error, data.RateDecision) {
+	done := instrumented.start(ctx, "TakeRateLimitToken")
+	err, decision := instrumented.inventory.TakeRateLimitToken(ctx, key, limit)
+	done(err)
+	return err, decision
+}
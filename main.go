@@ -1,27 +1,67 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/auknl/warehouse/api"
+	"github.com/auknl/warehouse/auth"
+	"github.com/auknl/warehouse/data"
 	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/instrument"
+	"github.com/auknl/warehouse/metrics"
 	"github.com/auknl/warehouse/postgres"
+	"github.com/auknl/warehouse/request"
+	"github.com/auknl/warehouse/tracing"
 	"github.com/kelseyhightower/envconfig"
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 )
 
+//defaultShutdownGracePeriod is used when the configured grace period cannot be parsed
+const defaultShutdownGracePeriod = 25 * time.Second
+
 //configuration keeps all config info for warehouse service
 type configuration struct {
-	LogLevel       string `mapstructure:"LOGLEVEL" default:"info"`
-	Version        string `mapstructure:"VERSION" required:"true"`
-	Environment    string `mapstructure:"ENVIRONMENT" required:"true"`
-	BackendTimeout string `mapstructure:"BACKENDTIMEOUT" default:"25s"`
-	ListenAddress  string `mapstructure:"LISTENADDRESS" default:":8080"`
-	DBDriver       string `mapstructure:"DBDRIVER" required:"true"`
-	DBHost         string `mapstructure:"DBHOST" required:"true"`
-	DBPort         string `mapstructure:"DBPORT" required:"true"`
-	DBUser         string `mapstructure:"DBUSER" required:"true"`
-	DBPassword     string `mapstructure:"DBPASSWORD" required:"true"`
-	DBName         string `mapstructure:"DBDBNAME" required:"true"`
+	LogLevel              string `mapstructure:"LOGLEVEL" default:"info"`
+	Version               string `mapstructure:"VERSION" required:"true"`
+	Environment           string `mapstructure:"ENVIRONMENT" required:"true"`
+	BackendTimeout        string `mapstructure:"BACKENDTIMEOUT" default:"25s"`
+	ListenAddress         string `mapstructure:"LISTENADDRESS" default:":8080"`
+	IdempotencyRetention  string `mapstructure:"IDEMPOTENCYRETENTION" default:"24h"`
+	IdempotencyStaleAfter string `mapstructure:"IDEMPOTENCYSTALEAFTER" default:"1m"`
+	UploadBatchSize       int    `mapstructure:"UPLOADBATCHSIZE" default:"1000"`
+	JobPollInterval       string `mapstructure:"JOBPOLLINTERVAL" default:"5s"`
+	JobStaleAfter         string `mapstructure:"JOBSTALEAFTER" default:"5m"`
+	ShutdownDelay         string `mapstructure:"SHUTDOWNDELAY" default:"5s"`
+	ShutdownGracePeriod   string `mapstructure:"SHUTDOWNGRACEPERIOD" default:"25s"`
+	LowStockThreshold     int    `mapstructure:"LOWSTOCKTHRESHOLD" default:"5"`
+	TraceExporter         string `mapstructure:"TRACEEXPORTER" default:"none"`
+	TraceFile             string `mapstructure:"TRACEFILE" default:"traces.json"`
+	TraceEndpoint         string `mapstructure:"TRACEENDPOINT"`
+	TraceInsecure         bool   `mapstructure:"TRACEINSECURE" default:"false"`
+	AuthDisabled          bool   `mapstructure:"AUTHDISABLED" default:"false"`
+	AutoMigrate           bool   `mapstructure:"AUTOMIGRATE" default:"false"`
+	RateLimits            string `mapstructure:"RATELIMITS"`
+	RateLimitStore        string `mapstructure:"RATELIMITSTORE" default:"memory"`
+	JWKS                  string `mapstructure:"JWKS"`
+	JWTIssuer             string `mapstructure:"JWTISSUER"`
+	JWTAudience           string `mapstructure:"JWTAUDIENCE"`
+	JWTRoleClaim          string `mapstructure:"JWTROLECLAIM" default:"roles"`
+	JWTTenantClaim        string `mapstructure:"JWTTENANTCLAIM" default:"tenant"`
+	DBDriver              string `mapstructure:"DBDRIVER" required:"true"`
+	DBHost                string `mapstructure:"DBHOST" required:"true"`
+	DBPort                string `mapstructure:"DBPORT" required:"true"`
+	DBUser                string `mapstructure:"DBUSER" required:"true"`
+	DBPassword            string `mapstructure:"DBPASSWORD" required:"true"`
+	DBName                string `mapstructure:"DBDBNAME" required:"true"`
 }
 
 func main() {
@@ -38,6 +78,19 @@ func main() {
 		"service": "inventory",
 	})
 
+	stopTracing, err := tracing.Start(context.Background(), tracing.Config{
+		ServiceName: "inventory",
+		Version:     config.Version,
+		Environment: config.Environment,
+		Exporter:    config.TraceExporter,
+		File:        config.TraceFile,
+		Endpoint:    config.TraceEndpoint,
+		Insecure:    config.TraceInsecure,
+	})
+	if err != nil {
+		loggerEntry.WithField("err", err).Fatal("Could not start tracing")
+	}
+
 	var inventory db.Inventory
 
 	if config.DBDriver == "postgres" {
@@ -51,17 +104,208 @@ func main() {
 			Dbname:   config.DBName,
 		}
 		inventory = postgres.NewPInventory(config)
+	} else {
+		loggerEntry.WithField("driver", config.DBDriver).Fatal("Database driver is not supported")
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = runMigrateCommand(inventory, os.Args[2:], os.Stdout)
+		inventory.Close()
+		if err != nil {
+			loggerEntry.WithField("err", err).Fatal("migrate command failed")
+		}
+		return
 	}
 
-	server := api.NewServer(inventory,
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		err = runAPIKeyCommand(inventory, os.Args[2:], os.Stdout)
+		inventory.Close()
+		if err != nil {
+			loggerEntry.WithField("err", err).Fatal("apikey command failed")
+		}
+		return
+	}
+
+	if migrator, ok := inventory.(db.Migrator); ok {
+		err = checkSchema(migrator, config.AutoMigrate, loggerEntry)
+		if err != nil {
+			inventory.Close()
+			loggerEntry.WithField("err", err).Fatal("Schema is not up to date")
+		}
+	}
+
+	serviceMetrics := metrics.New()
+	if source, ok := inventory.(metrics.StatsSource); ok {
+		serviceMetrics.RegisterDBStats(source)
+	}
+	inventory = instrument.NewInventory(inventory, serviceMetrics.ObserveQuery, tracing.ObserveQuery)
+	backendTimeout, err := time.ParseDuration(config.BackendTimeout)
+	if err != nil {
+		loggerEntry.WithField("err", err).Error("Could not parse backend timeout duration")
+	}
+	serviceMetrics.RegisterLowStock(inventory, config.LowStockThreshold, backendTimeout)
+
+	var tokens *auth.Verifier
+	if config.JWKS != "" {
+		keys, err := auth.LoadKeySet(config.JWKS, loggerEntry)
+		if err != nil {
+			loggerEntry.WithField("err", err).Fatal("Could not load the JWKS")
+		}
+		tokens = auth.NewVerifier(keys, auth.TokenConfig{
+			Issuer:      config.JWTIssuer,
+			Audience:    config.JWTAudience,
+			RoleClaim:   config.JWTRoleClaim,
+			TenantClaim: config.JWTTenantClaim,
+		})
+	}
+
+	server := api.NewServer(inventory, serviceMetrics, tokens,
 		api.Configuration{
-			ListenAddress:  config.ListenAddress,
-			BackendTimeout: config.BackendTimeout},
+			ListenAddress:         config.ListenAddress,
+			BackendTimeout:        config.BackendTimeout,
+			IdempotencyRetention:  config.IdempotencyRetention,
+			IdempotencyStaleAfter: config.IdempotencyStaleAfter,
+			UploadBatchSize:       config.UploadBatchSize,
+			JobPollInterval:       config.JobPollInterval,
+			JobStaleAfter:         config.JobStaleAfter,
+			ShutdownDelay:         config.ShutdownDelay,
+			AuthDisabled:          config.AuthDisabled,
+			RateLimits:            config.RateLimits,
+			RateLimitStore:        config.RateLimitStore},
 		loggerEntry)
 
-	err = server.Start()
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.Start()
+	}()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+	select {
+	case err = <-serverErr:
+		server.Logger.WithField("err", err).Error("cannot start server")
+	case sig := <-signals:
+		server.Logger.WithField("signal", sig.String()).Info("Shutting down")
+		gracePeriod, parseErr := time.ParseDuration(config.ShutdownGracePeriod)
+		if parseErr != nil {
+			server.Logger.WithField("err", parseErr).Error("Could not parse shutdown grace period duration")
+			gracePeriod = defaultShutdownGracePeriod
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+		err = server.Shutdown(ctx)
+		cancel()
+		if err == nil {
+			err = <-serverErr
+		}
+	}
+
+	closeErr := inventory.Close()
+	if closeErr != nil {
+		server.Logger.WithField("err", closeErr).Error("Could not close the database")
+	}
+	stopTracing(context.Background())
 	if err != nil {
-		server.Logger.Fatal("cannot start server:", err)
+		os.Exit(1)
+	}
+	server.Logger.Info("Server is stopped")
+}
+
+//runAPIKeyCommand creates the first API keys without a running server, "apikey create <name> <scope,...> [tenant]"
+//prints the key that is not shown again. The key is of the default tenant when no tenant is given
+func runAPIKeyCommand(inventory db.Inventory, args []string, out io.Writer) error {
+	if (len(args) != 3 && len(args) != 4) || args[0] != "create" {
+		return errors.New("usage: apikey create <name> <scope,...> [tenant]")
+	}
+	tenant := request.DefaultTenant
+	if len(args) == 4 {
+		tenant = args[3]
+	}
+	if !request.ValidTenant(tenant) {
+		return fmt.Errorf("tenant %q is not valid", tenant)
+	}
+	var scopes []data.Scope
+	for _, scope := range strings.Split(args[2], ",") {
+		scopes = append(scopes, data.Scope(strings.TrimSpace(scope)))
+	}
+	apiKey, err := auth.NewAPIKey(args[1], scopes)
+	if err != nil {
+		return err
+	}
+	err, created := inventory.CreateAPIKey(request.WithTenant(context.Background(), tenant), apiKey)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "API key %d %s is created with %s for tenant %s, keep the key, it is not shown again\n%s\n",
+		created.KeyId, created.Name, args[2], tenant, apiKey.Key)
+	return nil
+}
+
+//runMigrateCommand migrates the schema with the migrations embedded in the binary, "migrate up", "migrate down <N>"
+//and "migrate status" print the version of the schema when they are done
+func runMigrateCommand(inventory db.Inventory, args []string, out io.Writer) error {
+	migrator, ok := inventory.(db.Migrator)
+	if !ok {
+		return errors.New("the database does not support migrations")
+	}
+	usage := errors.New("usage: migrate up | migrate down <N> | migrate status")
+	switch {
+	case len(args) == 1 && args[0] == "up":
+		err := migrator.MigrateUp()
+		if err != nil {
+			return err
+		}
+	case len(args) == 2 && args[0] == "down":
+		steps, err := strconv.Atoi(args[1])
+		if err != nil || steps < 1 {
+			return usage
+		}
+		err = migrator.MigrateDown(steps)
+		if err != nil {
+			return err
+		}
+	case len(args) == 1 && args[0] == "status":
+	default:
+		return usage
+	}
+
+	err, status := migrator.SchemaStatus()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, describeSchema(status))
+	return nil
+}
+
+//checkSchema applies the missing migrations when autoMigrate is set, the server does not start on a schema that is
+//behind the migrations of the binary
+func checkSchema(migrator db.Migrator, autoMigrate bool, logger *logrus.Entry) error {
+	if autoMigrate {
+		err := migrator.MigrateUp()
+		if err != nil {
+			return err
+		}
+	}
+	err, status := migrator.SchemaStatus()
+	if err != nil {
+		return err
+	}
+	if status.Behind() {
+		return fmt.Errorf("%s, run \"migrate up\" or set ISC_AUTOMIGRATE=true", describeSchema(status))
+	}
+	logger.WithField("schema_version", status.Version).Info("Schema is up to date")
+	return nil
+}
+
+//describeSchema tells the version of the schema against the latest migration
+func describeSchema(status data.SchemaStatus) string {
+	switch {
+	case status.Dirty:
+		return fmt.Sprintf("schema version %d of %d is dirty, a migration failed halfway and has to be fixed by hand",
+			status.Version, status.Latest)
+	case status.Version < status.Latest:
+		return fmt.Sprintf("schema version %d of %d is behind the migrations", status.Version, status.Latest)
+	default:
+		return fmt.Sprintf("schema version %d of %d is up to date", status.Version, status.Latest)
 	}
 }
 
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"github.com/auknl/warehouse/db"
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"time"
+)
+
+//StatsSource gives the connection pool stats, *sql.DB is one
+type StatsSource interface {
+	Stats() sql.DBStats
+}
+
+//dbStatsCollector collects the connection pool stats on every scrape
+type dbStatsCollector struct {
+	source            StatsSource
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxIdleTimeClosed *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+//newDBStatsCollector creates the collector of the connection pool stats
+func newDBStatsCollector(source StatsSource) *dbStatsCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", name), help, nil, nil)
+	}
+	return &dbStatsCollector{
+		source:            source,
+		maxOpen:           desc("max_open_connections", "Maximum number of open connections to the database."),
+		open:              desc("open_connections", "Number of established connections, both in use and idle."),
+		inUse:             desc("in_use_connections", "Number of connections currently in use."),
+		idle:              desc("idle_connections", "Number of idle connections."),
+		waitCount:         desc("wait_count_total", "Number of connections waited for."),
+		waitDuration:      desc("wait_duration_seconds_total", "Time blocked waiting for a new connection."),
+		maxIdleClosed:     desc("max_idle_closed_total", "Number of connections closed due to the maximum idle connections."),
+		maxIdleTimeClosed: desc("max_idle_time_closed_total", "Number of connections closed due to the maximum idle time."),
+		maxLifetimeClosed: desc("max_lifetime_closed_total", "Number of connections closed due to the maximum connection lifetime."),
+	}
+}
+
+//Describe sends the descriptions of the pool stats
+func (collector *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.maxOpen
+	ch <- collector.open
+	ch <- collector.inUse
+	ch <- collector.idle
+	ch <- collector.waitCount
+	ch <- collector.waitDuration
+	ch <- collector.maxIdleClosed
+	ch <- collector.maxIdleTimeClosed
+	ch <- collector.maxLifetimeClosed
+}
+
+//Collect reads the pool stats and sends them
+func (collector *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := collector.source.Stats()
+	ch <- prometheus.MustNewConstMetric(collector.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(collector.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(collector.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(collector.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(collector.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(collector.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(collector.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(collector.maxIdleTimeClosed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(collector.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
+
+//lowStockCollector counts the articles of every tenant below the stock threshold on every scrape
+type lowStockCollector struct {
+	inventory db.Inventory
+	threshold int
+	timeout   time.Duration
+	lowStock  *prometheus.Desc
+}
+
+//newLowStockCollector creates the collector of the articles below the threshold
+func newLowStockCollector(inventory db.Inventory, threshold int, timeout time.Duration) *lowStockCollector {
+	return &lowStockCollector{
+		inventory: inventory,
+		threshold: threshold,
+		timeout:   timeout,
+		lowStock: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "articles_below_threshold"),
+			"Number of articles whose available stock is below the low stock threshold.", []string{"tenant"},
+			prometheus.Labels{"threshold": strconv.Itoa(threshold)}),
+	}
+}
+
+//Describe sends the description of the low stock gauge
+func (collector *lowStockCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- collector.lowStock
+}
+
+//Collect counts the articles below the threshold of every tenant and sends them per tenant, the gauge is reported
+//as failed if they cannot be counted
+func (collector *lowStockCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collector.timeout)
+	defer cancel()
+	err, counts := collector.inventory.CountLowStockArticles(ctx, collector.threshold)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(collector.lowStock, err)
+		return
+	}
+	for tenant, count := range counts {
+		ch <- prometheus.MustNewConstMetric(collector.lowStock, prometheus.GaugeValue, float64(count), tenant)
+	}
+}
@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"context"
+	"github.com/auknl/warehouse/db"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const namespace = "warehouse"
+
+//reasons of a failed sell
+const (
+	SellFailureUnknownProduct = "unknown_product"
+	SellFailureOutOfStock     = "out_of_stock"
+	SellFailureError          = "error"
+)
+
+//Metrics keeps the collectors of the service and the registry they are exposed from
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	queryErrors     *prometheus.CounterVec
+	sells           prometheus.Counter
+	sellFailures    *prometheus.CounterVec
+}
+
+//New creates the metrics of the service with the go runtime and process collectors
+func New() *Metrics {
+	metrics := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Latency of the inventory database calls by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_errors_total",
+			Help:      "Number of failed inventory database calls by method.",
+		}, []string{"method"}),
+		sells: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sells_total",
+			Help:      "Number of sold products.",
+		}),
+		sellFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sell_failures_total",
+			Help:      "Number of failed sells by reason.",
+		}, []string{"reason"}),
+	}
+	metrics.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		metrics.requests,
+		metrics.requestDuration,
+		metrics.queryDuration,
+		metrics.queryErrors,
+		metrics.sells,
+		metrics.sellFailures,
+	)
+	return metrics
+}
+
+//Handler serves the metrics in the prometheus exposition format, a failing collector does not fail the others
+func (metrics *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(metrics.registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
+
+//ObserveRequest records a served HTTP request
+func (metrics *Metrics) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	if metrics == nil {
+		return
+	}
+	statusCode := strconv.Itoa(status)
+	metrics.requests.WithLabelValues(method, route, statusCode).Inc()
+	metrics.requestDuration.WithLabelValues(method, route, statusCode).Observe(elapsed.Seconds())
+}
+
+//ObserveQuery records the latency and the failure of an inventory call, it is an instrument.Probe
+func (metrics *Metrics) ObserveQuery(ctx context.Context, method string) func(err error) {
+	start := time.Now()
+	return func(err error) {
+		metrics.queryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+		if err != nil {
+			metrics.queryErrors.WithLabelValues(method).Inc()
+		}
+	}
+}
+
+//SellSucceeded counts a sold product
+func (metrics *Metrics) SellSucceeded() {
+	if metrics == nil {
+		return
+	}
+	metrics.sells.Inc()
+}
+
+//SellFailed counts a failed sell with its reason
+func (metrics *Metrics) SellFailed(reason string) {
+	if metrics == nil {
+		return
+	}
+	metrics.sellFailures.WithLabelValues(reason).Inc()
+}
+
+//RegisterDBStats exposes the connection pool stats of the database
+func (metrics *Metrics) RegisterDBStats(source StatsSource) {
+	metrics.registry.MustRegister(newDBStatsCollector(source))
+}
+
+//RegisterLowStock exposes the number of articles whose stock is below the threshold
+func (metrics *Metrics) RegisterLowStock(inventory db.Inventory, threshold int, timeout time.Duration) {
+	metrics.registry.MustRegister(newLowStockCollector(inventory, threshold, timeout))
+}
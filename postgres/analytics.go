@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"time"
+)
+
+//GetTopProducts ranks the products by the units sold or by the revenue of the sales matching the filter and gets
+//the first ones up to the limit
+func (inventory *PInventoryDB) GetTopProducts(ctx context.Context, filter data.SalesFilter, by data.SalesRanking, limit int) (error, []data.TopProduct) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("by", by)
+	log.Debug("GetTopProducts() entry...")
+	query := getTopProductsByUnits
+	if by == data.RankByRevenue {
+		query = getTopProductsByRevenue
+	}
+	rows, err := inventory.db.QueryContext(ctx, query, filter.ProductName, filter.From, filter.To, filter.Currency, request.GetTenant(ctx))
+	if err != nil {
+		log.WithField("err", err).Error("GetTopProducts query failed")
+		return err, nil
+	}
+	defer rows.Close()
+
+	// the rows of a product come one after the other, one per currency
+	products := []data.TopProduct{}
+	for rows.Next() {
+		var productName, currency string
+		var quantity, totalQuantity int
+		var revenue, totalRevenue float64
+		err = rows.Scan(&productName, &currency, &quantity, &revenue, &totalQuantity, &totalRevenue)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, nil
+		}
+		if len(products) == 0 || products[len(products)-1].ProductName != productName {
+			if len(products) == limit {
+				break
+			}
+			products = append(products, data.TopProduct{Rank: len(products) + 1, ProductName: productName, Quantity: totalQuantity})
+		}
+		if currency != "" {
+			product := &products[len(products)-1]
+			if product.Revenue == nil {
+				product.Revenue = make(map[string]float64)
+			}
+			product.Revenue[currency] = revenue
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, nil
+	}
+
+	log.WithField("number of products to be returned: ", len(products)).Debug("GetTopProducts(), returns the products...")
+	return nil, products
+}
+
+//GetSalesSeries gets the sales matching the filter per product and period. Every product has a point for every period
+//of the filtered period, or from its first to its last sale when the filter has no period, the periods without sales
+//are zero
+func (inventory *PInventoryDB) GetSalesSeries(ctx context.Context, filter data.SalesFilter, interval data.SeriesInterval) (error, []data.ProductSeries) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("interval", interval)
+	tenant := request.GetTenant(ctx)
+	log.Debug("GetSalesSeries() entry...")
+	rows, err := inventory.db.QueryContext(ctx, getSalesSeries, filter.ProductName, filter.From, filter.To, filter.Currency, tenant, string(interval))
+	if err != nil {
+		log.WithField("err", err).Error("GetSalesSeries query failed")
+		return err, nil
+	}
+	defer rows.Close()
+
+	var productNames []string
+	points := make(map[string]map[time.Time]*data.SeriesPoint)
+	var first, last time.Time
+	for rows.Next() {
+		var productName, currency string
+		var period time.Time
+		var quantity int
+		var revenue float64
+		err = rows.Scan(&productName, &period, &currency, &quantity, &revenue)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, nil
+		}
+		period = interval.Start(period)
+		if first.IsZero() || period.Before(first) {
+			first = period
+		}
+		if period.After(last) {
+			last = period
+		}
+		if _, ok := points[productName]; !ok {
+			productNames = append(productNames, productName)
+			points[productName] = make(map[time.Time]*data.SeriesPoint)
+		}
+		point, ok := points[productName][period]
+		if !ok {
+			point = &data.SeriesPoint{Period: period}
+			points[productName][period] = point
+		}
+		point.Quantity += quantity
+		if currency != "" {
+			if point.Revenue == nil {
+				point.Revenue = make(map[string]float64)
+			}
+			point.Revenue[currency] = revenue
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, nil
+	}
+
+	if filter.From != nil {
+		first = interval.Start(*filter.From)
+	}
+	if filter.To != nil {
+		last = interval.Start(*filter.To)
+	}
+	series := []data.ProductSeries{}
+	for _, productName := range productNames {
+		productSeries := data.ProductSeries{ProductName: productName}
+		for period := first; !period.After(last); period = interval.Next(period) {
+			point, ok := points[productName][period]
+			if !ok {
+				point = &data.SeriesPoint{Period: period}
+			}
+			productSeries.Points = append(productSeries.Points, *point)
+		}
+		series = append(series, productSeries)
+	}
+
+	log.WithField("number of products to be returned: ", len(series)).Debug("GetSalesSeries(), returns the series...")
+	return nil, series
+}
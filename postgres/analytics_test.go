@@ -0,0 +1,82 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/data"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPInventoryDB_SalesAnalytics(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	for _, sale := range []struct {
+		productName string
+		quantity    int
+		unitPrice   interface{}
+		currency    interface{}
+		soldAt      string
+	}{
+		{"Dining Chair", 2, 49.9, "EUR", "2021-03-01T10:00:00Z"},
+		{"Dining Table", 1, nil, nil, "2021-03-01T12:00:00Z"},
+		{"Dining Chair", 1, 59.9, "USD", "2021-03-03T09:00:00Z"},
+		{"Dining Table", 5, 199.5, "EUR", "2021-04-01T09:00:00Z"},
+	} {
+		_, err := conn.Exec("INSERT INTO sale (product_name, quantity, unit_price, currency, cost_fifo, cost_average, sold_at) VALUES ($1,$2,$3,$4,0,0,$5)",
+			sale.productName, sale.quantity, sale.unitPrice, sale.currency, sale.soldAt)
+		assert.Equal(t, err, nil)
+	}
+	from := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 3, 3, 23, 59, 59, 0, time.UTC)
+	march := data.SalesFilter{From: &from, To: &to}
+
+	err, products := inventory.GetTopProducts(ctx, march, data.RankByUnits, 10)
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, products, []data.TopProduct{
+		{Rank: 1, ProductName: "Dining Chair", Quantity: 3, Revenue: map[string]float64{"EUR": 99.8, "USD": 59.9}},
+		{Rank: 2, ProductName: "Dining Table", Quantity: 1},
+	})
+
+	err, products = inventory.GetTopProducts(ctx, data.SalesFilter{Currency: "EUR"}, data.RankByRevenue, 1)
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, products, []data.TopProduct{
+		{Rank: 1, ProductName: "Dining Table", Quantity: 5, Revenue: map[string]float64{"EUR": 997.5}},
+	})
+
+	err, series := inventory.GetSalesSeries(ctx, march, data.IntervalDay)
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, series, []data.ProductSeries{
+		{ProductName: "Dining Chair", Points: []data.SeriesPoint{
+			{Period: from, Quantity: 2, Revenue: map[string]float64{"EUR": 99.8}},
+			{Period: from.AddDate(0, 0, 1)},
+			{Period: from.AddDate(0, 0, 2), Quantity: 1, Revenue: map[string]float64{"USD": 59.9}},
+		}},
+		{ProductName: "Dining Table", Points: []data.SeriesPoint{
+			{Period: from, Quantity: 1},
+			{Period: from.AddDate(0, 0, 1)},
+			{Period: from.AddDate(0, 0, 2)},
+		}},
+	})
+
+	err, series = inventory.GetSalesSeries(ctx, data.SalesFilter{ProductName: "Dining Table"}, data.IntervalMonth)
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, series, []data.ProductSeries{
+		{ProductName: "Dining Table", Points: []data.SeriesPoint{
+			{Period: from, Quantity: 1},
+			{Period: from.AddDate(0, 1, 0), Quantity: 5, Revenue: map[string]float64{"EUR": 997.5}},
+		}},
+	})
+}
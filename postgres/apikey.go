@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"github.com/lib/pq"
+)
+
+//scanAPIKey scans a row of an API key, the key and its hash are not selected
+func scanAPIKey(row scanner) (data.APIKey, error) {
+	var key data.APIKey
+	var scopes pq.StringArray
+	var revokedAt sql.NullTime
+	err := row.Scan(&key.KeyId, &key.Name, &key.Prefix, &scopes, &key.CreatedAt, &revokedAt, &key.TenantId)
+	if err != nil {
+		return key, err
+	}
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, data.Scope(scope))
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return key, nil
+}
+
+//CreateAPIKey stores the API key with its hash, the key itself is not stored
+func (inventory *PInventoryDB) CreateAPIKey(ctx context.Context, key data.APIKey) (error, data.APIKey) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("name", key.Name)
+	tenant := request.GetTenant(ctx)
+	log.Debug("CreateAPIKey() entry...")
+	scopes := make(pq.StringArray, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	err := inventory.db.QueryRowContext(ctx, insertAPIKey, key.Name, key.Prefix, key.KeyHash, scopes, tenant).
+		Scan(&key.KeyId, &key.CreatedAt)
+	key.TenantId = tenant
+	if err != nil {
+		log.WithField("err: ", err).Error("CreateAPIKey(), failed to insert record...")
+		return err, key
+	}
+
+	log.WithField("key_id", key.KeyId).Debug("CreateAPIKey(), created the key...")
+	return nil, key
+}
+
+//GetAPIKeys gets all API keys, the revoked ones too
+func (inventory *PInventoryDB) GetAPIKeys(ctx context.Context) (error, []data.APIKey) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("GetAPIKeys() entry...")
+	rows, err := inventory.db.QueryContext(ctx, getAPIKeys, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetAPIKeys query failed")
+		return err, nil
+	}
+	defer rows.Close()
+
+	keys := []data.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, nil
+		}
+		keys = append(keys, key)
+	}
+	return rows.Err(), keys
+}
+
+//GetActiveAPIKey gets the API key with the hash, db.ErrAPIKeyNotFound is returned when it does not exist or is revoked
+func (inventory *PInventoryDB) GetActiveAPIKey(ctx context.Context, keyHash string) (error, data.APIKey) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	log.Debug("GetActiveAPIKey() entry...")
+	key, err := scanAPIKey(inventory.db.QueryRowContext(ctx, getActiveAPIKey, keyHash))
+	if err == sql.ErrNoRows {
+		return db.ErrAPIKeyNotFound, key
+	}
+	if err != nil {
+		log.WithField("err", err).Error("GetActiveAPIKey query failed")
+		return err, key
+	}
+	return nil, key
+}
+
+//RevokeAPIKey revokes the API key, revoking a revoked key again changes nothing
+func (inventory *PInventoryDB) RevokeAPIKey(ctx context.Context, keyId int) error {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("key_id", keyId)
+	tenant := request.GetTenant(ctx)
+	log.Debug("RevokeAPIKey() entry...")
+	var count int
+	err := inventory.db.QueryRowContext(ctx, apiKeyExist, keyId, tenant).Scan(&count)
+	if err != nil {
+		log.WithField("err", err).Error("APIKeyExist query failed")
+		return err
+	}
+	if count == 0 {
+		return db.ErrAPIKeyNotFound
+	}
+	_, err = inventory.db.ExecContext(ctx, revokeAPIKey, keyId, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("RevokeAPIKey(), failed to revoke the key...")
+		return err
+	}
+
+	log.Debug("RevokeAPIKey(), revoked the key...")
+	return nil
+}
@@ -0,0 +1,57 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/auth"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPInventoryDB_APIKey(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	apiKey, err := auth.NewAPIKey("till", []data.Scope{data.ScopeProductSell, data.ScopeInventoryRead})
+	assert.Equal(t, err, nil)
+	err, created := inventory.CreateAPIKey(ctx, apiKey)
+	assert.Equal(t, err, nil)
+	assert.Assert(t, created.KeyId != 0)
+	assert.Assert(t, !created.CreatedAt.IsZero())
+
+	err, active := inventory.GetActiveAPIKey(ctx, auth.HashAPIKey(apiKey.Key))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, active.KeyId, created.KeyId)
+	assert.DeepEqual(t, active.Scopes, apiKey.Scopes)
+	assert.Assert(t, active.RevokedAt == nil)
+
+	err, keys := inventory.GetAPIKeys(ctx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(keys), 1)
+	assert.Equal(t, keys[0].Prefix, apiKey.Prefix)
+
+	err = inventory.RevokeAPIKey(ctx, created.KeyId)
+	assert.Equal(t, err, nil)
+	err, _ = inventory.GetActiveAPIKey(ctx, auth.HashAPIKey(apiKey.Key))
+	assert.Equal(t, err, db.ErrAPIKeyNotFound)
+	err, keys = inventory.GetAPIKeys(ctx)
+	assert.Equal(t, err, nil)
+	assert.Assert(t, keys[0].RevokedAt != nil)
+
+	err = inventory.RevokeAPIKey(ctx, created.KeyId)
+	assert.Equal(t, err, nil)
+	err = inventory.RevokeAPIKey(ctx, created.KeyId+1)
+	assert.Equal(t, err, db.ErrAPIKeyNotFound)
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"github.com/lib/pq"
+	"strconv"
+)
+
+//scanner is implemented by both sql.Row and sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+//scanArticle scans a row selected with articleColumns
+func scanArticle(row scanner) (data.Stock, error) {
+	var article data.Stock
+	var weight, length, width, height sql.NullFloat64
+	var attributes []byte
+	var barcodes pq.StringArray
+	err := row.Scan(&article.ArtId, &article.Name, &article.Stock, &article.OnOrder, &article.Unit,
+		&weight, &length, &width, &height, &article.Category, &attributes, &barcodes, &article.UnitCost)
+	if err != nil {
+		return article, err
+	}
+
+	article.WeightKg = weight.Float64
+	if length.Valid || width.Valid || height.Valid {
+		article.Dimensions = &data.Dimensions{LengthCm: length.Float64, WidthCm: width.Float64, HeightCm: height.Float64}
+	}
+	if len(barcodes) > 0 {
+		article.Barcodes = barcodes
+	}
+	err = json.Unmarshal(attributes, &article.Attributes)
+	if err != nil {
+		return article, err
+	}
+	if len(article.Attributes) == 0 {
+		article.Attributes = nil
+	}
+	return article, nil
+}
+
+//insertArticle inserts the article with its master data and barcodes, its stock is the first cost layer
+func insertArticle(ctx context.Context, transaction *sql.Tx, article data.Stock) error {
+	err := writeArticle(ctx, transaction, article, insertStock, insertBarcode)
+	if err != nil {
+		return err
+	}
+	stock, _ := strconv.Atoi(article.Stock)
+	return addCostLayer(ctx, transaction, article.ArtId, stock, article.UnitCost, layerUpload)
+}
+
+//writeArticle writes the article with the stock query and its barcodes with the barcode query
+func writeArticle(ctx context.Context, transaction *sql.Tx, article data.Stock, stockQuery, barcodeQuery string) error {
+	attributes := []byte("{}")
+	if len(article.Attributes) > 0 {
+		var err error
+		attributes, err = json.Marshal(article.Attributes)
+		if err != nil {
+			return err
+		}
+	}
+	var length, width, height sql.NullFloat64
+	if article.Dimensions != nil {
+		length = sql.NullFloat64{Float64: article.Dimensions.LengthCm, Valid: true}
+		width = sql.NullFloat64{Float64: article.Dimensions.WidthCm, Valid: true}
+		height = sql.NullFloat64{Float64: article.Dimensions.HeightCm, Valid: true}
+	}
+	weight := sql.NullFloat64{Float64: article.WeightKg, Valid: article.WeightKg != 0}
+
+	_, err := transaction.ExecContext(ctx, stockQuery, article.ArtId, article.Name, article.Stock, article.Unit,
+		weight, length, width, height, article.Category, attributes, request.GetTenant(ctx))
+	if err != nil {
+		return err
+	}
+	for _, barcode := range article.Barcodes {
+		_, err = transaction.ExecContext(ctx, barcodeQuery, barcode, article.ArtId, request.GetTenant(ctx))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/data"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPInventoryDB_UploadInventoryMasterData(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	article := data.Stock{ArtId: "1", Name: "leg", Stock: "12", Unit: "pcs", WeightKg: 0.4,
+		Dimensions: &data.Dimensions{LengthCm: 70, WidthCm: 4, HeightCm: 4}, Barcodes: []string{"4006381333931", "5901234123457"},
+		Category: "legs", Attributes: map[string]string{"color": "white"}}
+	plain := data.Stock{ArtId: "2", Name: "screw", Stock: "17"}
+	err, inserted := inventory.UploadInventory(ctx, data.Inventory{Inventory: []data.Stock{article, plain}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, inserted, 2)
+
+	err, stock := inventory.GetInventory(ctx)
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, stock, []data.Stock{article, plain})
+
+	err, detail := inventory.GetArticle(ctx, "1")
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, detail, article)
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+)
+
+//LookupBarcode resolves the barcode to the article or, if no article has it, to the product with its stock
+func (inventory *PInventoryDB) LookupBarcode(ctx context.Context, barcode string) (error, data.ScanResult) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("barcode", barcode)
+	tenant := request.GetTenant(ctx)
+	log.Debug("LookupBarcode() entry...")
+	result := data.ScanResult{Barcode: barcode}
+	article, err := scanArticle(inventory.db.QueryRowContext(ctx, getArticleByBarcode, barcode, tenant))
+	if err == nil {
+		result.Article = &article
+		return nil, result
+	}
+	if err != sql.ErrNoRows {
+		log.WithField("err", err).Error("GetArticleByBarcode query failed")
+		return err, result
+	}
+
+	var product data.ProductStock
+	err = inventory.db.QueryRowContext(ctx, getProductByBarcode, barcode, tenant).Scan(&product.Name, &product.AvailableProductNo)
+	if err == sql.ErrNoRows {
+		return db.ErrBarcodeNotFound, result
+	}
+	if err != nil {
+		log.WithField("err", err).Error("GetProductByBarcode query failed")
+		return err, result
+	}
+	result.Product = &product
+	return nil, result
+}
+
+//AdjustStockByScan changes the stock of the article with the barcode by the given delta.
+//The stock cannot go below the amount allocated to orders
+func (inventory *PInventoryDB) AdjustStockByScan(ctx context.Context, barcode string, delta int) (error, data.Stock) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("barcode", barcode)
+	tenant := request.GetTenant(ctx)
+	log.Debug("AdjustStockByScan() entry...")
+	transaction, err := inventory.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, data.Stock{}
+	}
+	defer transaction.Rollback()
+
+	var artId string
+	err = transaction.QueryRowContext(ctx, getBarcodeArticle, barcode, tenant).Scan(&artId)
+	if err == sql.ErrNoRows {
+		var count int
+		err = transaction.QueryRowContext(ctx, productBarcodeExist, barcode, tenant).Scan(&count)
+		if err != nil {
+			log.WithField("err", err).Error("ProductBarcodeExist query failed")
+			return err, data.Stock{}
+		}
+		if count > 0 {
+			return db.ErrBarcodeNotAdjustable, data.Stock{}
+		}
+		return db.ErrBarcodeNotFound, data.Stock{}
+	}
+	if err != nil {
+		log.WithField("err", err).Error("GetBarcodeArticle query failed")
+		return err, data.Stock{}
+	}
+
+	result, err := transaction.ExecContext(ctx, adjustArticleStock, artId, delta, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("AdjustStockByScan(), failed to update inventory...")
+		return err, data.Stock{}
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err, data.Stock{}
+	}
+	if updated == 0 {
+		return db.ErrInsufficientStock, data.Stock{}
+	}
+	if delta > 0 {
+		err = addCostLayerAtAverage(ctx, transaction, artId, delta, layerAdjustment)
+	} else {
+		_, err = consumeCostLayers(ctx, transaction, artId, -delta)
+	}
+	if err != nil {
+		log.WithField("err: ", err).Error("AdjustStockByScan(), failed to update cost layers...")
+		return err, data.Stock{}
+	}
+
+	article, err := scanArticle(transaction.QueryRowContext(ctx, getArticle, artId, tenant))
+	if err != nil {
+		log.WithField("err", err).Error("GetArticle query failed")
+		return err, data.Stock{}
+	}
+	err = transaction.Commit()
+	if err != nil {
+		log.WithField("err: ", err).Error("AdjustStockByScan(), failed to commit...")
+		return err, data.Stock{}
+	}
+
+	log.WithField("art_id", artId).WithField("delta", delta).Debug("AdjustStockByScan(), adjusted the stock...")
+	return nil, article
+}
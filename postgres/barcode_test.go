@@ -0,0 +1,63 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPInventoryDB_ScanBarcode(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	err, _ := inventory.UploadInventory(ctx, data.Inventory{Inventory: []data.Stock{
+		{ArtId: "1", Name: "leg", Stock: "1", Barcodes: []string{"4006381333931"}},
+		{ArtId: "2", Name: "screw", Stock: "16"},
+	}})
+	assert.Equal(t, err, nil)
+	err, _ = inventory.UploadProducts(ctx, data.Products{Products: []data.Product{
+		{Name: "table", Barcodes: []string{"5901234123457"}, ContainArticles: []data.ArticleContain{{ArtId: "2", AmountOf: "4"}}},
+	}})
+	assert.Equal(t, err, nil)
+
+	err, result := inventory.LookupBarcode(ctx, "4006381333931")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, result.Article.ArtId, "1")
+	assert.Equal(t, result.Article.Stock, "1")
+
+	err, result = inventory.LookupBarcode(ctx, "5901234123457")
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, result.Product, &data.ProductStock{Name: "table", AvailableProductNo: "4"})
+
+	err, _ = inventory.LookupBarcode(ctx, "96385074")
+	assert.Equal(t, err, db.ErrBarcodeNotFound)
+
+	err, article := inventory.AdjustStockByScan(ctx, "4006381333931", 1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, article.Stock, "2")
+
+	err, article = inventory.AdjustStockByScan(ctx, "4006381333931", -1)
+	assert.Equal(t, err, nil)
+	err, article = inventory.AdjustStockByScan(ctx, "4006381333931", -1)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, article.Stock, "0")
+
+	err, _ = inventory.AdjustStockByScan(ctx, "4006381333931", -1)
+	assert.Equal(t, err, db.ErrInsufficientStock)
+
+	err, _ = inventory.AdjustStockByScan(ctx, "5901234123457", 1)
+	assert.Equal(t, err, db.ErrBarcodeNotAdjustable)
+}
@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"math"
+)
+
+// sources of the cost layers
+const (
+	layerUpload     = "upload"
+	layerReceipt    = "receipt"
+	layerReturn     = "return"
+	layerAdjustment = "adjustment"
+	layerRestore    = "restore"
+)
+
+//goodsCost is the cost of the stock taken out of an article by both cost methods
+type goodsCost struct {
+	fifo    float64
+	average float64
+}
+
+func (cost *goodsCost) add(other goodsCost) {
+	cost.fifo += other.fifo
+	cost.average += other.average
+}
+
+//addCostLayer puts the quantity into the stock of the article at the unit cost and moves the average cost of the
+//article by it
+func addCostLayer(ctx context.Context, transaction *sql.Tx, artId string, quantity int, unitCost float64, source string) error {
+	tenant := request.GetTenant(ctx)
+	if quantity <= 0 {
+		return nil
+	}
+	// the average is moved by the layers before the new one
+	_, err := transaction.ExecContext(ctx, updateAverageCost, artId, quantity, unitCost, tenant)
+	if err != nil {
+		return err
+	}
+	_, err = transaction.ExecContext(ctx, insertCostLayer, artId, source, quantity, unitCost, tenant)
+	return err
+}
+
+//addCostLayerAtAverage puts the quantity into the stock of the article at its current average cost, used when the
+//stock comes back without a purchase price
+func addCostLayerAtAverage(ctx context.Context, transaction *sql.Tx, artId string, quantity int, source string) error {
+	tenant := request.GetTenant(ctx)
+	var averageCost float64
+	err := transaction.QueryRowContext(ctx, getAverageCost, artId, tenant).Scan(&averageCost)
+	if err != nil {
+		return err
+	}
+	return addCostLayer(ctx, transaction, artId, quantity, averageCost, source)
+}
+
+//consumeCostLayers takes the quantity out of the oldest cost layers of the article. The quantity not covered by the
+//layers is costed at the average cost
+func consumeCostLayers(ctx context.Context, transaction *sql.Tx, artId string, quantity int) (goodsCost, error) {
+	tenant := request.GetTenant(ctx)
+	var cost goodsCost
+	var averageCost float64
+	err := transaction.QueryRowContext(ctx, getAverageCost, artId, tenant).Scan(&averageCost)
+	if err != nil {
+		return cost, err
+	}
+	cost.average = float64(quantity) * averageCost
+
+	rows, err := transaction.QueryContext(ctx, lockOpenCostLayers, artId, tenant)
+	if err != nil {
+		return cost, err
+	}
+	type layer struct {
+		id        int
+		remaining int
+		unitCost  float64
+	}
+	var layers []layer
+	for rows.Next() {
+		var l layer
+		err = rows.Scan(&l.id, &l.remaining, &l.unitCost)
+		if err != nil {
+			rows.Close()
+			return cost, err
+		}
+		layers = append(layers, l)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return cost, err
+	}
+
+	left := quantity
+	for _, l := range layers {
+		if left == 0 {
+			break
+		}
+		taken := l.remaining
+		if taken > left {
+			taken = left
+		}
+		_, err = transaction.ExecContext(ctx, consumeCostLayer, l.id, taken, tenant)
+		if err != nil {
+			return cost, err
+		}
+		cost.fifo += float64(taken) * l.unitCost
+		left -= taken
+	}
+	cost.fifo += float64(left) * averageCost
+	return cost, nil
+}
+
+//resetCostLayers drops the cost layers and the average cost of the article, before its stock is set from scratch
+func resetCostLayers(ctx context.Context, transaction *sql.Tx, artId string) error {
+	tenant := request.GetTenant(ctx)
+	_, err := transaction.ExecContext(ctx, deleteCostLayers, artId, tenant)
+	if err != nil {
+		return err
+	}
+	_, err = transaction.ExecContext(ctx, deleteAverageCost, artId, tenant)
+	return err
+}
+
+//GetValuation values the stock of every article and sums the cost of the goods sold by the cost method
+func (inventory *PInventoryDB) GetValuation(ctx context.Context, method data.CostMethod) (error, data.Valuation) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("method", method)
+	tenant := request.GetTenant(ctx)
+	log.Debug("GetValuation() entry...")
+	valuation := data.Valuation{Method: method, Articles: []data.ArticleValuation{}}
+	transaction, err := inventory.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, valuation
+	}
+	defer transaction.Rollback()
+
+	query := getFIFOValuation
+	if method == data.CostWeightedAverage {
+		query = getAverageValuation
+	}
+	rows, err := transaction.QueryContext(ctx, query, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetValuation query failed")
+		return err, valuation
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var article data.ArticleValuation
+		err = rows.Scan(&article.ArtId, &article.Name, &article.Quantity, &article.Value)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, valuation
+		}
+		if article.Quantity > 0 {
+			article.UnitCost = roundCost(article.Value / float64(article.Quantity))
+		}
+		valuation.TotalValue += article.Value
+		valuation.Articles = append(valuation.Articles, article)
+	}
+	if err = rows.Err(); err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, valuation
+	}
+	valuation.TotalValue = roundCost(valuation.TotalValue)
+
+	var fifo, average float64
+	err = transaction.QueryRowContext(ctx, getCostOfGoodsSold, tenant).Scan(&fifo, &average)
+	if err != nil {
+		log.WithField("err", err).Error("GetCostOfGoodsSold query failed")
+		return err, valuation
+	}
+	valuation.CostOfGoodsSold = fifo
+	if method == data.CostWeightedAverage {
+		valuation.CostOfGoodsSold = average
+	}
+
+	log.WithField("total value", valuation.TotalValue).Debug("GetValuation(), returns the valuation...")
+	return nil, valuation
+}
+
+//roundCost rounds the cost to the precision it is stored with
+func roundCost(cost float64) float64 {
+	return math.Round(cost*10000) / 10000
+}
+
+//recordSale takes the articles of the sold products out of their cost layers and records the sale of the quantity
+//with its cost
+func recordSale(ctx context.Context, transaction *sql.Tx, productName string, quantity int) error {
+	tenant := request.GetTenant(ctx)
+	rows, err := transaction.QueryContext(ctx, getProductArticles, productName, tenant)
+	if err != nil {
+		return err
+	}
+	amounts := make(map[string]int)
+	var artIds []string
+	for rows.Next() {
+		var artId string
+		var amount int
+		err = rows.Scan(&artId, &amount)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		artIds = append(artIds, artId)
+		amounts[artId] = amount * quantity
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	var cost goodsCost
+	for _, artId := range artIds {
+		articleCost, err := consumeCostLayers(ctx, transaction, artId, amounts[artId])
+		if err != nil {
+			return err
+		}
+		cost.add(articleCost)
+	}
+	_, err = transaction.ExecContext(ctx, insertSale, productName, quantity, roundCost(cost.fifo), roundCost(cost.average), tenant)
+	return err
+}
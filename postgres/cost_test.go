@@ -0,0 +1,76 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/data"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPInventoryDB_GetValuation(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	err, _ := inventory.UploadInventory(ctx, data.Inventory{Inventory: []data.Stock{
+		{ArtId: "1", Name: "leg", Stock: "10", UnitCost: 2},
+		{ArtId: "2", Name: "screw", Stock: "40", UnitCost: 0.1},
+	}})
+	assert.Equal(t, err, nil)
+	err, _ = inventory.UploadProducts(ctx, data.Products{Products: []data.Product{
+		{Name: "Dining Chair", ContainArticles: []data.ArticleContain{{ArtId: "1", AmountOf: "4"}, {ArtId: "2", AmountOf: "8"}}},
+	}})
+	assert.Equal(t, err, nil)
+
+	//the second layer of legs is more expensive, the average cost of the legs is 2.5
+	err, purchaseOrder := inventory.CreatePurchaseOrder(ctx, data.PurchaseOrder{Lines: []data.PurchaseOrderLine{{ArtId: "1", Expected: 10}}})
+	assert.Equal(t, err, nil)
+	err, _ = inventory.ReceiveGoods(ctx, data.GoodsReceipt{
+		PurchaseOrderId: purchaseOrder.PurchaseOrderId,
+		Lines:           []data.ReceiptLine{{ArtId: "1", Quantity: 10, UnitCost: 3}},
+	})
+	assert.Equal(t, err, nil)
+
+	for i := 0; i < 3; i++ {
+		err = inventory.SellProduct(ctx, "Dining Chair")
+		assert.Equal(t, err, nil)
+	}
+
+	err, valuation := inventory.GetValuation(ctx, data.CostFIFO)
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, valuation, data.Valuation{
+		Method: data.CostFIFO,
+		Articles: []data.ArticleValuation{
+			{ArtId: "1", Name: "leg", Quantity: 8, UnitCost: 3, Value: 24},
+			{ArtId: "2", Name: "screw", Quantity: 16, UnitCost: 0.1, Value: 1.6},
+		},
+		TotalValue:      25.6,
+		CostOfGoodsSold: 28.4,
+	})
+
+	err, valuation = inventory.GetValuation(ctx, data.CostWeightedAverage)
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, valuation, data.Valuation{
+		Method: data.CostWeightedAverage,
+		Articles: []data.ArticleValuation{
+			{ArtId: "1", Name: "leg", Quantity: 8, UnitCost: 2.5, Value: 20},
+			{ArtId: "2", Name: "screw", Quantity: 16, UnitCost: 0.1, Value: 1.6},
+		},
+		TotalValue:      21.6,
+		CostOfGoodsSold: 32.4,
+	})
+
+	err, stock := inventory.GetInventory(ctx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stock[0].UnitCost, 2.5)
+}
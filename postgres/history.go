@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"strconv"
+	"time"
+)
+
+//scanArticles scans all rows selected with the article columns
+func scanArticles(rows *sql.Rows) ([]data.Stock, error) {
+	var stocks []data.Stock
+	for rows.Next() {
+		stock, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, stock)
+	}
+	return stocks, rows.Err()
+}
+
+//scanProductStocks scans the product name and available product number rows, the products out of stock are skipped
+func scanProductStocks(rows *sql.Rows) (data.ProductStocks, error) {
+	var productName string
+	var stock string
+	var stocks data.ProductStocks
+	for rows.Next() {
+		err := rows.Scan(&productName, &stock)
+		if err != nil {
+			return nil, err
+		}
+		stockNo, _ := strconv.ParseInt(stock, 10, 64)
+		if stockNo != 0 { // if product items are enough
+			stocks = append(stocks, data.ProductStock{Name: productName, AvailableProductNo: stock})
+		}
+	}
+	return stocks, rows.Err()
+}
+
+//GetInventoryAsOf rebuilds the inventory as it was at the given time from the inventory history
+func (inventory *PInventoryDB) GetInventoryAsOf(ctx context.Context, asOf time.Time) (error, []data.Stock) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.WithField("as_of", asOf).Debug("GetInventoryAsOf() entry...")
+	rows, err := inventory.db.QueryContext(ctx, getInventoryAsOf, asOf, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetInventoryAsOf query failed")
+		return err, nil
+	}
+
+	defer rows.Close()
+	stocks, err := scanArticles(rows)
+	if err != nil {
+		log.WithField("err", err).Error("Cannot scan the inventory history")
+		return err, nil
+	}
+
+	log.WithField("number of inventory record to be returned: ", len(stocks)).Debug("GetInventoryAsOf(), returns the stocks...")
+	return nil, stocks
+}
+
+//GetProductStockAsOf calculates the stock of the available products at the given time from the inventory and
+//product history
+func (inventory *PInventoryDB) GetProductStockAsOf(ctx context.Context, asOf time.Time) (error, data.ProductStocks) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.WithField("as_of", asOf).Debug("GetProductStockAsOf() entry...")
+	rows, err := inventory.db.QueryContext(ctx, getProductStockAsOf, asOf, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetProductStockAsOf query failed")
+		return err, nil
+	}
+
+	defer rows.Close()
+	stocks, err := scanProductStocks(rows)
+	if err != nil {
+		log.WithField("err", err).Error("Cannot scan the product history")
+		return err, nil
+	}
+
+	log.WithField("number of product to be returned: ", len(stocks)).Debug("GetProductStockAsOf(), returns the stocks...")
+	return nil, stocks
+}
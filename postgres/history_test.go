@@ -0,0 +1,60 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/data"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPInventoryDB_AsOf(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	err, _ := inventory.UploadInventory(ctx, data.Inventory{Inventory: []data.Stock{
+		{ArtId: "1", Name: "leg", Stock: "12"},
+		{ArtId: "2", Name: "screw", Stock: "17"},
+	}})
+	assert.Equal(t, err, nil)
+	err, _ = inventory.UploadProducts(ctx, data.Products{Products: []data.Product{
+		{Name: "Dining Chair", ContainArticles: []data.ArticleContain{{ArtId: "1", AmountOf: "4"}, {ArtId: "2", AmountOf: "8"}}},
+	}})
+	assert.Equal(t, err, nil)
+	var beforeSale time.Time
+	err = conn.QueryRow("SELECT now()").Scan(&beforeSale)
+	assert.Equal(t, err, nil)
+
+	err = inventory.SellProduct(ctx, "Dining Chair")
+	assert.Equal(t, err, nil)
+
+	err, stocks := inventory.GetInventoryAsOf(ctx, beforeSale)
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, stocks, []data.Stock{{ArtId: "1", Name: "leg", Stock: "12"}, {ArtId: "2", Name: "screw", Stock: "17"}})
+	err, productStocks := inventory.GetProductStockAsOf(ctx, beforeSale)
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, productStocks, data.ProductStocks{{Name: "Dining Chair", AvailableProductNo: "2"}})
+
+	err, stocks = inventory.GetInventoryAsOf(ctx, time.Now().Add(time.Hour))
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, stocks, []data.Stock{{ArtId: "1", Name: "leg", Stock: "8"}, {ArtId: "2", Name: "screw", Stock: "9"}})
+	err, productStocks = inventory.GetProductStockAsOf(ctx, time.Now().Add(time.Hour))
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, productStocks, data.ProductStocks{{Name: "Dining Chair", AvailableProductNo: "1"}})
+
+	// nothing was recorded before the articles were uploaded
+	err, stocks = inventory.GetInventoryAsOf(ctx, beforeSale.Add(-time.Hour))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(stocks), 0)
+}
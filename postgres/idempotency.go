@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"time"
+)
+
+//ReserveIdempotencyKey reserves the key for the request. If the key was already used within the retention
+//window, the stored response is returned, or an error if the first request is still in progress. A reservation in
+//progress for longer than staleAfter is left by a crashed instance and is taken over by the same request
+func (inventory *PInventoryDB) ReserveIdempotencyKey(ctx context.Context, key, method, path string, retention, staleAfter time.Duration) (error, *data.IdempotentResponse) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("ReserveIdempotencyKey() entry...")
+	transaction, err := inventory.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, nil
+	}
+	defer transaction.Rollback()
+
+	_, err = transaction.ExecContext(ctx, deleteExpiredIdempotencyKeys, time.Now().Add(-retention))
+	if err != nil {
+		log.WithField("err: ", err).Error("ReserveIdempotencyKey(), failed to delete expired keys...")
+		return err, nil
+	}
+
+	result, err := transaction.ExecContext(ctx, reserveIdempotencyKey, key, method, path, tenant, time.Now().Add(-staleAfter))
+	if err != nil {
+		log.WithField("err: ", err).Error("ReserveIdempotencyKey(), failed to insert key...")
+		return err, nil
+	}
+	reserved, err := result.RowsAffected()
+	if err != nil {
+		log.WithField("err: ", err).Error("ReserveIdempotencyKey(), failed to read affected rows...")
+		return err, nil
+	}
+	if reserved == 1 {
+		err = transaction.Commit()
+		if err != nil {
+			log.WithField("err: ", err).Error("ReserveIdempotencyKey(), failed to commit...")
+			return err, nil
+		}
+		log.WithField("key", key).Debug("ReserveIdempotencyKey(), key is reserved...")
+		return nil, nil
+	}
+
+	stored := data.IdempotentResponse{Key: key}
+	var completed bool
+	err = transaction.QueryRowContext(ctx, getIdempotencyKey, key, tenant).Scan(&stored.Method, &stored.Path, &completed, &stored.StatusCode, &stored.Body)
+	if err != nil {
+		log.WithField("err", err).Error("Cannot scan the table")
+		return err, nil
+	}
+	if stored.Method != method || stored.Path != path {
+		log.WithField("key", key).Info("idempotency key is reused for a different request")
+		return db.ErrIdempotencyKeyReused, nil
+	}
+	if !completed {
+		log.WithField("key", key).Info("request with the idempotency key is in progress")
+		return db.ErrIdempotencyInProgress, nil
+	}
+
+	log.WithField("key", key).Debug("ReserveIdempotencyKey(), returns the stored response...")
+	return nil, &stored
+}
+
+//SaveIdempotentResponse stores the response of the request that reserved the key
+func (inventory *PInventoryDB) SaveIdempotentResponse(ctx context.Context, key string, statusCode int, body []byte) error {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("SaveIdempotentResponse() entry...")
+	_, err := inventory.db.ExecContext(ctx, saveIdempotentResponse, key, statusCode, body, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("SaveIdempotentResponse(), failed to store response...")
+		return err
+	}
+	return nil
+}
+
+//ReleaseIdempotencyKey removes an uncompleted reservation so the request can be retried with the same key
+func (inventory *PInventoryDB) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("ReleaseIdempotencyKey() entry...")
+	_, err := inventory.db.ExecContext(ctx, releaseIdempotencyKey, key, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("ReleaseIdempotencyKey(), failed to release key...")
+		return err
+	}
+	return nil
+}
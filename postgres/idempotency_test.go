@@ -0,0 +1,95 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPInventoryDB_ReserveIdempotencyKey(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	err, stored := inventory.ReserveIdempotencyKey(ctx, "key", "POST", "/sell", time.Hour, time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Assert(t, stored == nil)
+
+	//first request is not completed yet
+	err, _ = inventory.ReserveIdempotencyKey(ctx, "key", "POST", "/sell", time.Hour, time.Minute)
+	assert.Equal(t, err, db.ErrIdempotencyInProgress)
+
+	err, _ = inventory.ReserveIdempotencyKey(ctx, "key", "POST", "/other", time.Hour, time.Minute)
+	assert.Equal(t, err, db.ErrIdempotencyKeyReused)
+
+	err = inventory.SaveIdempotentResponse(ctx, "key", 200, []byte(`{"message":"sold"}`))
+	assert.Equal(t, err, nil)
+
+	err, stored = inventory.ReserveIdempotencyKey(ctx, "key", "POST", "/sell", time.Hour, time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stored.StatusCode, 200)
+	assert.Equal(t, string(stored.Body), `{"message":"sold"}`)
+}
+
+func TestPInventoryDB_ReleaseIdempotencyKey(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	err, _ := inventory.ReserveIdempotencyKey(ctx, "key", "POST", "/sell", time.Hour, time.Minute)
+	assert.Equal(t, err, nil)
+	err = inventory.ReleaseIdempotencyKey(ctx, "key")
+	assert.Equal(t, err, nil)
+
+	//released key can be reserved again
+	err, stored := inventory.ReserveIdempotencyKey(ctx, "key", "POST", "/sell", time.Hour, time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Assert(t, stored == nil)
+}
+
+func TestPInventoryDB_ReserveStaleIdempotencyKey(t *testing.T) { //A reservation left in progress by a crashed instance
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	err, _ := inventory.ReserveIdempotencyKey(ctx, "key", "POST", "/sell", time.Hour, time.Minute)
+	assert.Equal(t, err, nil)
+	_, err = conn.Exec("UPDATE idempotency_key SET reserved_at=now()-interval '2 minutes'")
+	assert.Equal(t, err, nil)
+
+	//another request can not take the stale reservation over
+	err, _ = inventory.ReserveIdempotencyKey(ctx, "key", "POST", "/other", time.Hour, time.Minute)
+	assert.Equal(t, err, db.ErrIdempotencyKeyReused)
+
+	err, stored := inventory.ReserveIdempotencyKey(ctx, "key", "POST", "/sell", time.Hour, time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Assert(t, stored == nil)
+
+	//the reservation that is taken over is in progress again
+	err, _ = inventory.ReserveIdempotencyKey(ctx, "key", "POST", "/sell", time.Hour, time.Minute)
+	assert.Equal(t, err, db.ErrIdempotencyInProgress)
+}
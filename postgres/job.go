@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"time"
+)
+
+//CreateImportJob queues the import job with its payload
+func (inventory *PInventoryDB) CreateImportJob(ctx context.Context, job data.ImportJob) (error, data.ImportJob) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("CreateImportJob() entry...")
+	created, err := scanImportJob(inventory.db.QueryRowContext(ctx, insertImportJob, job.Kind, data.JobQueued, job.Payload, job.Total, tenant))
+	if err != nil {
+		log.WithField("err: ", err).Error("CreateImportJob(), failed to insert import job...")
+		return err, job
+	}
+
+	log.WithField("job id: ", created.JobId).Debug("CreateImportJob(), queued the import job...")
+	return nil, created
+}
+
+//GetImportJob gets the state, progress and record errors of the import job without its payload
+func (inventory *PInventoryDB) GetImportJob(ctx context.Context, jobId int) (error, data.ImportJob) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("GetImportJob() entry...")
+	job, err := scanImportJob(inventory.db.QueryRowContext(ctx, getImportJob, jobId, tenant))
+	if err == sql.ErrNoRows {
+		return db.ErrImportJobNotFound, job
+	}
+	if err != nil {
+		log.WithField("err", err).Error("GetImportJob query failed")
+		return err, job
+	}
+	return nil, job
+}
+
+//ClaimImportJob leases the oldest queued job to the worker. Running jobs not updated within staleAfter are taken over,
+//so the jobs of a stopped process are continued. Nil is returned when there is no job to run
+func (inventory *PInventoryDB) ClaimImportJob(ctx context.Context, worker string, staleAfter time.Duration) (error, *data.ImportJob) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	log.Debug("ClaimImportJob() entry...")
+	var payload []byte
+	var tenant string
+	job, err := scanImportJob(inventory.db.QueryRowContext(ctx, claimImportJob, worker, time.Now().Add(-staleAfter)), &payload, &tenant)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		log.WithField("err", err).Error("ClaimImportJob query failed")
+		return err, nil
+	}
+	job.Payload = payload
+	job.Worker = worker
+	job.TenantId = tenant
+
+	log.WithField("job id: ", job.JobId).Debug("ClaimImportJob(), claimed the import job...")
+	return nil, &job
+}
+
+//UpdateImportJob saves the progress of the job, the payload is dropped once the job is finished.
+//ErrImportJobLost is returned if the job is leased to another worker meanwhile
+func (inventory *PInventoryDB) UpdateImportJob(ctx context.Context, job data.ImportJob) error {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("job_id", job.JobId)
+	log.Debug("UpdateImportJob() entry...")
+	recordErrors := job.Errors
+	if recordErrors == nil {
+		recordErrors = []data.RecordError{}
+	}
+	errorsJSON, err := json.Marshal(recordErrors)
+	if err != nil {
+		return err
+	}
+
+	query := updateImportJob
+	if job.State.IsFinished() {
+		query = finishImportJob
+	}
+	result, err := inventory.db.ExecContext(ctx, query, job.JobId, job.Worker, job.State, job.Processed, job.Records, job.Batches, errorsJSON, request.GetTenant(ctx))
+	if err != nil {
+		log.WithField("err: ", err).Error("UpdateImportJob(), failed to update import job...")
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return db.ErrImportJobLost
+	}
+	return nil
+}
+
+//RenewImportJob extends the lease of the worker on the job, so the job is not taken over while a long batch runs.
+//ErrImportJobLost is returned if the job is leased to another worker meanwhile
+func (inventory *PInventoryDB) RenewImportJob(ctx context.Context, jobId int, worker string) error {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("job_id", jobId)
+	log.Debug("RenewImportJob() entry...")
+	result, err := inventory.db.ExecContext(ctx, renewImportJob, jobId, worker, request.GetTenant(ctx))
+	if err != nil {
+		log.WithField("err: ", err).Error("RenewImportJob(), failed to renew import job...")
+		return err
+	}
+	renewed, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if renewed == 0 {
+		return db.ErrImportJobLost
+	}
+	return nil
+}
+
+//scanImportJob scans a row selected with importJobColumns followed by the extra columns
+func scanImportJob(row scanner, extra ...interface{}) (data.ImportJob, error) {
+	var job data.ImportJob
+	var recordErrors []byte
+	err := row.Scan(append([]interface{}{&job.JobId, &job.Kind, &job.State, &job.Total, &job.Processed, &job.Records,
+		&job.Batches, &recordErrors, &job.CreatedAt, &job.UpdatedAt}, extra...)...)
+	if err != nil {
+		return job, err
+	}
+	err = json.Unmarshal(recordErrors, &job.Errors)
+	if err != nil {
+		return job, err
+	}
+	if len(job.Errors) == 0 {
+		job.Errors = nil
+	}
+	return job, nil
+}
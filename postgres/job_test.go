@@ -0,0 +1,74 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPInventoryDB_ImportJob(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	payload := []byte(`{"art_id":"1","name":"leg","stock":"12"}` + "\n")
+	err, job := inventory.CreateImportJob(ctx, data.ImportJob{Kind: data.ImportInventory, Total: 1, Payload: payload})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, job.State, data.JobQueued)
+
+	err, claimed := inventory.ClaimImportJob(ctx, "first", time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, claimed.JobId, job.JobId)
+	assert.Equal(t, claimed.State, data.JobRunning)
+	assert.DeepEqual(t, claimed.Payload, payload)
+
+	// a running job is not claimed again until it is stale
+	err, other := inventory.ClaimImportJob(ctx, "second", time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Assert(t, other == nil)
+	err, other = inventory.ClaimImportJob(ctx, "second", -time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, other.JobId, job.JobId)
+
+	// the first worker lost its lease
+	claimed.Processed = 1
+	err = inventory.UpdateImportJob(ctx, *claimed)
+	assert.Equal(t, err, db.ErrImportJobLost)
+	err = inventory.RenewImportJob(ctx, claimed.JobId, claimed.Worker)
+	assert.Equal(t, err, db.ErrImportJobLost)
+	err = inventory.RenewImportJob(ctx, other.JobId, other.Worker)
+	assert.Equal(t, err, nil)
+
+	other.State = data.JobPartiallySucceeded
+	other.Processed, other.Records, other.Batches = 1, 0, 1
+	other.Errors = []data.RecordError{{Record: 1, Message: "duplicate key"}}
+	err = inventory.UpdateImportJob(ctx, *other)
+	assert.Equal(t, err, nil)
+
+	err, finished := inventory.GetImportJob(ctx, job.JobId)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, finished.State, data.JobPartiallySucceeded)
+	assert.Equal(t, finished.Processed, 1)
+	assert.DeepEqual(t, finished.Errors, other.Errors)
+
+	// finished jobs are not claimed anymore
+	err, other = inventory.ClaimImportJob(ctx, "third", -time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Assert(t, other == nil)
+
+	err, _ = inventory.GetImportJob(ctx, job.JobId+1)
+	assert.Equal(t, err, db.ErrImportJobNotFound)
+}
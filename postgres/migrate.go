@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	migratepostgres "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/httpfs"
+	"net/http"
+	"os"
+)
+
+//MigrateUp applies the embedded migrations the schema misses
+func (inventory *PInventoryDB) MigrateUp() error {
+	inventory.config.Logger.Debug("MigrateUp() entry...")
+	return inventory.migrate(func(migration *migrate.Migrate, _ uint) error {
+		return migration.Up()
+	})
+}
+
+//MigrateDown reverts the last steps migrations of the schema
+func (inventory *PInventoryDB) MigrateDown(steps int) error {
+	inventory.config.Logger.WithField("steps", steps).Debug("MigrateDown() entry...")
+	if steps < 1 {
+		return errors.New("number of the migrations to revert must be at least 1")
+	}
+	return inventory.migrate(func(migration *migrate.Migrate, _ uint) error {
+		return migration.Steps(-steps)
+	})
+}
+
+//SchemaStatus gets the version of the schema and the latest version of the embedded migrations
+func (inventory *PInventoryDB) SchemaStatus() (error, data.SchemaStatus) {
+	inventory.config.Logger.Debug("SchemaStatus() entry...")
+	var status data.SchemaStatus
+	err := inventory.migrate(func(migration *migrate.Migrate, latest uint) error {
+		var err error
+		status.Latest = latest
+		status.Version, status.Dirty, err = migration.Version()
+		if err == migrate.ErrNilVersion {
+			return nil
+		}
+		return err
+	})
+	return err, status
+}
+
+//migrate runs the step with the embedded migrations and their latest version on a connection of its own, the migrate
+//driver closes the connection it is given
+func (inventory *PInventoryDB) migrate(step func(migration *migrate.Migrate, latest uint) error) error {
+	sourceDriver, err := httpfs.New(http.FS(migrations.FS), ".")
+	if err != nil {
+		return err
+	}
+	latest, err := latestVersion(sourceDriver)
+	if err != nil {
+		sourceDriver.Close()
+		return err
+	}
+
+	conn, err := sql.Open(inventory.config.Driver, inventory.dataSourceName())
+	if err != nil {
+		sourceDriver.Close()
+		return err
+	}
+	databaseDriver, err := migratepostgres.WithInstance(conn, &migratepostgres.Config{})
+	if err != nil {
+		sourceDriver.Close()
+		conn.Close()
+		return err
+	}
+	migration, err := migrate.NewWithInstance("httpfs", sourceDriver, inventory.config.Dbname, databaseDriver)
+	if err != nil {
+		sourceDriver.Close()
+		databaseDriver.Close()
+		return err
+	}
+	defer migration.Close()
+
+	err = step(migration, latest)
+	if err == migrate.ErrNoChange {
+		return nil
+	}
+	if err != nil {
+		inventory.config.Logger.WithField("err: ", err).Error("migrate(), migration failed...")
+	}
+	return err
+}
+
+//latestVersion walks the migrations of the source to the last one
+func latestVersion(sourceDriver source.Driver) (uint, error) {
+	version, err := sourceDriver.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := sourceDriver.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
@@ -0,0 +1,57 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"testing"
+)
+
+func TestPInventoryDB_Migrate(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	password, _ := DockerDBURL.User.Password()
+	port := DockerDBURL.Port()
+	if port == "" {
+		port = "5432"
+	}
+	inventory := &PInventoryDB{
+		db: DockerDBConn.Conn,
+		config: Config{
+			Logger:   logrus.NewEntry(logrus.New()),
+			Driver:   "postgres",
+			Host:     DockerDBURL.Hostname(),
+			Port:     port,
+			User:     DockerDBURL.User.Username(),
+			Password: password,
+			Dbname:   DockerDBURL.Path,
+		},
+	}
+
+	//the test database is migrated from the files, the embedded migrations are the same
+	err, status := inventory.SchemaStatus()
+	assert.Equal(t, err, nil)
+	assert.Assert(t, status.Latest > 1)
+	assert.Equal(t, status.Version, status.Latest)
+	assert.Equal(t, status.Behind(), false)
+
+	err = inventory.MigrateDown(1)
+	assert.Equal(t, err, nil)
+	err, status = inventory.SchemaStatus()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, status.Version, status.Latest-1)
+	assert.Equal(t, status.Behind(), true)
+
+	err = inventory.MigrateUp()
+	assert.Equal(t, err, nil)
+	err, status = inventory.SchemaStatus()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, status.Version, status.Latest)
+
+	//nothing is left to apply
+	err = inventory.MigrateUp()
+	assert.Equal(t, err, nil)
+	err = inventory.MigrateDown(0)
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,356 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"github.com/sirupsen/logrus"
+	"sort"
+)
+
+//CreateOrder inserts the order and allocates the stock of its articles. If the stock is not enough,
+//the order stays in created status and can be allocated later
+func (inventory *PInventoryDB) CreateOrder(ctx context.Context, order data.Order) (error, data.Order) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("CreateOrder() entry...")
+	transaction, err := inventory.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, order
+	}
+	defer transaction.Rollback()
+
+	var orderId int
+	err = transaction.QueryRowContext(ctx, insertOrder, data.OrderCreated, tenant).Scan(&orderId)
+	if err != nil {
+		log.WithField("err: ", err).Error("CreateOrder(), failed to insert order...")
+		return err, order
+	}
+
+	quantities := make(map[string]int)
+	for _, line := range order.Lines {
+		quantities[line.ProductName] += line.Quantity
+	}
+	var lines []data.OrderLine
+	for product, quantity := range quantities {
+		_, err = transaction.ExecContext(ctx, insertOrderLine, orderId, product, quantity, tenant)
+		if err != nil {
+			log.WithField("err: ", err).Error("CreateOrder(), failed to insert order line...")
+			return err, order
+		}
+		lines = append(lines, data.OrderLine{ProductName: product, Quantity: quantity})
+	}
+
+	status := data.OrderAllocated
+	err = allocateOrder(ctx, transaction, log, orderId, lines)
+	if err == db.ErrInsufficientStock {
+		status = data.OrderCreated
+	} else if err != nil {
+		return err, order
+	}
+	_, err = transaction.ExecContext(ctx, updateOrderStatus, orderId, status, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("CreateOrder(), failed to update order status...")
+		return err, order
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		log.WithField("err: ", err).Error("CreateOrder(), failed to commit...")
+		return err, order
+	}
+
+	log.WithField("order id: ", orderId).Debug("CreateOrder(), created the order...")
+	return inventory.GetOrder(ctx, orderId)
+}
+
+//GetOrders gets the orders in the given status, or all orders if the status is empty
+func (inventory *PInventoryDB) GetOrders(ctx context.Context, status data.OrderStatus) (error, []data.Order) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	log.Debug("GetOrders() entry...")
+	err, orders := queryOrders(ctx, inventory.db, log, getOrders, status, request.GetTenant(ctx))
+	if err != nil {
+		return err, nil
+	}
+
+	log.WithField("number of order to be returned: ", len(orders)).Debug("GetOrders(), returns the orders...")
+	return nil, orders
+}
+
+//GetOrder gets the order with its lines
+func (inventory *PInventoryDB) GetOrder(ctx context.Context, orderId int) (error, data.Order) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	log.Debug("GetOrder() entry...")
+	err, orders := queryOrders(ctx, inventory.db, log, getOrder, orderId, request.GetTenant(ctx))
+	if err != nil {
+		return err, data.Order{}
+	}
+	if len(orders) == 0 {
+		return db.ErrOrderNotFound, data.Order{}
+	}
+	return nil, orders[0]
+}
+
+//UpdateOrderStatus moves the order to the given status. Allocating reserves the stock of the articles,
+//shipping consumes the reserved stock and cancelling releases it
+func (inventory *PInventoryDB) UpdateOrderStatus(ctx context.Context, orderId int, status data.OrderStatus) (error, data.Order) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("order_id", orderId)
+	tenant := request.GetTenant(ctx)
+	log.Debug("UpdateOrderStatus() entry...")
+	transaction, err := inventory.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, data.Order{}
+	}
+	defer transaction.Rollback()
+
+	var current data.OrderStatus
+	err = transaction.QueryRowContext(ctx, lockOrder, orderId, tenant).Scan(&current)
+	if err == sql.ErrNoRows {
+		return db.ErrOrderNotFound, data.Order{}
+	}
+	if err != nil {
+		log.WithField("err", err).Error("LockOrder query failed")
+		return err, data.Order{}
+	}
+	if !current.CanTransitionTo(status) {
+		log.WithField("from", current).WithField("to", status).Info("invalid order transition")
+		return fmt.Errorf("%w: %s to %s", db.ErrInvalidOrderTransition, current, status), data.Order{}
+	}
+
+	switch {
+	case status == data.OrderAllocated:
+		err, lines := queryOrderLines(ctx, transaction, log, orderId)
+		if err != nil {
+			return err, data.Order{}
+		}
+		err = allocateOrder(ctx, transaction, log, orderId, lines)
+		if err != nil {
+			return err, data.Order{}
+		}
+	case status == data.OrderShipped:
+		err = changeAllocatedStock(ctx, transaction, log, orderId, consumeArticle)
+		if err != nil {
+			return err, data.Order{}
+		}
+		err = recordOrderSales(ctx, transaction, log, orderId)
+		if err != nil {
+			return err, data.Order{}
+		}
+	case status == data.OrderCancelled && current.HoldsStock():
+		err = changeAllocatedStock(ctx, transaction, log, orderId, releaseArticle)
+		if err != nil {
+			return err, data.Order{}
+		}
+	}
+
+	_, err = transaction.ExecContext(ctx, updateOrderStatus, orderId, status, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("UpdateOrderStatus(), failed to update order status...")
+		return err, data.Order{}
+	}
+	err = transaction.Commit()
+	if err != nil {
+		log.WithField("err: ", err).Error("UpdateOrderStatus(), failed to commit...")
+		return err, data.Order{}
+	}
+
+	log.WithField("status", status).Debug("UpdateOrderStatus(), updated the order...")
+	return inventory.GetOrder(ctx, orderId)
+}
+
+//queryer is implemented by both sql.DB and sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+//queryOrders runs an order query and groups its lines per order
+func queryOrders(ctx context.Context, conn queryer, log *logrus.Entry, query string, args ...interface{}) (error, []data.Order) {
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithField("err", err).Error("Order query failed")
+		return err, nil
+	}
+	defer rows.Close()
+
+	var orders []data.Order
+	for rows.Next() {
+		var order data.Order
+		var line data.OrderLine
+		err = rows.Scan(&order.OrderId, &order.Status, &order.CreatedAt, &order.UpdatedAt, &line.ProductName, &line.Quantity)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, nil
+		}
+		if len(orders) == 0 || orders[len(orders)-1].OrderId != order.OrderId {
+			orders = append(orders, order)
+		}
+		last := &orders[len(orders)-1]
+		last.Lines = append(last.Lines, line)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, nil
+	}
+	return nil, orders
+}
+
+//queryOrderLines gets the lines of the order
+func queryOrderLines(ctx context.Context, transaction *sql.Tx, log *logrus.Entry, orderId int) (error, []data.OrderLine) {
+	tenant := request.GetTenant(ctx)
+	rows, err := transaction.QueryContext(ctx, getOrderLines, orderId, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetOrderLines query failed")
+		return err, nil
+	}
+	defer rows.Close()
+
+	var lines []data.OrderLine
+	for rows.Next() {
+		var line data.OrderLine
+		err = rows.Scan(&line.ProductName, &line.Quantity)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, nil
+		}
+		lines = append(lines, line)
+	}
+	return rows.Err(), lines
+}
+
+//allocateOrder reserves the articles required by the order lines. It returns db.ErrInsufficientStock
+//without changing the inventory if any of the articles is not available
+func allocateOrder(ctx context.Context, transaction *sql.Tx, log *logrus.Entry, orderId int, lines []data.OrderLine) error {
+	tenant := request.GetTenant(ctx)
+	required := make(map[string]int)
+	for _, line := range lines {
+		rows, err := transaction.QueryContext(ctx, getProductArticles, line.ProductName, tenant)
+		if err != nil {
+			log.WithField("err", err).Error("GetProductArticles query failed")
+			return err
+		}
+		found := false
+		for rows.Next() {
+			var artId string
+			var amount int
+			err = rows.Scan(&artId, &amount)
+			if err != nil {
+				rows.Close()
+				log.WithField("err", err).Error("Cannot scan the table")
+				return err
+			}
+			required[artId] += amount * line.Quantity
+			found = true
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			log.WithField("err", err).Error("Error happened during the iteration")
+			return err
+		}
+		if !found {
+			return fmt.Errorf("%w: %s", db.ErrOrderedProductNotInSystem, line.ProductName)
+		}
+	}
+
+	// rows are locked in the same order by every transaction to avoid deadlocks
+	artIds := make([]string, 0, len(required))
+	for artId := range required {
+		artIds = append(artIds, artId)
+	}
+	sort.Strings(artIds)
+	for _, artId := range artIds {
+		var available int
+		err := transaction.QueryRowContext(ctx, lockAvailableStock, artId, tenant).Scan(&available)
+		if err != nil {
+			log.WithField("err", err).Error("LockAvailableStock query failed")
+			return err
+		}
+		if available < required[artId] {
+			log.WithField("art_id", artId).Info("not enough stock to allocate the order")
+			return db.ErrInsufficientStock
+		}
+	}
+
+	for _, artId := range artIds {
+		_, err := transaction.ExecContext(ctx, allocateArticle, artId, required[artId], tenant)
+		if err != nil {
+			log.WithField("err: ", err).Error("allocateOrder(), failed to allocate article...")
+			return err
+		}
+		_, err = transaction.ExecContext(ctx, insertOrderAllocation, orderId, artId, required[artId], tenant)
+		if err != nil {
+			log.WithField("err: ", err).Error("allocateOrder(), failed to insert allocation...")
+			return err
+		}
+	}
+	return nil
+}
+
+//queryOrderAllocations gets the articles allocated to the order and their amounts
+func queryOrderAllocations(ctx context.Context, transaction *sql.Tx, log *logrus.Entry, orderId int) (error, []string, map[string]int) {
+	tenant := request.GetTenant(ctx)
+	rows, err := transaction.QueryContext(ctx, getOrderAllocations, orderId, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetOrderAllocations query failed")
+		return err, nil, nil
+	}
+	allocations := make(map[string]int)
+	var artIds []string
+	for rows.Next() {
+		var artId string
+		var amount int
+		err = rows.Scan(&artId, &amount)
+		if err != nil {
+			rows.Close()
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, nil, nil
+		}
+		artIds = append(artIds, artId)
+		allocations[artId] = amount
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, nil, nil
+	}
+	return nil, artIds, allocations
+}
+
+//changeAllocatedStock applies the given update to every article allocated to the order
+func changeAllocatedStock(ctx context.Context, transaction *sql.Tx, log *logrus.Entry, orderId int, update string) error {
+	err, artIds, allocations := queryOrderAllocations(ctx, transaction, log, orderId)
+	if err != nil {
+		return err
+	}
+	for _, artId := range artIds {
+		_, err = transaction.ExecContext(ctx, update, artId, allocations[artId], request.GetTenant(ctx))
+		if err != nil {
+			log.WithField("err: ", err).Error("changeAllocatedStock(), failed to update inventory...")
+			return err
+		}
+	}
+	return nil
+}
+
+//recordOrderSales records a sale for every line of the shipped order with the cost of its articles, so the shipped
+//orders are in the sales and the revenue
+func recordOrderSales(ctx context.Context, transaction *sql.Tx, log *logrus.Entry, orderId int) error {
+	err, lines := queryOrderLines(ctx, transaction, log, orderId)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		err = recordSale(ctx, transaction, line.ProductName, line.Quantity)
+		if err != nil {
+			log.WithField("err: ", err).Error("recordOrderSales(), failed to record the sale...")
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// +build integration
+
+package postgres
+
+import (
+	"errors"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPInventoryDB_OrderLifecycle(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	//fill the tables before apply query
+	uploadInventory(inventory, ctx)
+	uploadProduct(inventory, ctx)
+
+	err, order := inventory.CreateOrder(ctx, data.Order{Lines: []data.OrderLine{{ProductName: "Dinning Table", Quantity: 1}}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, order.Status, data.OrderAllocated)
+
+	//the only table top is allocated, so the table cannot be sold anymore
+	err, stockOfProduct := inventory.GetProductStock(ctx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(stockOfProduct), 1)
+	err = inventory.SellProduct(ctx, "Dinning Table")
+	assert.Error(t, err, "this product is not in stock, cannot be sold")
+
+	err, order = inventory.UpdateOrderStatus(ctx, order.OrderId, data.OrderPicked)
+	assert.Equal(t, err, nil)
+	err, order = inventory.UpdateOrderStatus(ctx, order.OrderId, data.OrderShipped)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, order.Status, data.OrderShipped)
+
+	err, stock := inventory.GetInventory(ctx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stock[0].Stock, "8")
+	assert.Equal(t, stock[3].Stock, "0")
+
+	//the shipped order is in the sales
+	err, sales := inventory.GetSales(ctx, data.SalesFilter{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sales), 1)
+	assert.Equal(t, sales[0].ProductName, "Dinning Table")
+	assert.Equal(t, sales[0].Quantity, 1)
+
+	err, _ = inventory.UpdateOrderStatus(ctx, order.OrderId, data.OrderCancelled)
+	assert.Assert(t, errors.Is(err, db.ErrInvalidOrderTransition))
+
+	err, orders := inventory.GetOrders(ctx, data.OrderShipped)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(orders), 1)
+}
+
+func TestPInventoryDB_CancelOrder(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	uploadInventory(inventory, ctx)
+	uploadProduct(inventory, ctx)
+
+	//not enough seats for three chairs
+	err, order := inventory.CreateOrder(ctx, data.Order{Lines: []data.OrderLine{{ProductName: "Dining Chair", Quantity: 3}}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, order.Status, data.OrderCreated)
+
+	err, allocated := inventory.CreateOrder(ctx, data.Order{Lines: []data.OrderLine{{ProductName: "Dining Chair", Quantity: 1}}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, allocated.Status, data.OrderAllocated)
+
+	err, allocated = inventory.UpdateOrderStatus(ctx, allocated.OrderId, data.OrderCancelled)
+	assert.Equal(t, err, nil)
+	err, order = inventory.UpdateOrderStatus(ctx, order.OrderId, data.OrderCancelled)
+	assert.Equal(t, err, nil)
+
+	//released stock is available again
+	err, stockOfProduct := inventory.GetProductStock(ctx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(stockOfProduct), 2)
+
+	err, _ = inventory.GetOrder(ctx, 1000)
+	assert.Equal(t, err, db.ErrOrderNotFound)
+}
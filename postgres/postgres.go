@@ -3,13 +3,11 @@ package postgres
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"github.com/auknl/warehouse/data"
 	"github.com/auknl/warehouse/db"
 	"github.com/auknl/warehouse/request"
 	"github.com/sirupsen/logrus"
-	"strconv"
 )
 
 //PInventoryDB keep db and configuration
@@ -48,14 +46,15 @@ func (inventory *PInventoryDB) Ping() error {
 	//TODO: if ping gives error, connection retry mech. can be added.
 }
 
+//Stats returns the connection pool stats of the database
+func (inventory *PInventoryDB) Stats() sql.DBStats {
+	return inventory.db.Stats()
+}
+
 //Open opens a postgres database
 func (inventory *PInventoryDB) Open() error {
 	inventory.config.Logger.Debug("Open() entry...")
-	psqlCredentials := fmt.Sprintf("host=%s port=%s user=%s "+
-		"password=%s dbname=%s sslmode=disable",
-		inventory.config.Host, inventory.config.Port, inventory.config.User, inventory.config.Password, inventory.config.Dbname)
-
-	conn, err := sql.Open(inventory.config.Driver, psqlCredentials)
+	conn, err := sql.Open(inventory.config.Driver, inventory.dataSourceName())
 	if err != nil {
 		inventory.config.Logger.WithField("err: ", err).Error("Sql open failed")
 		return err
@@ -65,9 +64,26 @@ func (inventory *PInventoryDB) Open() error {
 	return nil
 }
 
+//dataSourceName gives the connection string of the database
+func (inventory *PInventoryDB) dataSourceName() string {
+	return fmt.Sprintf("host=%s port=%s user=%s "+
+		"password=%s dbname=%s sslmode=disable",
+		inventory.config.Host, inventory.config.Port, inventory.config.User, inventory.config.Password, inventory.config.Dbname)
+}
+
+//Close closes the database, the queries in progress are completed first
+func (inventory *PInventoryDB) Close() error {
+	inventory.config.Logger.Debug("Close() entry...")
+	if inventory.db == nil {
+		return nil
+	}
+	return inventory.db.Close()
+}
+
 //GetInventory gets all inventory/stock info in system
 func (inventory *PInventoryDB) GetInventory(ctx context.Context) (error, []data.Stock) {
 	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
 	log.Debug("GetInventory() entry...")
 	transaction, err := inventory.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -75,28 +91,16 @@ func (inventory *PInventoryDB) GetInventory(ctx context.Context) (error, []data.
 		return err, nil
 	}
 	defer transaction.Rollback() //get operation
-	rows, err := transaction.Query(getInventory)
+	rows, err := transaction.QueryContext(ctx, getInventory, tenant)
 	if err != nil {
 		log.WithField("err", err).Error("GetInventory query failed")
 		return err, nil
 	}
 
 	defer rows.Close()
-	var artId, artName string
-	var stock string
-	var stocks []data.Stock
-	for rows.Next() {
-		err = rows.Scan(&artId, &artName, &stock)
-		if err != nil {
-			log.WithField("err", err).Error("Cannot scan the table")
-			return err, nil
-		}
-		stocks = append(stocks, data.Stock{ArtId: artId, Name: artName, Stock: stock})
-	}
-
-	err = rows.Err()
+	stocks, err := scanArticles(rows)
 	if err != nil {
-		log.WithField("err", err).Error("Error happened during the iteration")
+		log.WithField("err", err).Error("Cannot scan the table")
 		return err, nil
 	}
 
@@ -107,6 +111,7 @@ func (inventory *PInventoryDB) GetInventory(ctx context.Context) (error, []data.
 //GetProductStock gets the stock of the available products in system
 func (inventory *PInventoryDB) GetProductStock(ctx context.Context) (error, data.ProductStocks) {
 	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
 	log.Debug("GetProductStock() entry...")
 	transaction, err := inventory.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -114,31 +119,16 @@ func (inventory *PInventoryDB) GetProductStock(ctx context.Context) (error, data
 		return err, nil
 	}
 	defer transaction.Rollback()
-	rows, err := transaction.Query(getProductStock)
+	rows, err := transaction.QueryContext(ctx, getProductStock, tenant)
 	if err != nil {
 		log.WithField("err", err).Error("GetProductStock query failed")
 		return err, nil
 	}
 
 	defer rows.Close()
-	var productName string
-	var stock string
-	var stocks data.ProductStocks
-	for rows.Next() {
-		err = rows.Scan(&productName, &stock)
-		if err != nil {
-			log.WithField("err", err).Error("Cannot scan the table")
-			return err, nil
-		}
-		stockNo, _ := strconv.ParseInt(stock, 10, 64)
-		if stockNo != 0 { // if product items are enough
-			stocks = append(stocks, data.ProductStock{Name: productName, AvailableProductNo: stock})
-		}
-	}
-
-	err = rows.Err()
+	stocks, err := scanProductStocks(rows)
 	if err != nil {
-		log.WithField("err", err).Error("Error happened during the getProductStock iteration")
+		log.WithField("err", err).Error("Cannot scan the table")
 		return err, nil
 	}
 
@@ -149,6 +139,7 @@ func (inventory *PInventoryDB) GetProductStock(ctx context.Context) (error, data
 //UploadProducts inserts the product info into db
 func (inventory *PInventoryDB) UploadProducts(ctx context.Context, product data.Products) (error, int) {
 	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
 	log.Debug("UploadProducts() entry...")
 	transaction, err := inventory.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -158,7 +149,7 @@ func (inventory *PInventoryDB) UploadProducts(ctx context.Context, product data.
 	insertedRecord := 0
 	for _, product := range product.Products {
 		for _, contain := range product.ContainArticles {
-			_, err := transaction.ExecContext(ctx, insertProduct, product.Name, contain.ArtId, contain.AmountOf)
+			_, err := transaction.ExecContext(ctx, insertProduct, product.Name, contain.ArtId, contain.AmountOf, tenant)
 			if err != nil {
 				transaction.Rollback()
 				log.WithField("err: ", err).Error("UploadProducts(), failed to insert record...")
@@ -166,11 +157,19 @@ func (inventory *PInventoryDB) UploadProducts(ctx context.Context, product data.
 				//TODO: Failed products can save and keep uploading till the end of list. Then the unsuccessful ones can serve the client
 			}
 		}
+		for _, barcode := range product.Barcodes {
+			_, err := transaction.ExecContext(ctx, insertProductBarcode, barcode, product.Name, tenant)
+			if err != nil {
+				transaction.Rollback()
+				log.WithField("err: ", err).Error("UploadProducts(), failed to insert barcode...")
+				return err, 0
+			}
+		}
 	}
 	err = transaction.Commit()
 	if err != nil {
-		transaction.Rollback()
 		log.WithField("err: ", err).Error("Transaction commit failed to insert product...")
+		return err, 0
 	}
 	insertedRecord = len(product.Products)
 
@@ -188,7 +187,7 @@ func (inventory *PInventoryDB) UploadInventory(ctx context.Context, inventoryToI
 		return err, 0
 	}
 	for _, inventoryRec := range inventoryToInsert.Inventory {
-		_, err := transaction.ExecContext(ctx, insertStock, inventoryRec.ArtId, inventoryRec.Name, inventoryRec.Stock)
+		err := insertArticle(ctx, transaction, inventoryRec)
 		if err != nil {
 			transaction.Rollback()
 			log.WithField("err: ", err).Error("UploadInventory failed to insert record...")
@@ -197,9 +196,8 @@ func (inventory *PInventoryDB) UploadInventory(ctx context.Context, inventoryToI
 	}
 	err = transaction.Commit()
 	if err != nil {
-		transaction.Rollback()
 		log.WithField("err: ", err).Error("Failed to commit...")
-		return nil, 0
+		return err, 0
 	}
 	insertedRecord := len(inventoryToInsert.Inventory)
 
@@ -210,6 +208,7 @@ func (inventory *PInventoryDB) UploadInventory(ctx context.Context, inventoryToI
 //SellProduct checks if the product exist and in stock. If true then update inventory accordingly
 func (inventory *PInventoryDB) SellProduct(ctx context.Context, productName string) error {
 	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
 	log.Debug("sellProduct() entry...")
 	transaction, err := inventory.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -219,10 +218,10 @@ func (inventory *PInventoryDB) SellProduct(ctx context.Context, productName stri
 
 	defer transaction.Rollback()
 	// do not sell if the product does not exist
-	rows, errQuery := transaction.Query(productExist, productName)
+	rows, errQuery := transaction.QueryContext(ctx, productExist, productName, tenant)
 	if errQuery != nil {
-		log.WithField("err", err).Error("ProductExist query failed")
-		return err
+		log.WithField("err", errQuery).Error("ProductExist query failed")
+		return errQuery
 	}
 	var productExist int
 	for rows.Next() {
@@ -233,15 +232,23 @@ func (inventory *PInventoryDB) SellProduct(ctx context.Context, productName stri
 		}
 		if productExist == 0 {
 			log.WithField("err", err).Info("product is not found in system")
-			return errors.New("this product is not in system, cannot be sold")
+			return db.ErrProductNotInSystem
 		}
 	}
 
+	// lock the articles of the product in the order allocateOrder locks them, so the stock checked below is still
+	// there when it is taken off
+	err, parts := lockProductArticles(ctx, transaction, productName)
+	if err != nil {
+		log.WithField("err", err).Error("LockProductStock query failed")
+		return err
+	}
+
 	// do not sell if the product is not in stock
-	rows, errQuery = transaction.Query(inStock, productName)
+	rows, errQuery = transaction.QueryContext(ctx, inStock, productName, tenant)
 	if errQuery != nil {
-		log.WithField("err", err).Error("InStock query failed")
-		return err
+		log.WithField("err", errQuery).Error("InStock query failed")
+		return errQuery
 	}
 	var stockNo int
 	for rows.Next() {
@@ -252,17 +259,32 @@ func (inventory *PInventoryDB) SellProduct(ctx context.Context, productName stri
 		}
 		if stockNo != 0 {
 			log.WithField("err", err).Info("product items are out of stock")
-			return errors.New("this product is not in stock, cannot be sold")
+			return db.ErrProductOutOfStock
 		}
 	}
 
 	defer rows.Close()
-	_, err = transaction.ExecContext(ctx, updateSaleInfo, productName)
+	result, err := transaction.ExecContext(ctx, updateSaleInfo, productName, tenant)
 	if err != nil {
 		transaction.Rollback()
 		log.WithField("err: ", err).Error("SellProduct(), failed to update inventory...")
 		return err
 	}
+	// every part of the product is taken off the stock or the product is not sold
+	updated, err := result.RowsAffected()
+	if err != nil {
+		log.WithField("err: ", err).Error("SellProduct(), failed to read the updated articles...")
+		return err
+	}
+	if updated != int64(parts) {
+		log.WithField("updated", updated).Info("product items are out of stock")
+		return db.ErrProductOutOfStock
+	}
+	err = recordSale(ctx, transaction, productName, 1)
+	if err != nil {
+		log.WithField("err: ", err).Error("SellProduct(), failed to record the sale...")
+		return err
+	}
 	err = transaction.Commit()
 	if err != nil {
 		transaction.Rollback()
@@ -273,3 +295,22 @@ func (inventory *PInventoryDB) SellProduct(ctx context.Context, productName stri
 	log.WithField("product is sold: ", productName).Debug("sellProduct(), sold the product and update the inventory...")
 	return nil
 }
+
+//lockProductArticles locks the inventory articles of the product ordered by art_id and gives the number of them
+func lockProductArticles(ctx context.Context, transaction *sql.Tx, productName string) (error, int) {
+	rows, err := transaction.QueryContext(ctx, lockProductStock, productName, request.GetTenant(ctx))
+	if err != nil {
+		return err, 0
+	}
+	defer rows.Close()
+	parts := 0
+	for rows.Next() {
+		var artId string
+		err = rows.Scan(&artId)
+		if err != nil {
+			return err, 0
+		}
+		parts++
+	}
+	return rows.Err(), parts
+}
@@ -21,6 +21,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"runtime"
+	"sync"
 	"testing"
 
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -33,7 +34,9 @@ type dockerDBConn struct {
 var (
 	// DockerDBConn holds the connection to our DB in the container
 	DockerDBConn *dockerDBConn
-	logger       = logrus.New()
+	// DockerDBURL is the url of our DB in the container
+	DockerDBURL *url.URL
+	logger      = logrus.New()
 )
 
 // refs: https://github.com/ory/dockertest
@@ -67,6 +70,7 @@ func initDB(log *logrus.Logger) (*dockertest.Pool, *dockertest.Resource) {
 	if runtime.GOOS == "darwin" {
 		pgURL.Host = net.JoinHostPort(resource.GetBoundIP("5432/tcp"), resource.GetPort("5432/tcp"))
 	}
+	DockerDBURL = pgURL
 
 	DockerDBConn = &dockerDBConn{}
 	// exponential backoff-retry, because the application in the container might not be ready to accept connections yet
@@ -106,7 +110,7 @@ func (db dockerDBConn) initMigrations() {
 		log.Fatal(err)
 	}
 
-	err = migrateSql.Steps(1)
+	err = migrateSql.Up()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -207,7 +211,7 @@ func TestPInventoryDB_GetInventory(t *testing.T) {
 	err, stock := inventory.GetInventory(ctx)
 	assert.Equal(t, len(stock), len(inventoryData.Inventory))
 	for i := range inventoryData.Inventory {
-		assert.Equal(t, stock[i], inventoryData.Inventory[i])
+		assert.DeepEqual(t, stock[i], inventoryData.Inventory[i])
 	}
 	assert.Equal(t, err, nil)
 
@@ -303,6 +307,21 @@ func TestPInventoryDB_Open(t *testing.T) {
 
 }
 
+func TestPInventoryDB_Close(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+	err := inventory.Close()
+	assert.Equal(t, err, nil)
+	err = inventory.Ping()
+	assert.Assert(t, err != nil)
+
+}
+
 func TestPInventoryDB_SellOOSProduct(t *testing.T) { //Try to sell "Dinning Table" which is  Out Of Stock
 	pool, resource := initDB(logger)
 	defer closeDB(pool, resource)
@@ -348,3 +367,97 @@ func TestPInventoryDB_SellProductNotExist(t *testing.T) { //Try to sell a produc
 	}
 
 }
+
+func TestPInventoryDB_SellProductConcurrent(t *testing.T) { //Concurrent sells take every part of the product or none
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+	uploadInventory(inventory, ctx)
+	uploadProduct(inventory, ctx)
+
+	//the stock is enough for one "Dinning Table"
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- inventory.SellProduct(ctx, "Dinning Table")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	sold := 0
+	for err := range errs {
+		if err == nil {
+			sold++
+			continue
+		}
+		assert.Equal(t, err, db.ErrProductOutOfStock)
+	}
+	assert.Equal(t, sold, 1)
+
+	err, stock := inventory.GetInventory(ctx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stock[0].Stock, "8")
+	assert.Equal(t, stock[1].Stock, "9")
+	assert.Equal(t, stock[3].Stock, "0")
+	err, sales := inventory.GetSales(ctx, data.SalesFilter{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sales), 1)
+}
+
+func TestPInventoryDB_SellProductQueryFailed(t *testing.T) { //A failed query of the sell checks fails the sell
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+	uploadInventory(inventory, ctx)
+	uploadProduct(inventory, ctx)
+
+	_, err := conn.Exec("ALTER TABLE product RENAME TO product_moved")
+	assert.Equal(t, err, nil)
+	err = inventory.SellProduct(ctx, "Dinning Table")
+	assert.Assert(t, err != nil)
+
+	_, err = conn.Exec("ALTER TABLE product_moved RENAME TO product")
+	assert.Equal(t, err, nil)
+	err, sales := inventory.GetSales(ctx, data.SalesFilter{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sales), 0)
+}
+
+func TestPInventoryDB_GetInventoryDeadline(t *testing.T) { //The deadline and the cancellation of the request reach the queries
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+	uploadInventory(inventory, ctx)
+
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	err, stock := inventory.GetInventory(expired)
+	assert.Assert(t, err != nil)
+	assert.Equal(t, len(stock), 0)
+
+	disconnected, disconnect := context.WithCancel(context.Background())
+	disconnect()
+	err, _ = inventory.GetInventory(disconnected)
+	assert.Assert(t, err != nil)
+}
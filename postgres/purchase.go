@@ -0,0 +1,239 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"github.com/sirupsen/logrus"
+	"sort"
+)
+
+//CreatePurchaseOrder inserts an open purchase order with its expected articles
+func (inventory *PInventoryDB) CreatePurchaseOrder(ctx context.Context, purchaseOrder data.PurchaseOrder) (error, data.PurchaseOrder) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("CreatePurchaseOrder() entry...")
+	transaction, err := inventory.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, purchaseOrder
+	}
+	defer transaction.Rollback()
+
+	var purchaseOrderId int
+	err = transaction.QueryRowContext(ctx, insertPurchaseOrder, data.PurchaseOrderOpen, tenant).Scan(&purchaseOrderId)
+	if err != nil {
+		log.WithField("err: ", err).Error("CreatePurchaseOrder(), failed to insert purchase order...")
+		return err, purchaseOrder
+	}
+
+	expected := make(map[string]int)
+	for _, line := range purchaseOrder.Lines {
+		expected[line.ArtId] += line.Expected
+	}
+	for artId, quantity := range expected {
+		_, err = transaction.ExecContext(ctx, insertPurchaseOrderLine, purchaseOrderId, artId, quantity, tenant)
+		if isForeignKeyViolation(err) {
+			log.WithField("art_id", artId).Info("CreatePurchaseOrder(), the article is not in the inventory...")
+			return db.ErrArticleNotFound, purchaseOrder
+		}
+		if err != nil {
+			log.WithField("err: ", err).Error("CreatePurchaseOrder(), failed to insert purchase order line...")
+			return err, purchaseOrder
+		}
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		log.WithField("err: ", err).Error("CreatePurchaseOrder(), failed to commit...")
+		return err, purchaseOrder
+	}
+
+	log.WithField("purchase order id: ", purchaseOrderId).Debug("CreatePurchaseOrder(), created the purchase order...")
+	return inventory.GetPurchaseOrder(ctx, purchaseOrderId)
+}
+
+//GetPurchaseOrders gets the purchase orders in the given status, or all of them if the status is empty
+func (inventory *PInventoryDB) GetPurchaseOrders(ctx context.Context, status data.PurchaseOrderStatus) (error, []data.PurchaseOrder) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	log.Debug("GetPurchaseOrders() entry...")
+	err, purchaseOrders := queryPurchaseOrders(ctx, inventory.db, log, getPurchaseOrders, status, request.GetTenant(ctx))
+	if err != nil {
+		return err, nil
+	}
+
+	log.WithField("number of purchase order to be returned: ", len(purchaseOrders)).Debug("GetPurchaseOrders(), returns the purchase orders...")
+	return nil, purchaseOrders
+}
+
+//GetPurchaseOrder gets the purchase order with its delivery state per article
+func (inventory *PInventoryDB) GetPurchaseOrder(ctx context.Context, purchaseOrderId int) (error, data.PurchaseOrder) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	log.Debug("GetPurchaseOrder() entry...")
+	err, purchaseOrders := queryPurchaseOrders(ctx, inventory.db, log, getPurchaseOrder, purchaseOrderId, request.GetTenant(ctx))
+	if err != nil {
+		return err, data.PurchaseOrder{}
+	}
+	if len(purchaseOrders) == 0 {
+		return db.ErrPurchaseOrderNotFound, data.PurchaseOrder{}
+	}
+	return nil, purchaseOrders[0]
+}
+
+//ReceiveGoods records a delivery against the purchase order and increments the stock of the delivered articles.
+//Quantities above the expected ones are accepted and reported as over delivery
+func (inventory *PInventoryDB) ReceiveGoods(ctx context.Context, receipt data.GoodsReceipt) (error, data.PurchaseOrder) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("purchase_order_id", receipt.PurchaseOrderId)
+	tenant := request.GetTenant(ctx)
+	log.Debug("ReceiveGoods() entry...")
+	transaction, err := inventory.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, data.PurchaseOrder{}
+	}
+	defer transaction.Rollback()
+
+	var status data.PurchaseOrderStatus
+	err = transaction.QueryRowContext(ctx, lockPurchaseOrder, receipt.PurchaseOrderId, tenant).Scan(&status)
+	if err == sql.ErrNoRows {
+		return db.ErrPurchaseOrderNotFound, data.PurchaseOrder{}
+	}
+	if err != nil {
+		log.WithField("err", err).Error("LockPurchaseOrder query failed")
+		return err, data.PurchaseOrder{}
+	}
+	if status == data.PurchaseOrderClosed {
+		return db.ErrPurchaseOrderClosed, data.PurchaseOrder{}
+	}
+
+	var receiptId int
+	err = transaction.QueryRowContext(ctx, insertGoodsReceipt, receipt.PurchaseOrderId, tenant).Scan(&receiptId)
+	if err != nil {
+		log.WithField("err: ", err).Error("ReceiveGoods(), failed to insert goods receipt...")
+		return err, data.PurchaseOrder{}
+	}
+
+	received := make(map[string]int)
+	for _, line := range receipt.Lines {
+		received[line.ArtId] += line.Quantity
+	}
+	artIds := make([]string, 0, len(received))
+	for artId := range received {
+		artIds = append(artIds, artId)
+	}
+	sort.Strings(artIds)
+	for _, artId := range artIds {
+		result, err := transaction.ExecContext(ctx, receivePurchaseOrderLine, receipt.PurchaseOrderId, artId, received[artId], tenant)
+		if err != nil {
+			log.WithField("err: ", err).Error("ReceiveGoods(), failed to update purchase order line...")
+			return err, data.PurchaseOrder{}
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err, data.PurchaseOrder{}
+		}
+		if updated == 0 {
+			return fmt.Errorf("%w: article %s is not part of purchase order %d", db.ErrReceivedArticleNotOrdered, artId, receipt.PurchaseOrderId), data.PurchaseOrder{}
+		}
+		_, err = transaction.ExecContext(ctx, insertGoodsReceiptLine, receiptId, artId, received[artId], tenant)
+		if err != nil {
+			log.WithField("err: ", err).Error("ReceiveGoods(), failed to insert goods receipt line...")
+			return err, data.PurchaseOrder{}
+		}
+		_, err = transaction.ExecContext(ctx, restockArticle, artId, received[artId], tenant)
+		if err != nil {
+			log.WithField("err: ", err).Error("ReceiveGoods(), failed to update inventory...")
+			return err, data.PurchaseOrder{}
+		}
+	}
+
+	for _, line := range receipt.Lines {
+		err = addCostLayer(ctx, transaction, line.ArtId, line.Quantity, line.UnitCost, layerReceipt)
+		if err != nil {
+			log.WithField("err: ", err).Error("ReceiveGoods(), failed to insert cost layer...")
+			return err, data.PurchaseOrder{}
+		}
+	}
+
+	var outstanding int
+	err = transaction.QueryRowContext(ctx, countOutstandingLines, receipt.PurchaseOrderId, tenant).Scan(&outstanding)
+	if err != nil {
+		log.WithField("err", err).Error("CountOutstandingLines query failed")
+		return err, data.PurchaseOrder{}
+	}
+	status = data.PurchaseOrderReceived
+	if outstanding > 0 {
+		status = data.PurchaseOrderPartiallyReceived
+	}
+	_, err = transaction.ExecContext(ctx, updatePurchaseOrderStatus, receipt.PurchaseOrderId, status, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("ReceiveGoods(), failed to update purchase order status...")
+		return err, data.PurchaseOrder{}
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		log.WithField("err: ", err).Error("ReceiveGoods(), failed to commit...")
+		return err, data.PurchaseOrder{}
+	}
+
+	log.WithField("receipt id: ", receiptId).Debug("ReceiveGoods(), received the goods and updated the inventory...")
+	return inventory.GetPurchaseOrder(ctx, receipt.PurchaseOrderId)
+}
+
+//ClosePurchaseOrder closes the purchase order, so the outstanding quantities are not expected anymore
+func (inventory *PInventoryDB) ClosePurchaseOrder(ctx context.Context, purchaseOrderId int) (error, data.PurchaseOrder) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("purchase_order_id", purchaseOrderId)
+	tenant := request.GetTenant(ctx)
+	log.Debug("ClosePurchaseOrder() entry...")
+	result, err := inventory.db.ExecContext(ctx, updatePurchaseOrderStatus, purchaseOrderId, data.PurchaseOrderClosed, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("ClosePurchaseOrder(), failed to update purchase order status...")
+		return err, data.PurchaseOrder{}
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err, data.PurchaseOrder{}
+	}
+	if updated == 0 {
+		return db.ErrPurchaseOrderNotFound, data.PurchaseOrder{}
+	}
+	return inventory.GetPurchaseOrder(ctx, purchaseOrderId)
+}
+
+//queryPurchaseOrders runs a purchase order query and groups its lines per purchase order
+func queryPurchaseOrders(ctx context.Context, conn queryer, log *logrus.Entry, query string, args ...interface{}) (error, []data.PurchaseOrder) {
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithField("err", err).Error("Purchase order query failed")
+		return err, nil
+	}
+	defer rows.Close()
+
+	var purchaseOrders []data.PurchaseOrder
+	for rows.Next() {
+		var purchaseOrder data.PurchaseOrder
+		var line data.PurchaseOrderLine
+		err = rows.Scan(&purchaseOrder.PurchaseOrderId, &purchaseOrder.Status, &purchaseOrder.CreatedAt, &purchaseOrder.UpdatedAt,
+			&line.ArtId, &line.Expected, &line.Received, &line.Outstanding, &line.OverDelivered)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, nil
+		}
+		if len(purchaseOrders) == 0 || purchaseOrders[len(purchaseOrders)-1].PurchaseOrderId != purchaseOrder.PurchaseOrderId {
+			purchaseOrders = append(purchaseOrders, purchaseOrder)
+		}
+		last := &purchaseOrders[len(purchaseOrders)-1]
+		last.Lines = append(last.Lines, line)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, nil
+	}
+	return nil, purchaseOrders
+}
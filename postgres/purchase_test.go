@@ -0,0 +1,98 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPInventoryDB_ReceiveGoods(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	uploadInventory(inventory, ctx)
+
+	err, purchaseOrder := inventory.CreatePurchaseOrder(ctx, data.PurchaseOrder{Lines: []data.PurchaseOrderLine{
+		{ArtId: "3", Expected: 10},
+		{ArtId: "4", Expected: 5},
+	}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, purchaseOrder.Status, data.PurchaseOrderOpen)
+
+	err, stock := inventory.GetInventory(ctx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stock[2].OnOrder, 10)
+	assert.Equal(t, stock[3].OnOrder, 5)
+
+	//seats are over delivered, table tops are partially delivered
+	err, purchaseOrder = inventory.ReceiveGoods(ctx, data.GoodsReceipt{
+		PurchaseOrderId: purchaseOrder.PurchaseOrderId,
+		Lines:           []data.ReceiptLine{{ArtId: "3", Quantity: 12}, {ArtId: "4", Quantity: 2}},
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, purchaseOrder.Status, data.PurchaseOrderPartiallyReceived)
+	assert.DeepEqual(t, purchaseOrder.Lines, []data.PurchaseOrderLine{
+		{ArtId: "3", Expected: 10, Received: 12, Outstanding: 0, OverDelivered: 2},
+		{ArtId: "4", Expected: 5, Received: 2, Outstanding: 3, OverDelivered: 0},
+	})
+
+	err, stock = inventory.GetInventory(ctx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stock[2].Stock, "14")
+	assert.Equal(t, stock[2].OnOrder, 0)
+	assert.Equal(t, stock[3].Stock, "3")
+	assert.Equal(t, stock[3].OnOrder, 3)
+
+	err, purchaseOrder = inventory.ClosePurchaseOrder(ctx, purchaseOrder.PurchaseOrderId)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, purchaseOrder.Status, data.PurchaseOrderClosed)
+
+	err, _ = inventory.ReceiveGoods(ctx, data.GoodsReceipt{
+		PurchaseOrderId: purchaseOrder.PurchaseOrderId,
+		Lines:           []data.ReceiptLine{{ArtId: "4", Quantity: 3}},
+	})
+	assert.Equal(t, err, db.ErrPurchaseOrderClosed)
+}
+
+func TestPInventoryDB_ReceiveGoodsNotOrdered(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	uploadInventory(inventory, ctx)
+
+	err, purchaseOrder := inventory.CreatePurchaseOrder(ctx, data.PurchaseOrder{Lines: []data.PurchaseOrderLine{{ArtId: "3", Expected: 10}}})
+	assert.Equal(t, err, nil)
+
+	err, _ = inventory.ReceiveGoods(ctx, data.GoodsReceipt{
+		PurchaseOrderId: purchaseOrder.PurchaseOrderId,
+		Lines:           []data.ReceiptLine{{ArtId: "1", Quantity: 1}},
+	})
+	assert.Error(t, err, "received article is not ordered: article 1 is not part of purchase order 1")
+
+	err, _ = inventory.GetPurchaseOrder(ctx, 1000)
+	assert.Equal(t, err, db.ErrPurchaseOrderNotFound)
+
+	//the articles expected by a purchase order are in the inventory
+	err, _ = inventory.CreatePurchaseOrder(ctx, data.PurchaseOrder{Lines: []data.PurchaseOrderLine{{ArtId: "99", Expected: 1}}})
+	assert.Equal(t, err, db.ErrArticleNotFound)
+}
@@ -1,11 +1,174 @@
 package postgres
 
+//onOrderQuantity is the quantity of the inventory article (i) expected from the open purchase orders
+const onOrderQuantity = "coalesce((SELECT sum(greatest(l.expected-l.received,0)) FROM purchase_order_line l JOIN purchase_order po ON po.purchase_order_id=l.purchase_order_id WHERE l.art_id=i.art_id AND l.tenant_id=i.tenant_id AND po.status IN ('open','partially_received')),0)"
+
+//articleColumns are the columns of the inventory article (i) scanned by scanArticle
+const articleColumns = "i.art_id, i.art_name, i.stock, " + onOrderQuantity + ", i.unit, i.weight_kg, i.length_cm, i.width_cm, i.height_cm, i.category, i.attributes, " +
+	"ARRAY(SELECT b.barcode FROM article_barcode b WHERE b.art_id=i.art_id AND b.tenant_id=i.tenant_id ORDER BY b.barcode), " +
+	"coalesce((SELECT c.average_cost FROM article_cost c WHERE c.art_id=i.art_id AND c.tenant_id=i.tenant_id),0)"
+
+const (
+	getInventory          = "SELECT " + articleColumns + " FROM inventory i WHERE i.tenant_id=$1 order by art_id"
+	insertProduct         = "INSERT INTO product (product_name, art_id, amount, tenant_id) VALUES ($1,$2,$3,$4)"
+	insertStock           = "INSERT INTO inventory(art_id, art_name, stock, unit, weight_kg, length_cm, width_cm, height_cm, category, attributes, tenant_id) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)"
+	insertBarcode         = "INSERT INTO article_barcode (barcode, art_id, tenant_id) VALUES ($1,$2,$3)"
+	getProductStock       = "SELECT pr.product_name, min((i.stock-i.allocated)/pr.amount) as available_product FROM product pr,inventory i WHERE pr.art_id=i.art_id AND pr.tenant_id=i.tenant_id AND pr.tenant_id=$1 GROUP BY pr.product_name ORDER BY pr.product_name"
+	updateSaleInfo        = "UPDATE inventory i SET stock=stock-pr.amount from product pr WHERE pr.art_id= i.art_id AND pr.tenant_id=i.tenant_id and stock-allocated>=pr.amount AND pr.product_name=$1 AND pr.tenant_id=$2"
+	lockProductStock      = "SELECT i.art_id FROM product pr JOIN inventory i ON pr.art_id=i.art_id AND pr.tenant_id=i.tenant_id WHERE pr.product_name=$1 AND pr.tenant_id=$2 ORDER BY i.art_id FOR UPDATE OF i"
+	inStock               = "SELECT count(*) from product pr, inventory i WHERE pr.art_id=i.art_id AND pr.tenant_id=i.tenant_id AND pr.product_name = $1 AND pr.tenant_id=$2 AND i.stock-i.allocated<pr.amount"
+	countLowStockArticles = "SELECT tenant_id, count(*) FILTER (WHERE stock-allocated < $1) FROM inventory GROUP BY tenant_id"
+	productExist          = "select count(*) from product where product_name=$1 AND tenant_id=$2"
+)
+
+const (
+	deleteExpiredIdempotencyKeys = "DELETE FROM idempotency_key WHERE created_at < $1"
+	reserveIdempotencyKey        = "INSERT INTO idempotency_key (idempotency_key, method, path, tenant_id) VALUES ($1,$2,$3,$4) ON CONFLICT (tenant_id, idempotency_key) DO UPDATE SET reserved_at=now(), created_at=now() WHERE idempotency_key.completed=FALSE AND idempotency_key.method=EXCLUDED.method AND idempotency_key.path=EXCLUDED.path AND idempotency_key.reserved_at < $5"
+	getIdempotencyKey            = "SELECT method, path, completed, coalesce(status_code, 0), coalesce(response_body, '') FROM idempotency_key WHERE idempotency_key=$1 AND tenant_id=$2"
+	saveIdempotentResponse       = "UPDATE idempotency_key SET status_code=$2, response_body=$3, completed=TRUE WHERE idempotency_key=$1 AND tenant_id=$4"
+	releaseIdempotencyKey        = "DELETE FROM idempotency_key WHERE idempotency_key=$1 AND tenant_id=$2 AND completed=FALSE"
+)
+
+const (
+	getProductArticles  = "SELECT art_id, amount FROM product WHERE product_name=$1 AND tenant_id=$2 ORDER BY art_id"
+	lockReturnedSale    = "SELECT product_name, quantity FROM sale WHERE sale_id=$1 AND tenant_id=$2 FOR UPDATE"
+	getReturnedQuantity = "SELECT coalesce(sum(quantity),0) FROM product_return WHERE sale_id=$1 AND tenant_id=$2"
+	insertReturn        = "INSERT INTO product_return (product_name, quantity, sale_id, reason, tenant_id) VALUES ($1,$2,NULLIF($3,0),$4,$5) RETURNING return_id"
+	insertReturnArticle = "INSERT INTO product_return_article (return_id, art_id, restocked, damaged, tenant_id) VALUES ($1,$2,$3,$4,$5)"
+	restockArticle      = "UPDATE inventory SET stock=stock+$2 WHERE art_id=$1 AND tenant_id=$3"
+)
+
+const (
+	insertOrder           = "INSERT INTO orders (status, tenant_id) VALUES ($1,$2) RETURNING order_id"
+	insertOrderLine       = "INSERT INTO order_line (order_id, product_name, quantity, tenant_id) VALUES ($1,$2,$3,$4)"
+	getOrderLines         = "SELECT product_name, quantity FROM order_line WHERE order_id=$1 AND tenant_id=$2 ORDER BY product_name"
+	getOrders             = "SELECT o.order_id, o.status, o.created_at, o.updated_at, l.product_name, l.quantity FROM orders o JOIN order_line l ON l.order_id=o.order_id WHERE o.tenant_id=$2 AND ($1='' OR o.status=$1) ORDER BY o.order_id, l.product_name"
+	getOrder              = "SELECT o.order_id, o.status, o.created_at, o.updated_at, l.product_name, l.quantity FROM orders o JOIN order_line l ON l.order_id=o.order_id WHERE o.order_id=$1 AND o.tenant_id=$2 ORDER BY l.product_name"
+	lockOrder             = "SELECT status FROM orders WHERE order_id=$1 AND tenant_id=$2 FOR UPDATE"
+	updateOrderStatus     = "UPDATE orders SET status=$2, updated_at=now() WHERE order_id=$1 AND tenant_id=$3"
+	lockAvailableStock    = "SELECT stock-allocated FROM inventory WHERE art_id=$1 AND tenant_id=$2 FOR UPDATE"
+	allocateArticle       = "UPDATE inventory SET allocated=allocated+$2 WHERE art_id=$1 AND tenant_id=$3"
+	releaseArticle        = "UPDATE inventory SET allocated=allocated-$2 WHERE art_id=$1 AND tenant_id=$3"
+	consumeArticle        = "UPDATE inventory SET stock=stock-$2, allocated=allocated-$2 WHERE art_id=$1 AND tenant_id=$3"
+	insertOrderAllocation = "INSERT INTO order_allocation (order_id, art_id, amount, tenant_id) VALUES ($1,$2,$3,$4)"
+	getOrderAllocations   = "SELECT art_id, amount FROM order_allocation WHERE order_id=$1 AND tenant_id=$2 ORDER BY art_id"
+)
+
+const (
+	insertPurchaseOrder       = "INSERT INTO purchase_order (status, tenant_id) VALUES ($1,$2) RETURNING purchase_order_id"
+	insertPurchaseOrderLine   = "INSERT INTO purchase_order_line (purchase_order_id, art_id, expected, tenant_id) VALUES ($1,$2,$3,$4)"
+	getPurchaseOrders         = "SELECT po.purchase_order_id, po.status, po.created_at, po.updated_at, l.art_id, l.expected, l.received, greatest(l.expected-l.received,0), greatest(l.received-l.expected,0) FROM purchase_order po JOIN purchase_order_line l ON l.purchase_order_id=po.purchase_order_id WHERE po.tenant_id=$2 AND ($1='' OR po.status=$1) ORDER BY po.purchase_order_id, l.art_id"
+	getPurchaseOrder          = "SELECT po.purchase_order_id, po.status, po.created_at, po.updated_at, l.art_id, l.expected, l.received, greatest(l.expected-l.received,0), greatest(l.received-l.expected,0) FROM purchase_order po JOIN purchase_order_line l ON l.purchase_order_id=po.purchase_order_id WHERE po.purchase_order_id=$1 AND po.tenant_id=$2 ORDER BY l.art_id"
+	lockPurchaseOrder         = "SELECT status FROM purchase_order WHERE purchase_order_id=$1 AND tenant_id=$2 FOR UPDATE"
+	updatePurchaseOrderStatus = "UPDATE purchase_order SET status=$2, updated_at=now() WHERE purchase_order_id=$1 AND tenant_id=$3"
+	receivePurchaseOrderLine  = "UPDATE purchase_order_line SET received=received+$3 WHERE purchase_order_id=$1 AND art_id=$2 AND tenant_id=$4"
+	countOutstandingLines     = "SELECT count(*) FROM purchase_order_line WHERE purchase_order_id=$1 AND tenant_id=$2 AND received<expected"
+	insertGoodsReceipt        = "INSERT INTO goods_receipt (purchase_order_id, tenant_id) VALUES ($1,$2) RETURNING receipt_id"
+	insertGoodsReceiptLine    = "INSERT INTO goods_receipt_line (receipt_id, art_id, quantity, tenant_id) VALUES ($1,$2,$3,$4)"
+)
+
+const (
+	getArticle            = "SELECT " + articleColumns + " FROM inventory i WHERE i.art_id=$1 AND i.tenant_id=$2"
+	getArticleSuppliers   = "SELECT a.art_id, a.supplier_id, s.name, a.part_number, a.unit_cost, a.lead_time_days, a.min_order_quantity FROM article_supplier a JOIN supplier s ON s.supplier_id=a.supplier_id WHERE a.art_id=$1 AND a.tenant_id=$2 ORDER BY a.unit_cost, s.name"
+	getSuppliers          = "SELECT supplier_id, name, email, phone FROM supplier WHERE tenant_id=$1 ORDER BY name"
+	getSupplier           = "SELECT supplier_id, name, email, phone FROM supplier WHERE supplier_id=$1 AND tenant_id=$2"
+	getSupplierArticles   = "SELECT a.art_id, a.supplier_id, s.name, a.part_number, a.unit_cost, a.lead_time_days, a.min_order_quantity FROM article_supplier a JOIN supplier s ON s.supplier_id=a.supplier_id WHERE a.supplier_id=$1 AND a.tenant_id=$2 ORDER BY a.art_id"
+	insertSupplier        = "INSERT INTO supplier (name, email, phone, tenant_id) VALUES ($1,$2,$3,$4) RETURNING supplier_id"
+	updateSupplier        = "UPDATE supplier SET name=$2, email=$3, phone=$4 WHERE supplier_id=$1 AND tenant_id=$5"
+	deleteSupplier        = "DELETE FROM supplier WHERE supplier_id=$1 AND tenant_id=$2"
+	articleExist          = "SELECT count(*) FROM inventory WHERE art_id=$1 AND tenant_id=$2"
+	supplierExist         = "SELECT count(*) FROM supplier WHERE supplier_id=$1 AND tenant_id=$2"
+	upsertSupplierArticle = "INSERT INTO article_supplier (art_id, supplier_id, part_number, unit_cost, lead_time_days, min_order_quantity, tenant_id) VALUES ($1,$2,$3,$4,$5,$6,$7) ON CONFLICT (tenant_id, art_id, supplier_id) DO UPDATE SET part_number=EXCLUDED.part_number, unit_cost=EXCLUDED.unit_cost, lead_time_days=EXCLUDED.lead_time_days, min_order_quantity=EXCLUDED.min_order_quantity"
+	deleteSupplierArticle = "DELETE FROM article_supplier WHERE supplier_id=$1 AND art_id=$2 AND tenant_id=$3"
+)
+
+const (
+	getArticleByBarcode  = "SELECT " + articleColumns + " FROM inventory i JOIN article_barcode b ON b.art_id=i.art_id AND b.tenant_id=i.tenant_id WHERE b.barcode=$1 AND b.tenant_id=$2"
+	getProductByBarcode  = "SELECT pr.product_name, min((i.stock-i.allocated)/pr.amount) FROM product_barcode b JOIN product pr ON pr.product_name=b.product_name AND pr.tenant_id=b.tenant_id JOIN inventory i ON i.art_id=pr.art_id AND i.tenant_id=pr.tenant_id WHERE b.barcode=$1 AND b.tenant_id=$2 GROUP BY pr.product_name"
+	getBarcodeArticle    = "SELECT art_id FROM article_barcode WHERE barcode=$1 AND tenant_id=$2"
+	productBarcodeExist  = "SELECT count(*) FROM product_barcode WHERE barcode=$1 AND tenant_id=$2"
+	adjustArticleStock   = "UPDATE inventory SET stock=stock+$2 WHERE art_id=$1 AND tenant_id=$3 AND stock+$2>=allocated"
+	insertProductBarcode = "INSERT INTO product_barcode (barcode, product_name, tenant_id) VALUES ($1,$2,$3)"
+)
+
+const (
+	importJobColumns = "job_id, kind, state, total, processed, records, batches, errors, created_at, updated_at"
+	insertImportJob  = "INSERT INTO import_job (kind, state, payload, total, tenant_id) VALUES ($1,$2,$3,$4,$5) RETURNING " + importJobColumns
+	getImportJob     = "SELECT " + importJobColumns + " FROM import_job WHERE job_id=$1 AND tenant_id=$2"
+	claimImportJob   = "UPDATE import_job SET state='running', worker=$1, updated_at=now() WHERE job_id=(SELECT job_id FROM import_job WHERE state='queued' OR (state='running' AND updated_at<$2) ORDER BY job_id LIMIT 1 FOR UPDATE SKIP LOCKED) RETURNING " + importJobColumns + ", payload, tenant_id"
+	updateImportJob  = "UPDATE import_job SET state=$3, processed=$4, records=$5, batches=$6, errors=$7, updated_at=now() WHERE job_id=$1 AND worker=$2 AND tenant_id=$8"
+	renewImportJob   = "UPDATE import_job SET updated_at=now() WHERE job_id=$1 AND worker=$2 AND tenant_id=$3"
+	finishImportJob  = "UPDATE import_job SET state=$3, processed=$4, records=$5, batches=$6, errors=$7, payload='', updated_at=now() WHERE job_id=$1 AND worker=$2 AND tenant_id=$8"
+)
+
+const (
+	getSnapshotTime            = "SELECT now()"
+	getSnapshotProducts        = "SELECT product_name, art_id, amount FROM product WHERE tenant_id=$1 ORDER BY product_name, art_id"
+	getSnapshotProductBarcodes = "SELECT product_name, barcode FROM product_barcode WHERE tenant_id=$1 ORDER BY product_name, barcode"
+	upsertStock                = "INSERT INTO inventory(art_id, art_name, stock, unit, weight_kg, length_cm, width_cm, height_cm, category, attributes, tenant_id) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11) ON CONFLICT (tenant_id, art_id) DO UPDATE SET art_name=EXCLUDED.art_name, stock=EXCLUDED.stock, unit=EXCLUDED.unit, weight_kg=EXCLUDED.weight_kg, length_cm=EXCLUDED.length_cm, width_cm=EXCLUDED.width_cm, height_cm=EXCLUDED.height_cm, category=EXCLUDED.category, attributes=EXCLUDED.attributes"
+	upsertBarcode              = "INSERT INTO article_barcode (barcode, art_id, tenant_id) VALUES ($1,$2,$3) ON CONFLICT (tenant_id, barcode) DO UPDATE SET art_id=EXCLUDED.art_id"
+	deleteArticleBarcodes      = "DELETE FROM article_barcode WHERE art_id=$1 AND tenant_id=$2"
+	deleteProduct              = "DELETE FROM product WHERE product_name=$1 AND tenant_id=$2"
+	deleteProductBarcodes      = "DELETE FROM product_barcode WHERE product_name=$1 AND tenant_id=$2"
+	upsertProductBarcode       = "INSERT INTO product_barcode (barcode, product_name, tenant_id) VALUES ($1,$2,$3) ON CONFLICT (tenant_id, barcode) DO UPDATE SET product_name=EXCLUDED.product_name"
+)
+
+//inventoryAsOf is the inventory (i) at the time $1, the latest version of every article not deleted by then
+const inventoryAsOf = "(SELECT * FROM (SELECT DISTINCT ON (art_id) * FROM inventory_history WHERE changed_at<=$1 AND tenant_id=$2 ORDER BY art_id, history_id DESC) h WHERE NOT h.deleted) i"
+
+//productAsOf is the product articles (pr) at the time $1, the latest version of every row not deleted by then
+const productAsOf = "(SELECT * FROM (SELECT DISTINCT ON (product_name, art_id) * FROM product_history WHERE changed_at<=$1 AND tenant_id=$2 ORDER BY product_name, art_id, history_id DESC) h WHERE NOT h.deleted) pr"
+
+const (
+	getInventoryAsOf    = "SELECT i.art_id, i.art_name, i.stock, 0, i.unit, i.weight_kg, i.length_cm, i.width_cm, i.height_cm, i.category, i.attributes, ARRAY(SELECT b.barcode FROM article_barcode b WHERE b.art_id=i.art_id AND b.tenant_id=i.tenant_id ORDER BY b.barcode), 0 FROM " + inventoryAsOf + " ORDER BY i.art_id"
+	getProductStockAsOf = "SELECT pr.product_name, min((i.stock-i.allocated)/pr.amount) as available_product FROM " + productAsOf + ", " + inventoryAsOf + " WHERE pr.art_id=i.art_id GROUP BY pr.product_name ORDER BY pr.product_name"
+)
+
+const (
+	getAverageCost      = "SELECT coalesce((SELECT average_cost FROM article_cost WHERE art_id=$1 AND tenant_id=$2),0)"
+	updateAverageCost   = "INSERT INTO article_cost (art_id, average_cost, tenant_id) VALUES ($1,$3::numeric,$4) ON CONFLICT (tenant_id, art_id) DO UPDATE SET average_cost=(article_cost.average_cost*(SELECT coalesce(sum(remaining),0) FROM cost_layer WHERE art_id=$1 AND tenant_id=$4)+$2::int*$3::numeric)/((SELECT coalesce(sum(remaining),0) FROM cost_layer WHERE art_id=$1 AND tenant_id=$4)+$2::int)"
+	insertCostLayer     = "INSERT INTO cost_layer (art_id, source, quantity, remaining, unit_cost, tenant_id) VALUES ($1,$2,$3,$3,$4,$5)"
+	lockOpenCostLayers  = "SELECT layer_id, remaining, unit_cost FROM cost_layer WHERE art_id=$1 AND tenant_id=$2 AND remaining>0 ORDER BY layer_id FOR UPDATE"
+	consumeCostLayer    = "UPDATE cost_layer SET remaining=remaining-$2 WHERE layer_id=$1 AND tenant_id=$3"
+	deleteCostLayers    = "DELETE FROM cost_layer WHERE art_id=$1 AND tenant_id=$2"
+	deleteAverageCost   = "DELETE FROM article_cost WHERE art_id=$1 AND tenant_id=$2"
+	insertSale          = "INSERT INTO sale (product_name, quantity, unit_price, currency, cost_fifo, cost_average, tenant_id) VALUES ($1,$2,(SELECT price FROM product_price WHERE product_name=$1 AND tenant_id=$5),(SELECT currency FROM product_price WHERE product_name=$1 AND tenant_id=$5),$3,$4,$5)"
+	getFIFOValuation    = "SELECT i.art_id, i.art_name, i.stock, round(coalesce((SELECT sum(l.remaining*l.unit_cost) FROM cost_layer l WHERE l.art_id=i.art_id AND l.tenant_id=i.tenant_id),0),4) FROM inventory i WHERE i.tenant_id=$1 ORDER BY i.art_id"
+	getAverageValuation = "SELECT i.art_id, i.art_name, i.stock, round(i.stock*coalesce(c.average_cost,0),4) FROM inventory i LEFT JOIN article_cost c ON c.art_id=i.art_id AND c.tenant_id=i.tenant_id WHERE i.tenant_id=$1 ORDER BY i.art_id"
+	getCostOfGoodsSold  = "SELECT coalesce(sum(cost_fifo),0), coalesce(sum(cost_average),0) FROM sale WHERE tenant_id=$1"
+)
+
+//salesFilter narrows the sales down to the tenant ($5) and by product name ($1), from ($2), to ($3) and currency ($4),
+//the empty ones are not filtered
+const salesFilter = "tenant_id=$5 AND ($1='' OR product_name=$1) AND ($2::timestamptz IS NULL OR sold_at>=$2) AND ($3::timestamptz IS NULL OR sold_at<=$3) AND ($4='' OR currency=$4)"
+
+const (
+	upsertProductPrice = "INSERT INTO product_price (product_name, price, currency, tenant_id) VALUES ($1,$2,$3,$4) ON CONFLICT (tenant_id, product_name) DO UPDATE SET price=EXCLUDED.price, currency=EXCLUDED.currency, updated_at=now() RETURNING updated_at"
+	getProductPrice    = "SELECT product_name, price, currency, updated_at FROM product_price WHERE product_name=$1 AND tenant_id=$2"
+	getSales           = "SELECT sale_id, product_name, quantity, unit_price, currency, sold_at FROM sale WHERE " + salesFilter + " ORDER BY sale_id"
+	getSalesRevenue    = "SELECT product_name, coalesce(currency,''), sum(quantity), coalesce(sum(quantity*unit_price),0) FROM sale WHERE " + salesFilter + " GROUP BY product_name, currency ORDER BY product_name, currency"
+)
+
+//topProducts is the quantity and the revenue of the sales per product and currency, with the totals of the product
+const topProducts = "SELECT product_name, coalesce(currency,''), sum(quantity), coalesce(sum(quantity*unit_price),0), sum(sum(quantity)) OVER product, coalesce(sum(sum(quantity*unit_price)) OVER product,0) FROM sale WHERE " + salesFilter + " GROUP BY product_name, currency WINDOW product AS (PARTITION BY product_name)"
+
+const (
+	getTopProductsByUnits   = topProducts + " ORDER BY 5 DESC, product_name, 2"
+	getTopProductsByRevenue = topProducts + " ORDER BY 6 DESC, product_name, 2"
+	getSalesSeries          = "SELECT product_name, date_trunc($6, sold_at AT TIME ZONE 'UTC'), coalesce(currency,''), sum(quantity), coalesce(sum(quantity*unit_price),0) FROM sale WHERE " + salesFilter + " GROUP BY 1, 2, 3 ORDER BY 1, 2, 3"
+)
+
+const (
+	insertAPIKey    = "INSERT INTO api_key (name, prefix, key_hash, scopes, tenant_id) VALUES ($1,$2,$3,$4,$5) RETURNING key_id, created_at"
+	getAPIKeys      = "SELECT key_id, name, prefix, scopes, created_at, revoked_at, tenant_id FROM api_key WHERE tenant_id=$1 ORDER BY key_id"
+	getActiveAPIKey = "SELECT key_id, name, prefix, scopes, created_at, revoked_at, tenant_id FROM api_key WHERE key_hash=$1 AND revoked_at IS NULL"
+	revokeAPIKey    = "UPDATE api_key SET revoked_at=now() WHERE key_id=$1 AND tenant_id=$2 AND revoked_at IS NULL"
+	apiKeyExist     = "SELECT count(*) FROM api_key WHERE key_id=$1 AND tenant_id=$2"
+)
+
 const (
-	getInventory    = "SELECT * FROM inventory order by art_id"
-	insertProduct   = "INSERT INTO product (product_name, art_id, amount) VALUES ($1,$2,$3)"
-	insertStock     = "INSERT INTO inventory(art_id, art_name, stock) VALUES ($1,$2,$3)"
-	getProductStock = "SELECT pr.product_name, min(i.stock/pr.amount) as available_product FROM product pr,inventory i WHERE pr.art_id=i.art_id GROUP BY pr.product_name ORDER BY pr.product_name"
-	updateSaleInfo  = "UPDATE inventory i SET stock=stock-1 from product pr WHERE pr.art_id= i.art_id and stock>= 1 AND pr.product_name=$1"
-	inStock         = "SELECT count(*) from product pr, inventory i WHERE pr.art_id=i.art_id AND pr.product_name = $1 AND i.stock=0"
-	productExist    = "select count(*) from product where product_name=$1"
+	insertRateLimitBucket      = "INSERT INTO rate_limit_bucket (bucket_key, tokens, tenant_id) VALUES ($1,$2,$3) ON CONFLICT (tenant_id, bucket_key) DO NOTHING"
+	getRateLimitBucket         = "SELECT tokens, extract(epoch FROM now()-updated_at) FROM rate_limit_bucket WHERE bucket_key=$1 AND tenant_id=$2 FOR UPDATE"
+	updateRateLimitBucket      = "UPDATE rate_limit_bucket SET tokens=$2, updated_at=now(), expires_at=now()+make_interval(secs => $4) WHERE bucket_key=$1 AND tenant_id=$3"
+	deleteFullRateLimitBuckets = "DELETE FROM rate_limit_bucket WHERE ctid IN (SELECT ctid FROM rate_limit_bucket WHERE expires_at < now() LIMIT $1 FOR UPDATE SKIP LOCKED)"
 )
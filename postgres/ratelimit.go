@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"time"
+)
+
+//rateLimitSweepSize is the most buckets removed by a take, the buckets of all the tenants are swept
+const rateLimitSweepSize = 100
+
+//TakeRateLimitToken takes a token from the bucket of the key shared by all the instances, a new bucket starts full.
+//The bucket row is locked, so the concurrent takes of the key wait for each other. The buckets full again are
+//removed before, they are the same as new ones
+func (inventory *PInventoryDB) TakeRateLimitToken(ctx context.Context, key string, limit data.RateLimit) (error, data.RateDecision) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("bucket_key", key)
+	tenant := request.GetTenant(ctx)
+	log.Debug("TakeRateLimitToken() entry...")
+	var decision data.RateDecision
+	_, err := inventory.db.ExecContext(ctx, deleteFullRateLimitBuckets, rateLimitSweepSize)
+	if err != nil {
+		log.WithField("err: ", err).Error("TakeRateLimitToken(), failed to delete the full buckets...")
+	}
+	transaction, err := inventory.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, decision
+	}
+	defer transaction.Rollback()
+
+	_, err = transaction.ExecContext(ctx, insertRateLimitBucket, key, limit.Burst, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("TakeRateLimitToken(), failed to insert the bucket...")
+		return err, decision
+	}
+	var tokens, elapsed float64
+	err = transaction.QueryRowContext(ctx, getRateLimitBucket, key, tenant).Scan(&tokens, &elapsed)
+	if err != nil {
+		log.WithField("err", err).Error("GetRateLimitBucket query failed")
+		return err, decision
+	}
+	tokens, decision = limit.Take(tokens, time.Duration(elapsed*float64(time.Second)))
+	refill := (float64(limit.Burst) - tokens) / limit.Rate
+	_, err = transaction.ExecContext(ctx, updateRateLimitBucket, key, tokens, tenant, refill)
+	if err != nil {
+		log.WithField("err: ", err).Error("TakeRateLimitToken(), failed to update the bucket...")
+		return err, decision
+	}
+	err = transaction.Commit()
+	if err != nil {
+		log.WithField("err: ", err).Error("TakeRateLimitToken(), failed to commit...")
+		return err, decision
+	}
+	return nil, decision
+}
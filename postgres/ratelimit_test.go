@@ -0,0 +1,52 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/data"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPInventoryDB_TakeRateLimitToken(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+	limit := data.RateLimit{Rate: 0.01, Burst: 2}
+
+	for i := 1; i >= 0; i-- {
+		err, decision := inventory.TakeRateLimitToken(ctx, "sell|api_key:1", limit)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, decision.Allowed, true)
+		assert.Equal(t, decision.Remaining, i)
+	}
+	err, decision := inventory.TakeRateLimitToken(ctx, "sell|api_key:1", limit)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decision.Allowed, false)
+	assert.Assert(t, decision.RetryAfter > 0)
+
+	err, decision = inventory.TakeRateLimitToken(ctx, "sell|api_key:2", limit)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decision.Allowed, true)
+
+	//the bucket full again is removed by the next take
+	err, _ = inventory.TakeRateLimitToken(ctx, "read|api_key:3", data.RateLimit{Rate: 1000, Burst: 1})
+	assert.Equal(t, err, nil)
+	time.Sleep(50 * time.Millisecond)
+	err, _ = inventory.TakeRateLimitToken(ctx, "read|api_key:4", limit)
+	assert.Equal(t, err, nil)
+	var buckets int
+	err = conn.QueryRow("SELECT count(*) FROM rate_limit_bucket WHERE bucket_key='read|api_key:3'").Scan(&buckets)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, buckets, 0)
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+)
+
+//ReturnProduct puts the articles of the returned products back to the inventory, except the damaged ones,
+//and records the return. A return that links a sale is of a sale of the product that has the returned quantity left
+//to return
+func (inventory *PInventoryDB) ReturnProduct(ctx context.Context, productReturn data.ProductReturn) (error, data.ProductReturn) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("ReturnProduct() entry...")
+	transaction, err := inventory.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, productReturn
+	}
+	defer transaction.Rollback()
+
+	if productReturn.SaleId > 0 {
+		err = checkReturnedSale(ctx, transaction, productReturn)
+		if err != nil {
+			log.WithField("err", err).Info("ReturnProduct(), the sale cannot take the return...")
+			return err, productReturn
+		}
+	}
+
+	rows, err := transaction.QueryContext(ctx, getProductArticles, productReturn.ProductName, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetProductArticles query failed")
+		return err, productReturn
+	}
+	var artIds []string
+	amounts := make(map[string]int)
+	for rows.Next() {
+		var artId string
+		var amount int
+		err = rows.Scan(&artId, &amount)
+		if err != nil {
+			rows.Close()
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, productReturn
+		}
+		artIds = append(artIds, artId)
+		amounts[artId] = amount * productReturn.Quantity
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, productReturn
+	}
+	if len(artIds) == 0 {
+		log.WithField("product", productReturn.ProductName).Info("product is not found in system")
+		return db.ErrProductNotReturnable, productReturn
+	}
+
+	damaged := make(map[string]int)
+	for _, article := range productReturn.DamagedArticles {
+		returned, ok := amounts[article.ArtId]
+		if !ok {
+			return fmt.Errorf("%w: article %s is not part of product %s", db.ErrInvalidDamagedArticle, article.ArtId, productReturn.ProductName), productReturn
+		}
+		damaged[article.ArtId] += article.Amount
+		if article.Amount < 0 || damaged[article.ArtId] > returned {
+			return fmt.Errorf("%w: amount of article %s must be between 0 and %d", db.ErrInvalidDamagedArticle, article.ArtId, returned), productReturn
+		}
+	}
+
+	err = transaction.QueryRowContext(ctx, insertReturn, productReturn.ProductName, productReturn.Quantity, productReturn.SaleId, productReturn.Reason, tenant).
+		Scan(&productReturn.ReturnId)
+	if err != nil {
+		log.WithField("err: ", err).Error("ReturnProduct(), failed to insert return...")
+		return err, productReturn
+	}
+
+	productReturn.Articles = nil
+	for _, artId := range artIds {
+		returnArticle := data.ReturnArticle{
+			ArtId:     artId,
+			Restocked: amounts[artId] - damaged[artId],
+			Damaged:   damaged[artId],
+		}
+		_, err = transaction.ExecContext(ctx, restockArticle, returnArticle.ArtId, returnArticle.Restocked, tenant)
+		if err != nil {
+			log.WithField("err: ", err).Error("ReturnProduct(), failed to update inventory...")
+			return err, productReturn
+		}
+		err = addCostLayerAtAverage(ctx, transaction, returnArticle.ArtId, returnArticle.Restocked, layerReturn)
+		if err != nil {
+			log.WithField("err: ", err).Error("ReturnProduct(), failed to insert cost layer...")
+			return err, productReturn
+		}
+		_, err = transaction.ExecContext(ctx, insertReturnArticle, productReturn.ReturnId, returnArticle.ArtId, returnArticle.Restocked, returnArticle.Damaged, tenant)
+		if err != nil {
+			log.WithField("err: ", err).Error("ReturnProduct(), failed to insert return article...")
+			return err, productReturn
+		}
+		productReturn.Articles = append(productReturn.Articles, returnArticle)
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		log.WithField("err: ", err).Error("ReturnProduct(), failed to commit...")
+		return err, productReturn
+	}
+
+	log.WithField("return id: ", productReturn.ReturnId).Debug("ReturnProduct(), returned the product and updated the inventory...")
+	return nil, productReturn
+}
+
+//checkReturnedSale checks that the sale of the return exists in the tenant, is of the returned product and has the
+//returned quantity left after its earlier returns. The sale is locked so that the concurrent returns of it are counted
+func checkReturnedSale(ctx context.Context, transaction *sql.Tx, productReturn data.ProductReturn) error {
+	tenant := request.GetTenant(ctx)
+	var productName string
+	var sold int
+	err := transaction.QueryRowContext(ctx, lockReturnedSale, productReturn.SaleId, tenant).Scan(&productName, &sold)
+	if err == sql.ErrNoRows {
+		return db.ErrSaleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if productName != productReturn.ProductName {
+		return db.ErrSaleOfOtherProduct
+	}
+
+	var returned int
+	err = transaction.QueryRowContext(ctx, getReturnedQuantity, productReturn.SaleId, tenant).Scan(&returned)
+	if err != nil {
+		return err
+	}
+	if returned+productReturn.Quantity > sold {
+		return fmt.Errorf("%w: %d of %d sold are returned before", db.ErrReturnExceedsSale, returned, sold)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPInventoryDB_ReturnProduct(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	//fill the tables before apply query
+	uploadInventory(inventory, ctx)
+	uploadProduct(inventory, ctx)
+	saleId := sellForReturn(t, inventory, ctx)
+
+	//one of the four legs comes back broken
+	err, productReturn := inventory.ReturnProduct(ctx, data.ProductReturn{
+		ProductName:     "Dinning Table",
+		Quantity:        1,
+		SaleId:          saleId,
+		DamagedArticles: []data.DamagedArticle{{ArtId: "1", Amount: 1}},
+	})
+	assert.Equal(t, err, nil)
+	assert.Assert(t, productReturn.ReturnId != 0)
+	assert.DeepEqual(t, productReturn.Articles, []data.ReturnArticle{
+		{ArtId: "1", Restocked: 3, Damaged: 1},
+		{ArtId: "2", Restocked: 8, Damaged: 0},
+		{ArtId: "4", Restocked: 1, Damaged: 0},
+	})
+
+	err, stock := inventory.GetInventory(ctx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, stock[0].Stock, "11")
+	assert.Equal(t, stock[1].Stock, "17")
+	assert.Equal(t, stock[3].Stock, "1")
+}
+
+func TestPInventoryDB_ReturnProductNotExist(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	uploadInventory(inventory, ctx)
+	uploadProduct(inventory, ctx)
+	saleId := sellForReturn(t, inventory, ctx)
+
+	err, _ := inventory.ReturnProduct(ctx, data.ProductReturn{ProductName: "NotExist", Quantity: 1, SaleId: saleId})
+	assert.Equal(t, err, db.ErrSaleOfOtherProduct)
+
+	//damaged article has to be part of the product
+	err, _ = inventory.ReturnProduct(ctx, data.ProductReturn{
+		ProductName:     "Dinning Table",
+		Quantity:        1,
+		SaleId:          saleId,
+		DamagedArticles: []data.DamagedArticle{{ArtId: "3", Amount: 1}},
+	})
+	assert.Error(t, err, "damaged article is not valid: article 3 is not part of product Dinning Table")
+}
+
+func TestPInventoryDB_ReturnProductOfSale(t *testing.T) { //The returns of a sale are at most its quantity
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	uploadInventory(inventory, ctx)
+	uploadProduct(inventory, ctx)
+	saleId := sellForReturn(t, inventory, ctx)
+
+	err, _ := inventory.ReturnProduct(ctx, data.ProductReturn{ProductName: "Dinning Table", Quantity: 1, SaleId: saleId + 1})
+	assert.Equal(t, err, db.ErrSaleNotFound)
+	err, _ = inventory.ReturnProduct(request.WithTenant(context.Background(), "globex"),
+		data.ProductReturn{ProductName: "Dinning Table", Quantity: 1, SaleId: saleId})
+	assert.Equal(t, err, db.ErrSaleNotFound)
+	err, _ = inventory.ReturnProduct(ctx, data.ProductReturn{ProductName: "Dinning Table", Quantity: 2, SaleId: saleId})
+	assert.Assert(t, errors.Is(err, db.ErrReturnExceedsSale))
+
+	err, _ = inventory.ReturnProduct(ctx, data.ProductReturn{ProductName: "Dinning Table", Quantity: 1, SaleId: saleId})
+	assert.Equal(t, err, nil)
+	//the sale is returned already
+	err, _ = inventory.ReturnProduct(ctx, data.ProductReturn{ProductName: "Dinning Table", Quantity: 1, SaleId: saleId})
+	assert.Assert(t, errors.Is(err, db.ErrReturnExceedsSale))
+	//a return without a sale is not checked against the sales
+	err, productReturn := inventory.ReturnProduct(ctx, data.ProductReturn{ProductName: "Dinning Table", Quantity: 1})
+	assert.Equal(t, err, nil)
+	assert.Assert(t, productReturn.ReturnId != 0)
+}
+
+//sellForReturn sells a "Dinning Table" and gives the id of the sale
+func sellForReturn(t *testing.T, inventory *PInventoryDB, ctx context.Context) int {
+	err := inventory.SellProduct(ctx, "Dinning Table")
+	assert.Equal(t, err, nil)
+	err, sales := inventory.GetSales(ctx, data.SalesFilter{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sales), 1)
+	return sales[0].SaleId
+}
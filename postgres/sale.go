@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+)
+
+//SetProductPrice sets the selling price of the product, the sales after it are recorded with this price
+func (inventory *PInventoryDB) SetProductPrice(ctx context.Context, price data.Price) (error, data.Price) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("product_name", price.ProductName)
+	tenant := request.GetTenant(ctx)
+	log.Debug("SetProductPrice() entry...")
+	transaction, err := inventory.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, price
+	}
+	defer transaction.Rollback()
+
+	var count int
+	err = transaction.QueryRowContext(ctx, productExist, price.ProductName, tenant).Scan(&count)
+	if err != nil {
+		log.WithField("err", err).Error("ProductExist query failed")
+		return err, price
+	}
+	if count == 0 {
+		return db.ErrProductNotFound, price
+	}
+
+	err = transaction.QueryRowContext(ctx, upsertProductPrice, price.ProductName, price.Price, price.Currency, tenant).Scan(&price.UpdatedAt)
+	if err != nil {
+		log.WithField("err: ", err).Error("SetProductPrice(), failed to save the price...")
+		return err, price
+	}
+	err = transaction.Commit()
+	if err != nil {
+		log.WithField("err: ", err).Error("SetProductPrice(), failed to commit...")
+		return err, price
+	}
+
+	log.WithField("price", price.Price).WithField("currency", price.Currency).Debug("SetProductPrice(), saved the price...")
+	return nil, price
+}
+
+//GetProductPrice gets the selling price of the product
+func (inventory *PInventoryDB) GetProductPrice(ctx context.Context, productName string) (error, data.Price) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx)).WithField("product_name", productName)
+	tenant := request.GetTenant(ctx)
+	log.Debug("GetProductPrice() entry...")
+	var price data.Price
+	err := inventory.db.QueryRowContext(ctx, getProductPrice, productName, tenant).
+		Scan(&price.ProductName, &price.Price, &price.Currency, &price.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return db.ErrPriceNotFound, price
+	}
+	if err != nil {
+		log.WithField("err", err).Error("GetProductPrice query failed")
+		return err, price
+	}
+	return nil, price
+}
+
+//GetSales gets the sales matching the filter in the order they are made
+func (inventory *PInventoryDB) GetSales(ctx context.Context, filter data.SalesFilter) (error, []data.Sale) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("GetSales() entry...")
+	rows, err := inventory.db.QueryContext(ctx, getSales, filter.ProductName, filter.From, filter.To, filter.Currency, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetSales query failed")
+		return err, nil
+	}
+	defer rows.Close()
+
+	sales := []data.Sale{}
+	for rows.Next() {
+		var sale data.Sale
+		var unitPrice sql.NullFloat64
+		var currency sql.NullString
+		err = rows.Scan(&sale.SaleId, &sale.ProductName, &sale.Quantity, &unitPrice, &currency, &sale.SoldAt)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, nil
+		}
+		sale.UnitPrice = unitPrice.Float64
+		sale.Currency = currency.String
+		sales = append(sales, sale)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, nil
+	}
+
+	log.WithField("number of sales to be returned: ", len(sales)).Debug("GetSales(), returns the sales...")
+	return nil, sales
+}
+
+//GetSalesRevenue sums the quantity and the revenue of the sales matching the filter per product and currency
+func (inventory *PInventoryDB) GetSalesRevenue(ctx context.Context, filter data.SalesFilter) (error, data.SalesRevenue) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("GetSalesRevenue() entry...")
+	revenue := data.SalesRevenue{From: filter.From, To: filter.To, Products: []data.ProductRevenue{}}
+	rows, err := inventory.db.QueryContext(ctx, getSalesRevenue, filter.ProductName, filter.From, filter.To, filter.Currency, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetSalesRevenue query failed")
+		return err, revenue
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var product data.ProductRevenue
+		err = rows.Scan(&product.ProductName, &product.Currency, &product.Quantity, &product.Revenue)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, revenue
+		}
+		revenue.Products = append(revenue.Products, product)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, revenue
+	}
+	return nil, revenue
+}
@@ -0,0 +1,68 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPInventoryDB_Sales(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	uploadInventory(inventory, ctx)
+	uploadProduct(inventory, ctx)
+
+	err, _ := inventory.SetProductPrice(ctx, data.Price{ProductName: "NotExist", Price: 10, Currency: "EUR"})
+	assert.Equal(t, err, db.ErrProductNotFound)
+	err, _ = inventory.GetProductPrice(ctx, "Dining Chair")
+	assert.Equal(t, err, db.ErrPriceNotFound)
+
+	// the first sale is made before the product has a price
+	err = inventory.SellProduct(ctx, "Dining Chair")
+	assert.Equal(t, err, nil)
+	err, price := inventory.SetProductPrice(ctx, data.Price{ProductName: "Dining Chair", Price: 199.5, Currency: "EUR"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, price.UpdatedAt.IsZero(), false)
+	err = inventory.SellProduct(ctx, "Dining Chair")
+	assert.Equal(t, err, nil)
+
+	err, price = inventory.GetProductPrice(ctx, "Dining Chair")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, price.Price, 199.5)
+	assert.Equal(t, price.Currency, "EUR")
+
+	err, sales := inventory.GetSales(ctx, data.SalesFilter{ProductName: "Dining Chair"})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sales), 2)
+	assert.Equal(t, sales[0].UnitPrice, 0.0)
+	assert.Equal(t, sales[0].Currency, "")
+	assert.Equal(t, sales[1].UnitPrice, 199.5)
+	assert.Equal(t, sales[1].Currency, "EUR")
+
+	err, revenue := inventory.GetSalesRevenue(ctx, data.SalesFilter{})
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, revenue.Products, []data.ProductRevenue{
+		{ProductName: "Dining Chair", Currency: "EUR", Quantity: 1, Revenue: 199.5},
+		{ProductName: "Dining Chair", Quantity: 1},
+	})
+
+	future := time.Now().Add(time.Hour)
+	err, sales = inventory.GetSales(ctx, data.SalesFilter{From: &future})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sales), 0)
+}
@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/request"
+	"strconv"
+	"time"
+)
+
+//GetSnapshot exports all articles and products in a single repeatable read transaction, so the snapshot is consistent
+//while sales and uploads go on
+func (inventory *PInventoryDB) GetSnapshot(ctx context.Context) (error, data.Snapshot) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("GetSnapshot() entry...")
+	snapshot := data.Snapshot{Version: data.SnapshotVersion}
+	transaction, err := inventory.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, snapshot
+	}
+	defer transaction.Rollback()
+
+	err = transaction.QueryRowContext(ctx, getSnapshotTime).Scan(&snapshot.TakenAt)
+	if err != nil {
+		log.WithField("err", err).Error("Snapshot time query failed")
+		return err, snapshot
+	}
+	snapshot.TakenAt = snapshot.TakenAt.UTC().Truncate(time.Microsecond)
+
+	rows, err := transaction.QueryContext(ctx, getInventory, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetInventory query failed")
+		return err, snapshot
+	}
+	for rows.Next() {
+		article, err := scanArticle(rows)
+		if err != nil {
+			rows.Close()
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, snapshot
+		}
+		// on order is derived from the purchase orders, which are not part of the snapshot
+		article.OnOrder = 0
+		snapshot.Articles = append(snapshot.Articles, article)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, snapshot
+	}
+
+	rows, err = transaction.QueryContext(ctx, getSnapshotProducts, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetSnapshotProducts query failed")
+		return err, snapshot
+	}
+	index := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var contain data.ArticleContain
+		err = rows.Scan(&name, &contain.ArtId, &contain.AmountOf)
+		if err != nil {
+			rows.Close()
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, snapshot
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(snapshot.Products)
+			index[name] = i
+			snapshot.Products = append(snapshot.Products, data.Product{Name: name})
+		}
+		snapshot.Products[i].ContainArticles = append(snapshot.Products[i].ContainArticles, contain)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, snapshot
+	}
+
+	rows, err = transaction.QueryContext(ctx, getSnapshotProductBarcodes, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetSnapshotProductBarcodes query failed")
+		return err, snapshot
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name, barcode string
+		err = rows.Scan(&name, &barcode)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, snapshot
+		}
+		// a barcode of a product without articles cannot be restored, so it is left out
+		if i, ok := index[name]; ok {
+			snapshot.Products[i].Barcodes = append(snapshot.Products[i].Barcodes, barcode)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, snapshot
+	}
+
+	log.WithField("articles", len(snapshot.Articles)).WithField("products", len(snapshot.Products)).Debug("GetSnapshot(), returns the snapshot...")
+	return nil, snapshot
+}
+
+//RestoreSnapshot loads the snapshot in a single transaction. Existing articles are overwritten with the snapshot ones
+//and existing products get the articles of the snapshot, the rest of the database is kept
+func (inventory *PInventoryDB) RestoreSnapshot(ctx context.Context, snapshot data.Snapshot) error {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("RestoreSnapshot() entry...")
+	transaction, err := inventory.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err
+	}
+	defer transaction.Rollback()
+
+	for _, article := range snapshot.Articles {
+		_, err = transaction.ExecContext(ctx, deleteArticleBarcodes, article.ArtId, tenant)
+		if err != nil {
+			log.WithField("err: ", err).Error("RestoreSnapshot(), failed to delete article barcodes...")
+			return err
+		}
+		err = writeArticle(ctx, transaction, article, upsertStock, upsertBarcode)
+		if err != nil {
+			log.WithField("err: ", err).Error("RestoreSnapshot(), failed to restore article...")
+			return err
+		}
+		// the restored stock is valued at the average cost of the snapshot
+		err = resetCostLayers(ctx, transaction, article.ArtId)
+		if err != nil {
+			log.WithField("err: ", err).Error("RestoreSnapshot(), failed to reset cost layers...")
+			return err
+		}
+		stock, _ := strconv.Atoi(article.Stock)
+		err = addCostLayer(ctx, transaction, article.ArtId, stock, article.UnitCost, layerRestore)
+		if err != nil {
+			log.WithField("err: ", err).Error("RestoreSnapshot(), failed to insert cost layer...")
+			return err
+		}
+	}
+
+	for _, product := range snapshot.Products {
+		_, err = transaction.ExecContext(ctx, deleteProduct, product.Name, tenant)
+		if err != nil {
+			log.WithField("err: ", err).Error("RestoreSnapshot(), failed to delete product...")
+			return err
+		}
+		_, err = transaction.ExecContext(ctx, deleteProductBarcodes, product.Name, tenant)
+		if err != nil {
+			log.WithField("err: ", err).Error("RestoreSnapshot(), failed to delete product barcodes...")
+			return err
+		}
+		for _, contain := range product.ContainArticles {
+			_, err = transaction.ExecContext(ctx, insertProduct, product.Name, contain.ArtId, contain.AmountOf, tenant)
+			if err != nil {
+				log.WithField("err: ", err).Error("RestoreSnapshot(), failed to restore product...")
+				return err
+			}
+		}
+		for _, barcode := range product.Barcodes {
+			_, err = transaction.ExecContext(ctx, upsertProductBarcode, barcode, product.Name, tenant)
+			if err != nil {
+				log.WithField("err: ", err).Error("RestoreSnapshot(), failed to restore product barcode...")
+				return err
+			}
+		}
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		log.WithField("err: ", err).Error("RestoreSnapshot(), failed to commit...")
+		return err
+	}
+	log.WithField("articles", len(snapshot.Articles)).WithField("products", len(snapshot.Products)).Debug("RestoreSnapshot(), restored the snapshot...")
+	return nil
+}
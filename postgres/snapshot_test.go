@@ -0,0 +1,54 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/data"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPInventoryDB_Snapshot(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	leg := data.Stock{ArtId: "1", Name: "leg", Stock: "12", Unit: "pcs", Barcodes: []string{"4006381333931"},
+		Attributes: map[string]string{"color": "white"}}
+	screw := data.Stock{ArtId: "2", Name: "screw", Stock: "17"}
+	chair := data.Product{Name: "Dining Chair", Barcodes: []string{"5901234123457"},
+		ContainArticles: []data.ArticleContain{{ArtId: "1", AmountOf: "4"}, {ArtId: "2", AmountOf: "8"}}}
+	err, _ := inventory.UploadInventory(ctx, data.Inventory{Inventory: []data.Stock{leg, screw}})
+	assert.Equal(t, err, nil)
+	err, _ = inventory.UploadProducts(ctx, data.Products{Products: []data.Product{chair}})
+	assert.Equal(t, err, nil)
+
+	err, snapshot := inventory.GetSnapshot(ctx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, snapshot.Version, data.SnapshotVersion)
+	assert.DeepEqual(t, snapshot.Articles, []data.Stock{leg, screw})
+	assert.DeepEqual(t, snapshot.Products, []data.Product{chair})
+
+	// the database moves on, then the snapshot is restored over it
+	err = inventory.SellProduct(ctx, "Dining Chair")
+	assert.Equal(t, err, nil)
+	err, _ = inventory.UploadInventory(ctx, data.Inventory{Inventory: []data.Stock{{ArtId: "3", Name: "seat", Stock: "2"}}})
+	assert.Equal(t, err, nil)
+
+	err = inventory.RestoreSnapshot(ctx, snapshot)
+	assert.Equal(t, err, nil)
+
+	err, restored := inventory.GetSnapshot(ctx)
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, restored.Articles, []data.Stock{leg, screw, {ArtId: "3", Name: "seat", Stock: "2"}})
+	assert.DeepEqual(t, restored.Products, []data.Product{chair})
+}
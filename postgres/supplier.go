@@ -0,0 +1,261 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+//uniqueViolation is the postgres error code of a unique constraint violation
+const uniqueViolation = "23505"
+
+//foreignKeyViolation is the postgres error code of a row that refers to a missing row
+const foreignKeyViolation = "23503"
+
+//GetArticle gets the article with its stock and suppliers
+func (inventory *PInventoryDB) GetArticle(ctx context.Context, artId string) (error, data.Stock) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("GetArticle() entry...")
+	article, err := scanArticle(inventory.db.QueryRowContext(ctx, getArticle, artId, tenant))
+	if err == sql.ErrNoRows {
+		return db.ErrArticleNotFound, article
+	}
+	if err != nil {
+		log.WithField("err", err).Error("GetArticle query failed")
+		return err, article
+	}
+
+	err, article.Suppliers = querySupplierArticles(ctx, inventory.db, log, getArticleSuppliers, artId, tenant)
+	if err != nil {
+		return err, article
+	}
+	return nil, article
+}
+
+//GetSuppliers gets all suppliers without their articles
+func (inventory *PInventoryDB) GetSuppliers(ctx context.Context) (error, []data.Supplier) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("GetSuppliers() entry...")
+	rows, err := inventory.db.QueryContext(ctx, getSuppliers, tenant)
+	if err != nil {
+		log.WithField("err", err).Error("GetSuppliers query failed")
+		return err, nil
+	}
+	defer rows.Close()
+
+	var suppliers []data.Supplier
+	for rows.Next() {
+		var supplier data.Supplier
+		err = rows.Scan(&supplier.SupplierId, &supplier.Name, &supplier.Email, &supplier.Phone)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, nil
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, nil
+	}
+	log.WithField("number of supplier to be returned: ", len(suppliers)).Debug("GetSuppliers(), returns the suppliers...")
+	return nil, suppliers
+}
+
+//GetSupplier gets the supplier with its articles
+func (inventory *PInventoryDB) GetSupplier(ctx context.Context, supplierId int) (error, data.Supplier) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("GetSupplier() entry...")
+	var supplier data.Supplier
+	err := inventory.db.QueryRowContext(ctx, getSupplier, supplierId, tenant).Scan(&supplier.SupplierId, &supplier.Name, &supplier.Email, &supplier.Phone)
+	if err == sql.ErrNoRows {
+		return db.ErrSupplierNotFound, supplier
+	}
+	if err != nil {
+		log.WithField("err", err).Error("GetSupplier query failed")
+		return err, supplier
+	}
+
+	err, supplier.Articles = querySupplierArticles(ctx, inventory.db, log, getSupplierArticles, supplierId, tenant)
+	if err != nil {
+		return err, supplier
+	}
+	return nil, supplier
+}
+
+//CreateSupplier inserts a new supplier
+func (inventory *PInventoryDB) CreateSupplier(ctx context.Context, supplier data.Supplier) (error, data.Supplier) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("CreateSupplier() entry...")
+	err := inventory.db.QueryRowContext(ctx, insertSupplier, supplier.Name, supplier.Email, supplier.Phone, tenant).Scan(&supplier.SupplierId)
+	if isUniqueViolation(err) {
+		return db.ErrSupplierExists, supplier
+	}
+	if err != nil {
+		log.WithField("err: ", err).Error("CreateSupplier(), failed to insert supplier...")
+		return err, supplier
+	}
+
+	log.WithField("supplier id: ", supplier.SupplierId).Debug("CreateSupplier(), created the supplier...")
+	return nil, supplier
+}
+
+//UpdateSupplier updates the contact info of the supplier
+func (inventory *PInventoryDB) UpdateSupplier(ctx context.Context, supplier data.Supplier) (error, data.Supplier) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("UpdateSupplier() entry...")
+	result, err := inventory.db.ExecContext(ctx, updateSupplier, supplier.SupplierId, supplier.Name, supplier.Email, supplier.Phone, tenant)
+	if isUniqueViolation(err) {
+		return db.ErrSupplierExists, supplier
+	}
+	if err != nil {
+		log.WithField("err: ", err).Error("UpdateSupplier(), failed to update supplier...")
+		return err, supplier
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err, supplier
+	}
+	if updated == 0 {
+		return db.ErrSupplierNotFound, supplier
+	}
+	return inventory.GetSupplier(ctx, supplier.SupplierId)
+}
+
+//DeleteSupplier deletes the supplier together with its article mappings
+func (inventory *PInventoryDB) DeleteSupplier(ctx context.Context, supplierId int) error {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("DeleteSupplier() entry...")
+	result, err := inventory.db.ExecContext(ctx, deleteSupplier, supplierId, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("DeleteSupplier(), failed to delete supplier...")
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return db.ErrSupplierNotFound
+	}
+	return nil
+}
+
+//SaveSupplierArticle inserts or updates the purchasing info of an article from a supplier
+func (inventory *PInventoryDB) SaveSupplierArticle(ctx context.Context, article data.SupplierArticle) (error, data.SupplierArticle) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("SaveSupplierArticle() entry...")
+	transaction, err := inventory.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithField("err", err).Error("Transaction begin failed")
+		return err, article
+	}
+	defer transaction.Rollback()
+
+	var count int
+	err = transaction.QueryRowContext(ctx, supplierExist, article.SupplierId, tenant).Scan(&count)
+	if err != nil {
+		log.WithField("err", err).Error("SupplierExist query failed")
+		return err, article
+	}
+	if count == 0 {
+		return db.ErrSupplierNotFound, article
+	}
+	err = transaction.QueryRowContext(ctx, articleExist, article.ArtId, tenant).Scan(&count)
+	if err != nil {
+		log.WithField("err", err).Error("ArticleExist query failed")
+		return err, article
+	}
+	if count == 0 {
+		return db.ErrArticleNotFound, article
+	}
+
+	if article.MinOrderQuantity == 0 {
+		article.MinOrderQuantity = 1
+	}
+	_, err = transaction.ExecContext(ctx, upsertSupplierArticle, article.ArtId, article.SupplierId, article.PartNumber,
+		article.UnitCost, article.LeadTimeDays, article.MinOrderQuantity, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("SaveSupplierArticle(), failed to save supplier article...")
+		return err, article
+	}
+	err = transaction.Commit()
+	if err != nil {
+		log.WithField("err: ", err).Error("SaveSupplierArticle(), failed to commit...")
+		return err, article
+	}
+	return nil, article
+}
+
+//DeleteSupplierArticle removes the article from the supplier catalog
+func (inventory *PInventoryDB) DeleteSupplierArticle(ctx context.Context, supplierId int, artId string) error {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	tenant := request.GetTenant(ctx)
+	log.Debug("DeleteSupplierArticle() entry...")
+	result, err := inventory.db.ExecContext(ctx, deleteSupplierArticle, supplierId, artId, tenant)
+	if err != nil {
+		log.WithField("err: ", err).Error("DeleteSupplierArticle(), failed to delete supplier article...")
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return db.ErrArticleNotFound
+	}
+	return nil
+}
+
+//querySupplierArticles runs a supplier article query
+func querySupplierArticles(ctx context.Context, conn queryer, log *logrus.Entry, query string, args ...interface{}) (error, []data.SupplierArticle) {
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithField("err", err).Error("Supplier article query failed")
+		return err, nil
+	}
+	defer rows.Close()
+
+	var articles []data.SupplierArticle
+	for rows.Next() {
+		var article data.SupplierArticle
+		err = rows.Scan(&article.ArtId, &article.SupplierId, &article.SupplierName, &article.PartNumber,
+			&article.UnitCost, &article.LeadTimeDays, &article.MinOrderQuantity)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, nil
+		}
+		articles = append(articles, article)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, nil
+	}
+	return nil, articles
+}
+
+//isUniqueViolation checks if the error is caused by a unique constraint
+func isUniqueViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == uniqueViolation
+}
+
+//isForeignKeyViolation checks if the error is caused by a foreign key constraint
+func isForeignKeyViolation(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == foreignKeyViolation
+}
@@ -0,0 +1,57 @@
+// +build integration
+
+package postgres
+
+import (
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPInventoryDB_SupplierCatalog(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+
+	uploadInventory(inventory, ctx)
+
+	err, supplier := inventory.CreateSupplier(ctx, data.Supplier{Name: "Acme Legs", Email: "sales@acme.test"})
+	assert.Equal(t, err, nil)
+	assert.Assert(t, supplier.SupplierId != 0)
+
+	err, _ = inventory.CreateSupplier(ctx, data.Supplier{Name: "Acme Legs"})
+	assert.Equal(t, err, db.ErrSupplierExists)
+
+	err, _ = inventory.SaveSupplierArticle(ctx, data.SupplierArticle{
+		ArtId: "1", SupplierId: supplier.SupplierId, PartNumber: "AL-1", UnitCost: 2.5, LeadTimeDays: 7, MinOrderQuantity: 100,
+	})
+	assert.Equal(t, err, nil)
+
+	err, article := inventory.GetArticle(ctx, "1")
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, article.Suppliers, []data.SupplierArticle{
+		{ArtId: "1", SupplierId: supplier.SupplierId, SupplierName: "Acme Legs", PartNumber: "AL-1", UnitCost: 2.5, LeadTimeDays: 7, MinOrderQuantity: 100},
+	})
+
+	err, _ = inventory.SaveSupplierArticle(ctx, data.SupplierArticle{ArtId: "NotExist", SupplierId: supplier.SupplierId})
+	assert.Equal(t, err, db.ErrArticleNotFound)
+
+	err = inventory.DeleteSupplier(ctx, supplier.SupplierId)
+	assert.Equal(t, err, nil)
+	err, article = inventory.GetArticle(ctx, "1")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(article.Suppliers), 0)
+
+	err, _ = inventory.GetSupplier(ctx, supplier.SupplierId)
+	assert.Equal(t, err, db.ErrSupplierNotFound)
+}
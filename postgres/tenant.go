@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+	"github.com/auknl/warehouse/request"
+)
+
+//CountLowStockArticles counts the articles whose available stock is below the threshold for every tenant that has
+//articles in the inventory, in one query over all tenants
+func (inventory *PInventoryDB) CountLowStockArticles(ctx context.Context, threshold int) (error, map[string]int) {
+	log := inventory.config.Logger.WithField("rid", request.GetRID(ctx))
+	log.Debug("CountLowStockArticles() entry...")
+	rows, err := inventory.db.QueryContext(ctx, countLowStockArticles, threshold)
+	if err != nil {
+		log.WithField("err", err).Error("CountLowStockArticles query failed")
+		return err, nil
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var tenant string
+		var count int
+		err = rows.Scan(&tenant, &count)
+		if err != nil {
+			log.WithField("err", err).Error("Cannot scan the table")
+			return err, nil
+		}
+		counts[tenant] = count
+	}
+	if err = rows.Err(); err != nil {
+		log.WithField("err", err).Error("Error happened during the iteration")
+		return err, nil
+	}
+	return nil, counts
+}
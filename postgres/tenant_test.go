@@ -0,0 +1,78 @@
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"github.com/auknl/warehouse/data"
+	"github.com/auknl/warehouse/db"
+	"github.com/auknl/warehouse/request"
+	"github.com/sirupsen/logrus"
+	"gotest.tools/assert"
+	"testing"
+	"time"
+)
+
+func TestPInventoryDB_TenantIsolation(t *testing.T) {
+	pool, resource := initDB(logger)
+	defer closeDB(pool, resource)
+	conn := DockerDBConn.Conn
+	inventory := &PInventoryDB{
+		db:     conn,
+		config: Config{Logger: logrus.NewEntry(logrus.New())},
+	}
+	acme := request.WithTenant(context.Background(), "acme")
+	globex := request.WithTenant(context.Background(), "globex")
+
+	//only acme has stock
+	uploadInventory(inventory, acme)
+	uploadProduct(inventory, acme)
+
+	err, stock := inventory.GetInventory(globex)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(stock), 0)
+	err, productStock := inventory.GetProductStock(globex)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(productStock), 0)
+	err, _ = inventory.GetArticle(globex, "1")
+	assert.Equal(t, err, db.ErrArticleNotFound)
+	err = inventory.SellProduct(globex, "Dinning Table")
+	assert.Equal(t, err, db.ErrProductNotInSystem)
+
+	//the same article ids and product names are free in another tenant
+	uploadInventory(inventory, globex)
+	uploadProduct(inventory, globex)
+	err = inventory.SellProduct(globex, "Dinning Table")
+	assert.Equal(t, err, nil)
+
+	//the sale of globex leaves the stock of acme as it is
+	err, stock = inventory.GetInventory(acme)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(stock), len(inventoryData.Inventory))
+	for i := range inventoryData.Inventory {
+		assert.DeepEqual(t, stock[i], inventoryData.Inventory[i])
+	}
+	err, sales := inventory.GetSales(acme, data.SalesFilter{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sales), 0)
+	err, sales = inventory.GetSales(globex, data.SalesFilter{})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(sales), 1)
+	//the low stock articles are counted per tenant on their available stock, the chairs allocated to the order of
+	//acme leave 4, 1, 0 and 1 of its articles
+	err, _ = inventory.CreateOrder(acme, data.Order{Lines: []data.OrderLine{{ProductName: "Dining Chair", Quantity: 2}}})
+	assert.Equal(t, err, nil)
+	err, lowStock := inventory.CountLowStockArticles(context.Background(), 5)
+	assert.Equal(t, err, nil)
+	assert.DeepEqual(t, lowStock, map[string]int{"acme": 4, "globex": 2})
+
+	//the jobs are claimed by the workers of all tenants and keep their tenant
+	err, job := inventory.CreateImportJob(acme, data.ImportJob{Kind: data.ImportInventory, Payload: []byte("{}\n"), Total: 1})
+	assert.Equal(t, err, nil)
+	err, claimed := inventory.ClaimImportJob(context.Background(), "worker", time.Minute)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, claimed.JobId, job.JobId)
+	assert.Equal(t, claimed.TenantId, "acme")
+	err, _ = inventory.GetImportJob(globex, job.JobId)
+	assert.Equal(t, err, db.ErrImportJobNotFound)
+}
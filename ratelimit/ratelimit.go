@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/auknl/warehouse/data"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//sweepInterval is how often the idle buckets of the memory store are removed
+const sweepInterval = time.Minute
+
+//units are the periods a rate can be given per
+var units = map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}
+
+//ParseLimits parses the limits of the route groups, "<group>=<requests>/<s|m|h>[:<burst>]" separated by commas.
+//The burst is the number of requests per period when it is not given
+func ParseLimits(value string) (map[string]data.RateLimit, error) {
+	limits := make(map[string]data.RateLimit)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pair := strings.SplitN(entry, "=", 2)
+		if len(pair) != 2 || strings.TrimSpace(pair[0]) == "" {
+			return nil, fmt.Errorf("rate limit %q is not in <group>=<requests>/<s|m|h>[:<burst>] form", entry)
+		}
+		group := strings.TrimSpace(pair[0])
+		rate := strings.TrimSpace(pair[1])
+		burst := ""
+		if i := strings.Index(rate, ":"); i >= 0 {
+			rate, burst = rate[:i], rate[i+1:]
+		}
+		parts := strings.SplitN(rate, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("rate limit %q has no period", entry)
+		}
+		requests, err := strconv.Atoi(parts[0])
+		if err != nil || requests <= 0 {
+			return nil, fmt.Errorf("rate limit %q has no positive number of requests", entry)
+		}
+		unit, ok := units[parts[1]]
+		if !ok {
+			return nil, fmt.Errorf("rate limit %q has a period other than s, m or h", entry)
+		}
+		limit := data.RateLimit{Rate: float64(requests) / unit.Seconds(), Burst: requests}
+		if burst != "" {
+			limit.Burst, err = strconv.Atoi(burst)
+			if err != nil || limit.Burst <= 0 {
+				return nil, fmt.Errorf("rate limit %q has no positive burst", entry)
+			}
+		}
+		limits[group] = limit
+	}
+	return limits, nil
+}
+
+//bucket is the tokens left for a key at the time of its last take
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   data.RateLimit
+}
+
+//Memory keeps the token buckets in the process, the limits are per instance
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+	now     func() time.Time
+}
+
+//NewMemory creates an in process store of the token buckets
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*bucket), swept: time.Now(), now: time.Now}
+}
+
+//TakeRateLimitToken takes a token from the bucket of the key, a new bucket starts full
+func (memory *Memory) TakeRateLimitToken(ctx context.Context, key string, limit data.RateLimit) (error, data.RateDecision) {
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+	now := memory.now()
+	if now.Sub(memory.swept) >= sweepInterval {
+		memory.sweep(now)
+	}
+	b, ok := memory.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		memory.buckets[key] = b
+	}
+	var decision data.RateDecision
+	b.tokens, decision = limit.Take(b.tokens, now.Sub(b.updated))
+	b.updated = now
+	b.limit = limit
+	return nil, decision
+}
+
+//sweep removes the buckets refilled since their last take, they are the same as new ones
+func (memory *Memory) sweep(now time.Time) {
+	for key, b := range memory.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*b.limit.Rate >= float64(b.limit.Burst) {
+			delete(memory.buckets, key)
+		}
+	}
+	memory.swept = now
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/auknl/warehouse/data"
+	"github.com/go-playground/assert/v2"
+	"testing"
+	"time"
+)
+
+func TestParseLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		limits map[string]data.RateLimit
+		valid  bool
+	}{
+		{
+			name:   "empty",
+			value:  "",
+			limits: map[string]data.RateLimit{},
+			valid:  true,
+		},
+		{
+			name:  "groups",
+			value: "sell=5/s:10, write=120/m,read=3600/h",
+			limits: map[string]data.RateLimit{
+				"sell":  {Rate: 5, Burst: 10},
+				"write": {Rate: 2, Burst: 120},
+				"read":  {Rate: 1, Burst: 3600},
+			},
+			valid: true,
+		},
+		{name: "no_group", value: "=5/s"},
+		{name: "no_period", value: "sell=5"},
+		{name: "unknown_period", value: "sell=5/d"},
+		{name: "zero_requests", value: "sell=0/s"},
+		{name: "zero_burst", value: "sell=5/s:0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits, err := ParseLimits(tt.value)
+			assert.Equal(t, err == nil, tt.valid)
+			if tt.valid {
+				assert.Equal(t, limits, tt.limits)
+			}
+		})
+	}
+}
+
+func TestMemory_TakeRateLimitToken(t *testing.T) {
+	now := time.Now()
+	memory := NewMemory()
+	memory.now = func() time.Time { return now }
+	limit := data.RateLimit{Rate: 1, Burst: 2}
+
+	for i := 1; i >= 0; i-- {
+		err, decision := memory.TakeRateLimitToken(context.Background(), "sell|ip:192.0.2.1", limit)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, decision, data.RateDecision{Allowed: true, Remaining: i})
+	}
+	_, decision := memory.TakeRateLimitToken(context.Background(), "sell|ip:192.0.2.1", limit)
+	assert.Equal(t, decision, data.RateDecision{RetryAfter: time.Second})
+
+	//another client has its own bucket
+	_, decision = memory.TakeRateLimitToken(context.Background(), "sell|ip:192.0.2.2", limit)
+	assert.Equal(t, decision.Allowed, true)
+
+	now = now.Add(1500 * time.Millisecond)
+	_, decision = memory.TakeRateLimitToken(context.Background(), "sell|ip:192.0.2.1", limit)
+	assert.Equal(t, decision.Allowed, true)
+	_, decision = memory.TakeRateLimitToken(context.Background(), "sell|ip:192.0.2.1", limit)
+	assert.Equal(t, decision, data.RateDecision{RetryAfter: 500 * time.Millisecond})
+
+	//the refilled buckets are swept
+	now = now.Add(sweepInterval)
+	_, _ = memory.TakeRateLimitToken(context.Background(), "read|ip:192.0.2.3", limit)
+	assert.Equal(t, len(memory.buckets), 1)
+}
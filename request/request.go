@@ -3,6 +3,7 @@ package request
 import (
 	"context"
 	"github.com/google/uuid"
+	"time"
 )
 
 //WithID returns context with contextIDKey
@@ -23,13 +24,40 @@ type contextIDType struct{}
 
 var contextIDKey = &contextIDType{}
 
-//GetRID returns the contextIDKey by generating or using the existing one
+//GetRID returns the request id of the context, a new id is generated when the context has none. The new id is not
+//kept, the id of a request is set once with WithID so that all of its log lines share it
 func GetRID(ctx context.Context) string {
 	v := IDFromContext(ctx)
 	if v == "" {
 		v = uuid.New().String()
-		WithID(ctx, v)
 	}
 
 	return v
 }
+
+//detachedContext keeps the values of its parent without its deadline and cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (ctx detachedContext) Value(key interface{}) interface{} {
+	return ctx.parent.Value(key)
+}
+
+//Detach returns a context with the request id, the tenant and the other values of ctx that is not done when ctx is,
+//the work that must finish after the request timed out or the client left runs on it
+func Detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
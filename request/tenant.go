@@ -0,0 +1,35 @@
+package request
+
+import (
+	"context"
+	"regexp"
+)
+
+//DefaultTenant is the tenant of the requests without one, the data from before the tenants belongs to it
+const DefaultTenant = "default"
+
+type contextTenantType struct{}
+
+var contextTenantKey = &contextTenantType{}
+
+//tenantPattern is the form of a tenant id, lower case letters, digits, '-' and '_'
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+//ValidTenant checks if the tenant id is in the form of a tenant id
+func ValidTenant(tenant string) bool {
+	return tenantPattern.MatchString(tenant)
+}
+
+//WithTenant returns context with the tenant, the database calls with it see only the data of the tenant
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, contextTenantKey, tenant)
+}
+
+//GetTenant returns the tenant of the context, the default tenant when the context has none
+func GetTenant(ctx context.Context) string {
+	v, _ := ctx.Value(contextTenantKey).(string)
+	if v == "" {
+		return DefaultTenant
+	}
+	return v
+}